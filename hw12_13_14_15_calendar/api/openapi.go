@@ -0,0 +1,8 @@
+package api
+
+import _ "embed" // For OpenAPI document.
+
+// OpenAPI is OpenAPI v2 document of the HTTP gateway, it is generated from service.proto.
+//
+//go:embed calendar.swagger.json
+var OpenAPI []byte
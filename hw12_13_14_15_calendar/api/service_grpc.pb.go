@@ -22,9 +22,40 @@ type EventsClient interface {
 	AddEvent(ctx context.Context, in *AddEventRequest, opts ...grpc.CallOption) (*AddEventResponse, error)
 	UpdateEvent(ctx context.Context, in *UpdateEventRequest, opts ...grpc.CallOption) (*empty.Empty, error)
 	RemoveEvent(ctx context.Context, in *RemoveEventRequest, opts ...grpc.CallOption) (*empty.Empty, error)
+	RestoreEvent(ctx context.Context, in *RestoreEventRequest, opts ...grpc.CallOption) (*empty.Empty, error)
+	BatchAddEvents(ctx context.Context, in *BatchAddEventsRequest, opts ...grpc.CallOption) (*BatchEventsResponse, error)
+	BatchUpdateEvents(ctx context.Context, in *BatchUpdateEventsRequest, opts ...grpc.CallOption) (*BatchEventsResponse, error)
+	BatchRemoveEvents(ctx context.Context, in *BatchRemoveEventsRequest, opts ...grpc.CallOption) (*BatchEventsResponse, error)
+	ListDeletedEvents(ctx context.Context, in *ListDeletedEventsRequest, opts ...grpc.CallOption) (*GetEventsResponse, error)
+	GetEventHistory(ctx context.Context, in *GetEventHistoryRequest, opts ...grpc.CallOption) (*GetEventHistoryResponse, error)
+	SearchEvents(ctx context.Context, in *SearchEventsRequest, opts ...grpc.CallOption) (*GetEventsResponse, error)
+	GetFreeBusy(ctx context.Context, in *GetFreeBusyRequest, opts ...grpc.CallOption) (*GetFreeBusyResponse, error)
+	AcknowledgeReminder(ctx context.Context, in *AcknowledgeReminderRequest, opts ...grpc.CallOption) (*empty.Empty, error)
+	SnoozeReminder(ctx context.Context, in *SnoozeReminderRequest, opts ...grpc.CallOption) (*empty.Empty, error)
 	GetEventsForDay(ctx context.Context, in *GetEventsRequest, opts ...grpc.CallOption) (*GetEventsResponse, error)
 	GetEventsForWeek(ctx context.Context, in *GetEventsRequest, opts ...grpc.CallOption) (*GetEventsResponse, error)
 	GetEventsForMonth(ctx context.Context, in *GetEventsRequest, opts ...grpc.CallOption) (*GetEventsResponse, error)
+	CreateCalendar(ctx context.Context, in *CreateCalendarRequest, opts ...grpc.CallOption) (*CreateCalendarResponse, error)
+	UpdateCalendar(ctx context.Context, in *UpdateCalendarRequest, opts ...grpc.CallOption) (*empty.Empty, error)
+	RemoveCalendar(ctx context.Context, in *RemoveCalendarRequest, opts ...grpc.CallOption) (*empty.Empty, error)
+	ListCalendars(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*ListCalendarsResponse, error)
+	ShareCalendar(ctx context.Context, in *ShareCalendarRequest, opts ...grpc.CallOption) (*empty.Empty, error)
+	RevokeCalendarAccess(ctx context.Context, in *RevokeCalendarAccessRequest, opts ...grpc.CallOption) (*empty.Empty, error)
+	ListCalendarGrants(ctx context.Context, in *ListCalendarGrantsRequest, opts ...grpc.CallOption) (*ListCalendarGrantsResponse, error)
+	AddAttachment(ctx context.Context, in *AddAttachmentRequest, opts ...grpc.CallOption) (*AddAttachmentResponse, error)
+	// UploadAttachment takes attachment in the first message and its content in chunks of the next ones.
+	UploadAttachment(ctx context.Context, opts ...grpc.CallOption) (Events_UploadAttachmentClient, error)
+	// DownloadAttachment returns attachment in the first message and its content in chunks of the next ones.
+	DownloadAttachment(ctx context.Context, in *DownloadAttachmentRequest, opts ...grpc.CallOption) (Events_DownloadAttachmentClient, error)
+	ListAttachments(ctx context.Context, in *ListAttachmentsRequest, opts ...grpc.CallOption) (*ListAttachmentsResponse, error)
+	RemoveAttachment(ctx context.Context, in *RemoveAttachmentRequest, opts ...grpc.CallOption) (*empty.Empty, error)
+	CreateResource(ctx context.Context, in *CreateResourceRequest, opts ...grpc.CallOption) (*CreateResourceResponse, error)
+	RemoveResource(ctx context.Context, in *RemoveResourceRequest, opts ...grpc.CallOption) (*empty.Empty, error)
+	ListResources(ctx context.Context, in *ListResourcesRequest, opts ...grpc.CallOption) (*ListResourcesResponse, error)
+	GetResourceSchedule(ctx context.Context, in *GetResourceScheduleRequest, opts ...grpc.CallOption) (*GetResourceScheduleResponse, error)
+	// Feed token of user from x-user-id header, the feed is served by HTTP server.
+	RotateFeedToken(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*RotateFeedTokenResponse, error)
+	RevokeFeedToken(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*empty.Empty, error)
 }
 
 type eventsClient struct {
@@ -62,6 +93,96 @@ func (c *eventsClient) RemoveEvent(ctx context.Context, in *RemoveEventRequest,
 	return out, nil
 }
 
+func (c *eventsClient) RestoreEvent(ctx context.Context, in *RestoreEventRequest, opts ...grpc.CallOption) (*empty.Empty, error) {
+	out := new(empty.Empty)
+	err := c.cc.Invoke(ctx, "/Events/RestoreEvent", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventsClient) BatchAddEvents(ctx context.Context, in *BatchAddEventsRequest, opts ...grpc.CallOption) (*BatchEventsResponse, error) {
+	out := new(BatchEventsResponse)
+	err := c.cc.Invoke(ctx, "/Events/BatchAddEvents", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventsClient) BatchUpdateEvents(ctx context.Context, in *BatchUpdateEventsRequest, opts ...grpc.CallOption) (*BatchEventsResponse, error) {
+	out := new(BatchEventsResponse)
+	err := c.cc.Invoke(ctx, "/Events/BatchUpdateEvents", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventsClient) BatchRemoveEvents(ctx context.Context, in *BatchRemoveEventsRequest, opts ...grpc.CallOption) (*BatchEventsResponse, error) {
+	out := new(BatchEventsResponse)
+	err := c.cc.Invoke(ctx, "/Events/BatchRemoveEvents", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventsClient) ListDeletedEvents(ctx context.Context, in *ListDeletedEventsRequest, opts ...grpc.CallOption) (*GetEventsResponse, error) {
+	out := new(GetEventsResponse)
+	err := c.cc.Invoke(ctx, "/Events/ListDeletedEvents", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventsClient) GetEventHistory(ctx context.Context, in *GetEventHistoryRequest, opts ...grpc.CallOption) (*GetEventHistoryResponse, error) {
+	out := new(GetEventHistoryResponse)
+	err := c.cc.Invoke(ctx, "/Events/GetEventHistory", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventsClient) SearchEvents(ctx context.Context, in *SearchEventsRequest, opts ...grpc.CallOption) (*GetEventsResponse, error) {
+	out := new(GetEventsResponse)
+	err := c.cc.Invoke(ctx, "/Events/SearchEvents", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventsClient) GetFreeBusy(ctx context.Context, in *GetFreeBusyRequest, opts ...grpc.CallOption) (*GetFreeBusyResponse, error) {
+	out := new(GetFreeBusyResponse)
+	err := c.cc.Invoke(ctx, "/Events/GetFreeBusy", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventsClient) AcknowledgeReminder(ctx context.Context, in *AcknowledgeReminderRequest, opts ...grpc.CallOption) (*empty.Empty, error) {
+	out := new(empty.Empty)
+	err := c.cc.Invoke(ctx, "/Events/AcknowledgeReminder", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventsClient) SnoozeReminder(ctx context.Context, in *SnoozeReminderRequest, opts ...grpc.CallOption) (*empty.Empty, error) {
+	out := new(empty.Empty)
+	err := c.cc.Invoke(ctx, "/Events/SnoozeReminder", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *eventsClient) GetEventsForDay(ctx context.Context, in *GetEventsRequest, opts ...grpc.CallOption) (*GetEventsResponse, error) {
 	out := new(GetEventsResponse)
 	err := c.cc.Invoke(ctx, "/Events/GetEventsForDay", in, out, opts...)
@@ -89,6 +210,216 @@ func (c *eventsClient) GetEventsForMonth(ctx context.Context, in *GetEventsReque
 	return out, nil
 }
 
+func (c *eventsClient) CreateCalendar(ctx context.Context, in *CreateCalendarRequest, opts ...grpc.CallOption) (*CreateCalendarResponse, error) {
+	out := new(CreateCalendarResponse)
+	err := c.cc.Invoke(ctx, "/Events/CreateCalendar", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventsClient) UpdateCalendar(ctx context.Context, in *UpdateCalendarRequest, opts ...grpc.CallOption) (*empty.Empty, error) {
+	out := new(empty.Empty)
+	err := c.cc.Invoke(ctx, "/Events/UpdateCalendar", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventsClient) RemoveCalendar(ctx context.Context, in *RemoveCalendarRequest, opts ...grpc.CallOption) (*empty.Empty, error) {
+	out := new(empty.Empty)
+	err := c.cc.Invoke(ctx, "/Events/RemoveCalendar", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventsClient) ListCalendars(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*ListCalendarsResponse, error) {
+	out := new(ListCalendarsResponse)
+	err := c.cc.Invoke(ctx, "/Events/ListCalendars", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventsClient) ShareCalendar(ctx context.Context, in *ShareCalendarRequest, opts ...grpc.CallOption) (*empty.Empty, error) {
+	out := new(empty.Empty)
+	err := c.cc.Invoke(ctx, "/Events/ShareCalendar", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventsClient) RevokeCalendarAccess(ctx context.Context, in *RevokeCalendarAccessRequest, opts ...grpc.CallOption) (*empty.Empty, error) {
+	out := new(empty.Empty)
+	err := c.cc.Invoke(ctx, "/Events/RevokeCalendarAccess", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventsClient) ListCalendarGrants(ctx context.Context, in *ListCalendarGrantsRequest, opts ...grpc.CallOption) (*ListCalendarGrantsResponse, error) {
+	out := new(ListCalendarGrantsResponse)
+	err := c.cc.Invoke(ctx, "/Events/ListCalendarGrants", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventsClient) AddAttachment(ctx context.Context, in *AddAttachmentRequest, opts ...grpc.CallOption) (*AddAttachmentResponse, error) {
+	out := new(AddAttachmentResponse)
+	err := c.cc.Invoke(ctx, "/Events/AddAttachment", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventsClient) UploadAttachment(ctx context.Context, opts ...grpc.CallOption) (Events_UploadAttachmentClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Events_ServiceDesc.Streams[0], "/Events/UploadAttachment", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &eventsUploadAttachmentClient{stream}
+	return x, nil
+}
+
+type Events_UploadAttachmentClient interface {
+	Send(*UploadAttachmentRequest) error
+	CloseAndRecv() (*AddAttachmentResponse, error)
+	grpc.ClientStream
+}
+
+type eventsUploadAttachmentClient struct {
+	grpc.ClientStream
+}
+
+func (x *eventsUploadAttachmentClient) Send(m *UploadAttachmentRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *eventsUploadAttachmentClient) CloseAndRecv() (*AddAttachmentResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(AddAttachmentResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *eventsClient) DownloadAttachment(ctx context.Context, in *DownloadAttachmentRequest, opts ...grpc.CallOption) (Events_DownloadAttachmentClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Events_ServiceDesc.Streams[1], "/Events/DownloadAttachment", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &eventsDownloadAttachmentClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Events_DownloadAttachmentClient interface {
+	Recv() (*DownloadAttachmentResponse, error)
+	grpc.ClientStream
+}
+
+type eventsDownloadAttachmentClient struct {
+	grpc.ClientStream
+}
+
+func (x *eventsDownloadAttachmentClient) Recv() (*DownloadAttachmentResponse, error) {
+	m := new(DownloadAttachmentResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *eventsClient) ListAttachments(ctx context.Context, in *ListAttachmentsRequest, opts ...grpc.CallOption) (*ListAttachmentsResponse, error) {
+	out := new(ListAttachmentsResponse)
+	err := c.cc.Invoke(ctx, "/Events/ListAttachments", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventsClient) RemoveAttachment(ctx context.Context, in *RemoveAttachmentRequest, opts ...grpc.CallOption) (*empty.Empty, error) {
+	out := new(empty.Empty)
+	err := c.cc.Invoke(ctx, "/Events/RemoveAttachment", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventsClient) CreateResource(ctx context.Context, in *CreateResourceRequest, opts ...grpc.CallOption) (*CreateResourceResponse, error) {
+	out := new(CreateResourceResponse)
+	err := c.cc.Invoke(ctx, "/Events/CreateResource", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventsClient) RemoveResource(ctx context.Context, in *RemoveResourceRequest, opts ...grpc.CallOption) (*empty.Empty, error) {
+	out := new(empty.Empty)
+	err := c.cc.Invoke(ctx, "/Events/RemoveResource", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventsClient) ListResources(ctx context.Context, in *ListResourcesRequest, opts ...grpc.CallOption) (*ListResourcesResponse, error) {
+	out := new(ListResourcesResponse)
+	err := c.cc.Invoke(ctx, "/Events/ListResources", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventsClient) GetResourceSchedule(ctx context.Context, in *GetResourceScheduleRequest, opts ...grpc.CallOption) (*GetResourceScheduleResponse, error) {
+	out := new(GetResourceScheduleResponse)
+	err := c.cc.Invoke(ctx, "/Events/GetResourceSchedule", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventsClient) RotateFeedToken(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*RotateFeedTokenResponse, error) {
+	out := new(RotateFeedTokenResponse)
+	err := c.cc.Invoke(ctx, "/Events/RotateFeedToken", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventsClient) RevokeFeedToken(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*empty.Empty, error) {
+	out := new(empty.Empty)
+	err := c.cc.Invoke(ctx, "/Events/RevokeFeedToken", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // EventsServer is the server API for Events service.
 // All implementations must embed UnimplementedEventsServer
 // for forward compatibility
@@ -96,9 +427,40 @@ type EventsServer interface {
 	AddEvent(context.Context, *AddEventRequest) (*AddEventResponse, error)
 	UpdateEvent(context.Context, *UpdateEventRequest) (*empty.Empty, error)
 	RemoveEvent(context.Context, *RemoveEventRequest) (*empty.Empty, error)
+	RestoreEvent(context.Context, *RestoreEventRequest) (*empty.Empty, error)
+	BatchAddEvents(context.Context, *BatchAddEventsRequest) (*BatchEventsResponse, error)
+	BatchUpdateEvents(context.Context, *BatchUpdateEventsRequest) (*BatchEventsResponse, error)
+	BatchRemoveEvents(context.Context, *BatchRemoveEventsRequest) (*BatchEventsResponse, error)
+	ListDeletedEvents(context.Context, *ListDeletedEventsRequest) (*GetEventsResponse, error)
+	GetEventHistory(context.Context, *GetEventHistoryRequest) (*GetEventHistoryResponse, error)
+	SearchEvents(context.Context, *SearchEventsRequest) (*GetEventsResponse, error)
+	GetFreeBusy(context.Context, *GetFreeBusyRequest) (*GetFreeBusyResponse, error)
+	AcknowledgeReminder(context.Context, *AcknowledgeReminderRequest) (*empty.Empty, error)
+	SnoozeReminder(context.Context, *SnoozeReminderRequest) (*empty.Empty, error)
 	GetEventsForDay(context.Context, *GetEventsRequest) (*GetEventsResponse, error)
 	GetEventsForWeek(context.Context, *GetEventsRequest) (*GetEventsResponse, error)
 	GetEventsForMonth(context.Context, *GetEventsRequest) (*GetEventsResponse, error)
+	CreateCalendar(context.Context, *CreateCalendarRequest) (*CreateCalendarResponse, error)
+	UpdateCalendar(context.Context, *UpdateCalendarRequest) (*empty.Empty, error)
+	RemoveCalendar(context.Context, *RemoveCalendarRequest) (*empty.Empty, error)
+	ListCalendars(context.Context, *empty.Empty) (*ListCalendarsResponse, error)
+	ShareCalendar(context.Context, *ShareCalendarRequest) (*empty.Empty, error)
+	RevokeCalendarAccess(context.Context, *RevokeCalendarAccessRequest) (*empty.Empty, error)
+	ListCalendarGrants(context.Context, *ListCalendarGrantsRequest) (*ListCalendarGrantsResponse, error)
+	AddAttachment(context.Context, *AddAttachmentRequest) (*AddAttachmentResponse, error)
+	// UploadAttachment takes attachment in the first message and its content in chunks of the next ones.
+	UploadAttachment(Events_UploadAttachmentServer) error
+	// DownloadAttachment returns attachment in the first message and its content in chunks of the next ones.
+	DownloadAttachment(*DownloadAttachmentRequest, Events_DownloadAttachmentServer) error
+	ListAttachments(context.Context, *ListAttachmentsRequest) (*ListAttachmentsResponse, error)
+	RemoveAttachment(context.Context, *RemoveAttachmentRequest) (*empty.Empty, error)
+	CreateResource(context.Context, *CreateResourceRequest) (*CreateResourceResponse, error)
+	RemoveResource(context.Context, *RemoveResourceRequest) (*empty.Empty, error)
+	ListResources(context.Context, *ListResourcesRequest) (*ListResourcesResponse, error)
+	GetResourceSchedule(context.Context, *GetResourceScheduleRequest) (*GetResourceScheduleResponse, error)
+	// Feed token of user from x-user-id header, the feed is served by HTTP server.
+	RotateFeedToken(context.Context, *empty.Empty) (*RotateFeedTokenResponse, error)
+	RevokeFeedToken(context.Context, *empty.Empty) (*empty.Empty, error)
 	mustEmbedUnimplementedEventsServer()
 }
 
@@ -115,6 +477,36 @@ func (UnimplementedEventsServer) UpdateEvent(context.Context, *UpdateEventReques
 func (UnimplementedEventsServer) RemoveEvent(context.Context, *RemoveEventRequest) (*empty.Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method RemoveEvent not implemented")
 }
+func (UnimplementedEventsServer) RestoreEvent(context.Context, *RestoreEventRequest) (*empty.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestoreEvent not implemented")
+}
+func (UnimplementedEventsServer) BatchAddEvents(context.Context, *BatchAddEventsRequest) (*BatchEventsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchAddEvents not implemented")
+}
+func (UnimplementedEventsServer) BatchUpdateEvents(context.Context, *BatchUpdateEventsRequest) (*BatchEventsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchUpdateEvents not implemented")
+}
+func (UnimplementedEventsServer) BatchRemoveEvents(context.Context, *BatchRemoveEventsRequest) (*BatchEventsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchRemoveEvents not implemented")
+}
+func (UnimplementedEventsServer) ListDeletedEvents(context.Context, *ListDeletedEventsRequest) (*GetEventsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListDeletedEvents not implemented")
+}
+func (UnimplementedEventsServer) GetEventHistory(context.Context, *GetEventHistoryRequest) (*GetEventHistoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetEventHistory not implemented")
+}
+func (UnimplementedEventsServer) SearchEvents(context.Context, *SearchEventsRequest) (*GetEventsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SearchEvents not implemented")
+}
+func (UnimplementedEventsServer) GetFreeBusy(context.Context, *GetFreeBusyRequest) (*GetFreeBusyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetFreeBusy not implemented")
+}
+func (UnimplementedEventsServer) AcknowledgeReminder(context.Context, *AcknowledgeReminderRequest) (*empty.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AcknowledgeReminder not implemented")
+}
+func (UnimplementedEventsServer) SnoozeReminder(context.Context, *SnoozeReminderRequest) (*empty.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SnoozeReminder not implemented")
+}
 func (UnimplementedEventsServer) GetEventsForDay(context.Context, *GetEventsRequest) (*GetEventsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetEventsForDay not implemented")
 }
@@ -124,6 +516,60 @@ func (UnimplementedEventsServer) GetEventsForWeek(context.Context, *GetEventsReq
 func (UnimplementedEventsServer) GetEventsForMonth(context.Context, *GetEventsRequest) (*GetEventsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetEventsForMonth not implemented")
 }
+func (UnimplementedEventsServer) CreateCalendar(context.Context, *CreateCalendarRequest) (*CreateCalendarResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateCalendar not implemented")
+}
+func (UnimplementedEventsServer) UpdateCalendar(context.Context, *UpdateCalendarRequest) (*empty.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateCalendar not implemented")
+}
+func (UnimplementedEventsServer) RemoveCalendar(context.Context, *RemoveCalendarRequest) (*empty.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveCalendar not implemented")
+}
+func (UnimplementedEventsServer) ListCalendars(context.Context, *empty.Empty) (*ListCalendarsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListCalendars not implemented")
+}
+func (UnimplementedEventsServer) ShareCalendar(context.Context, *ShareCalendarRequest) (*empty.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ShareCalendar not implemented")
+}
+func (UnimplementedEventsServer) RevokeCalendarAccess(context.Context, *RevokeCalendarAccessRequest) (*empty.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RevokeCalendarAccess not implemented")
+}
+func (UnimplementedEventsServer) ListCalendarGrants(context.Context, *ListCalendarGrantsRequest) (*ListCalendarGrantsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListCalendarGrants not implemented")
+}
+func (UnimplementedEventsServer) AddAttachment(context.Context, *AddAttachmentRequest) (*AddAttachmentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddAttachment not implemented")
+}
+func (UnimplementedEventsServer) UploadAttachment(Events_UploadAttachmentServer) error {
+	return status.Errorf(codes.Unimplemented, "method UploadAttachment not implemented")
+}
+func (UnimplementedEventsServer) DownloadAttachment(*DownloadAttachmentRequest, Events_DownloadAttachmentServer) error {
+	return status.Errorf(codes.Unimplemented, "method DownloadAttachment not implemented")
+}
+func (UnimplementedEventsServer) ListAttachments(context.Context, *ListAttachmentsRequest) (*ListAttachmentsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListAttachments not implemented")
+}
+func (UnimplementedEventsServer) RemoveAttachment(context.Context, *RemoveAttachmentRequest) (*empty.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveAttachment not implemented")
+}
+func (UnimplementedEventsServer) CreateResource(context.Context, *CreateResourceRequest) (*CreateResourceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateResource not implemented")
+}
+func (UnimplementedEventsServer) RemoveResource(context.Context, *RemoveResourceRequest) (*empty.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveResource not implemented")
+}
+func (UnimplementedEventsServer) ListResources(context.Context, *ListResourcesRequest) (*ListResourcesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListResources not implemented")
+}
+func (UnimplementedEventsServer) GetResourceSchedule(context.Context, *GetResourceScheduleRequest) (*GetResourceScheduleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetResourceSchedule not implemented")
+}
+func (UnimplementedEventsServer) RotateFeedToken(context.Context, *empty.Empty) (*RotateFeedTokenResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RotateFeedToken not implemented")
+}
+func (UnimplementedEventsServer) RevokeFeedToken(context.Context, *empty.Empty) (*empty.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RevokeFeedToken not implemented")
+}
 func (UnimplementedEventsServer) mustEmbedUnimplementedEventsServer() {}
 
 // UnsafeEventsServer may be embedded to opt out of forward compatibility for this service.
@@ -191,71 +637,586 @@ func _Events_RemoveEvent_Handler(srv interface{}, ctx context.Context, dec func(
 	return interceptor(ctx, in, info, handler)
 }
 
-func _Events_GetEventsForDay_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(GetEventsRequest)
+func _Events_RestoreEvent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestoreEventRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(EventsServer).GetEventsForDay(ctx, in)
+		return srv.(EventsServer).RestoreEvent(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/Events/GetEventsForDay",
+		FullMethod: "/Events/RestoreEvent",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(EventsServer).GetEventsForDay(ctx, req.(*GetEventsRequest))
+		return srv.(EventsServer).RestoreEvent(ctx, req.(*RestoreEventRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _Events_GetEventsForWeek_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(GetEventsRequest)
+func _Events_BatchAddEvents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchAddEventsRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(EventsServer).GetEventsForWeek(ctx, in)
+		return srv.(EventsServer).BatchAddEvents(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/Events/GetEventsForWeek",
+		FullMethod: "/Events/BatchAddEvents",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(EventsServer).GetEventsForWeek(ctx, req.(*GetEventsRequest))
+		return srv.(EventsServer).BatchAddEvents(ctx, req.(*BatchAddEventsRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _Events_GetEventsForMonth_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(GetEventsRequest)
+func _Events_BatchUpdateEvents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchUpdateEventsRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(EventsServer).GetEventsForMonth(ctx, in)
+		return srv.(EventsServer).BatchUpdateEvents(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/Events/GetEventsForMonth",
+		FullMethod: "/Events/BatchUpdateEvents",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(EventsServer).GetEventsForMonth(ctx, req.(*GetEventsRequest))
+		return srv.(EventsServer).BatchUpdateEvents(ctx, req.(*BatchUpdateEventsRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-// Events_ServiceDesc is the grpc.ServiceDesc for Events service.
-// It's only intended for direct use with grpc.RegisterService,
-// and not to be introspected or modified (even as a copy)
-var Events_ServiceDesc = grpc.ServiceDesc{
-	ServiceName: "Events",
-	HandlerType: (*EventsServer)(nil),
-	Methods: []grpc.MethodDesc{
-		{
-			MethodName: "AddEvent",
-			Handler:    _Events_AddEvent_Handler,
-		},
+func _Events_BatchRemoveEvents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchRemoveEventsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventsServer).BatchRemoveEvents(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Events/BatchRemoveEvents",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventsServer).BatchRemoveEvents(ctx, req.(*BatchRemoveEventsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Events_ListDeletedEvents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListDeletedEventsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventsServer).ListDeletedEvents(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Events/ListDeletedEvents",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventsServer).ListDeletedEvents(ctx, req.(*ListDeletedEventsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Events_GetEventHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetEventHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventsServer).GetEventHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Events/GetEventHistory",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventsServer).GetEventHistory(ctx, req.(*GetEventHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Events_SearchEvents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchEventsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventsServer).SearchEvents(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Events/SearchEvents",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventsServer).SearchEvents(ctx, req.(*SearchEventsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Events_GetFreeBusy_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetFreeBusyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventsServer).GetFreeBusy(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Events/GetFreeBusy",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventsServer).GetFreeBusy(ctx, req.(*GetFreeBusyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Events_AcknowledgeReminder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AcknowledgeReminderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventsServer).AcknowledgeReminder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Events/AcknowledgeReminder",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventsServer).AcknowledgeReminder(ctx, req.(*AcknowledgeReminderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Events_SnoozeReminder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SnoozeReminderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventsServer).SnoozeReminder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Events/SnoozeReminder",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventsServer).SnoozeReminder(ctx, req.(*SnoozeReminderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Events_GetEventsForDay_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetEventsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventsServer).GetEventsForDay(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Events/GetEventsForDay",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventsServer).GetEventsForDay(ctx, req.(*GetEventsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Events_GetEventsForWeek_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetEventsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventsServer).GetEventsForWeek(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Events/GetEventsForWeek",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventsServer).GetEventsForWeek(ctx, req.(*GetEventsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Events_GetEventsForMonth_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetEventsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventsServer).GetEventsForMonth(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Events/GetEventsForMonth",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventsServer).GetEventsForMonth(ctx, req.(*GetEventsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Events_CreateCalendar_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateCalendarRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventsServer).CreateCalendar(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Events/CreateCalendar",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventsServer).CreateCalendar(ctx, req.(*CreateCalendarRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Events_UpdateCalendar_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateCalendarRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventsServer).UpdateCalendar(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Events/UpdateCalendar",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventsServer).UpdateCalendar(ctx, req.(*UpdateCalendarRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Events_RemoveCalendar_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveCalendarRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventsServer).RemoveCalendar(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Events/RemoveCalendar",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventsServer).RemoveCalendar(ctx, req.(*RemoveCalendarRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Events_ListCalendars_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(empty.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventsServer).ListCalendars(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Events/ListCalendars",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventsServer).ListCalendars(ctx, req.(*empty.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Events_ShareCalendar_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ShareCalendarRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventsServer).ShareCalendar(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Events/ShareCalendar",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventsServer).ShareCalendar(ctx, req.(*ShareCalendarRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Events_RevokeCalendarAccess_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RevokeCalendarAccessRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventsServer).RevokeCalendarAccess(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Events/RevokeCalendarAccess",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventsServer).RevokeCalendarAccess(ctx, req.(*RevokeCalendarAccessRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Events_ListCalendarGrants_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListCalendarGrantsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventsServer).ListCalendarGrants(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Events/ListCalendarGrants",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventsServer).ListCalendarGrants(ctx, req.(*ListCalendarGrantsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Events_AddAttachment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddAttachmentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventsServer).AddAttachment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Events/AddAttachment",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventsServer).AddAttachment(ctx, req.(*AddAttachmentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Events_UploadAttachment_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(EventsServer).UploadAttachment(&eventsUploadAttachmentServer{stream})
+}
+
+type Events_UploadAttachmentServer interface {
+	SendAndClose(*AddAttachmentResponse) error
+	Recv() (*UploadAttachmentRequest, error)
+	grpc.ServerStream
+}
+
+type eventsUploadAttachmentServer struct {
+	grpc.ServerStream
+}
+
+func (x *eventsUploadAttachmentServer) SendAndClose(m *AddAttachmentResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *eventsUploadAttachmentServer) Recv() (*UploadAttachmentRequest, error) {
+	m := new(UploadAttachmentRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _Events_DownloadAttachment_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(DownloadAttachmentRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(EventsServer).DownloadAttachment(m, &eventsDownloadAttachmentServer{stream})
+}
+
+type Events_DownloadAttachmentServer interface {
+	Send(*DownloadAttachmentResponse) error
+	grpc.ServerStream
+}
+
+type eventsDownloadAttachmentServer struct {
+	grpc.ServerStream
+}
+
+func (x *eventsDownloadAttachmentServer) Send(m *DownloadAttachmentResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Events_ListAttachments_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListAttachmentsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventsServer).ListAttachments(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Events/ListAttachments",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventsServer).ListAttachments(ctx, req.(*ListAttachmentsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Events_RemoveAttachment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveAttachmentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventsServer).RemoveAttachment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Events/RemoveAttachment",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventsServer).RemoveAttachment(ctx, req.(*RemoveAttachmentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Events_CreateResource_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateResourceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventsServer).CreateResource(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Events/CreateResource",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventsServer).CreateResource(ctx, req.(*CreateResourceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Events_RemoveResource_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveResourceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventsServer).RemoveResource(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Events/RemoveResource",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventsServer).RemoveResource(ctx, req.(*RemoveResourceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Events_ListResources_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListResourcesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventsServer).ListResources(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Events/ListResources",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventsServer).ListResources(ctx, req.(*ListResourcesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Events_GetResourceSchedule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetResourceScheduleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventsServer).GetResourceSchedule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Events/GetResourceSchedule",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventsServer).GetResourceSchedule(ctx, req.(*GetResourceScheduleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Events_RotateFeedToken_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(empty.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventsServer).RotateFeedToken(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Events/RotateFeedToken",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventsServer).RotateFeedToken(ctx, req.(*empty.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Events_RevokeFeedToken_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(empty.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventsServer).RevokeFeedToken(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Events/RevokeFeedToken",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventsServer).RevokeFeedToken(ctx, req.(*empty.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Events_ServiceDesc is the grpc.ServiceDesc for Events service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Events_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "Events",
+	HandlerType: (*EventsServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "AddEvent",
+			Handler:    _Events_AddEvent_Handler,
+		},
 		{
 			MethodName: "UpdateEvent",
 			Handler:    _Events_UpdateEvent_Handler,
@@ -264,6 +1225,46 @@ var Events_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "RemoveEvent",
 			Handler:    _Events_RemoveEvent_Handler,
 		},
+		{
+			MethodName: "RestoreEvent",
+			Handler:    _Events_RestoreEvent_Handler,
+		},
+		{
+			MethodName: "BatchAddEvents",
+			Handler:    _Events_BatchAddEvents_Handler,
+		},
+		{
+			MethodName: "BatchUpdateEvents",
+			Handler:    _Events_BatchUpdateEvents_Handler,
+		},
+		{
+			MethodName: "BatchRemoveEvents",
+			Handler:    _Events_BatchRemoveEvents_Handler,
+		},
+		{
+			MethodName: "ListDeletedEvents",
+			Handler:    _Events_ListDeletedEvents_Handler,
+		},
+		{
+			MethodName: "GetEventHistory",
+			Handler:    _Events_GetEventHistory_Handler,
+		},
+		{
+			MethodName: "SearchEvents",
+			Handler:    _Events_SearchEvents_Handler,
+		},
+		{
+			MethodName: "GetFreeBusy",
+			Handler:    _Events_GetFreeBusy_Handler,
+		},
+		{
+			MethodName: "AcknowledgeReminder",
+			Handler:    _Events_AcknowledgeReminder_Handler,
+		},
+		{
+			MethodName: "SnoozeReminder",
+			Handler:    _Events_SnoozeReminder_Handler,
+		},
 		{
 			MethodName: "GetEventsForDay",
 			Handler:    _Events_GetEventsForDay_Handler,
@@ -276,7 +1277,82 @@ var Events_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetEventsForMonth",
 			Handler:    _Events_GetEventsForMonth_Handler,
 		},
+		{
+			MethodName: "CreateCalendar",
+			Handler:    _Events_CreateCalendar_Handler,
+		},
+		{
+			MethodName: "UpdateCalendar",
+			Handler:    _Events_UpdateCalendar_Handler,
+		},
+		{
+			MethodName: "RemoveCalendar",
+			Handler:    _Events_RemoveCalendar_Handler,
+		},
+		{
+			MethodName: "ListCalendars",
+			Handler:    _Events_ListCalendars_Handler,
+		},
+		{
+			MethodName: "ShareCalendar",
+			Handler:    _Events_ShareCalendar_Handler,
+		},
+		{
+			MethodName: "RevokeCalendarAccess",
+			Handler:    _Events_RevokeCalendarAccess_Handler,
+		},
+		{
+			MethodName: "ListCalendarGrants",
+			Handler:    _Events_ListCalendarGrants_Handler,
+		},
+		{
+			MethodName: "AddAttachment",
+			Handler:    _Events_AddAttachment_Handler,
+		},
+		{
+			MethodName: "ListAttachments",
+			Handler:    _Events_ListAttachments_Handler,
+		},
+		{
+			MethodName: "RemoveAttachment",
+			Handler:    _Events_RemoveAttachment_Handler,
+		},
+		{
+			MethodName: "CreateResource",
+			Handler:    _Events_CreateResource_Handler,
+		},
+		{
+			MethodName: "RemoveResource",
+			Handler:    _Events_RemoveResource_Handler,
+		},
+		{
+			MethodName: "ListResources",
+			Handler:    _Events_ListResources_Handler,
+		},
+		{
+			MethodName: "GetResourceSchedule",
+			Handler:    _Events_GetResourceSchedule_Handler,
+		},
+		{
+			MethodName: "RotateFeedToken",
+			Handler:    _Events_RotateFeedToken_Handler,
+		},
+		{
+			MethodName: "RevokeFeedToken",
+			Handler:    _Events_RevokeFeedToken_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "UploadAttachment",
+			Handler:       _Events_UploadAttachment_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "DownloadAttachment",
+			Handler:       _Events_DownloadAttachment_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "service.proto",
 }
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/broker"
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/clock"
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/dedup"
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/leader"
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/scheduler"
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/sender"
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/storage"
+	log "github.com/sirupsen/logrus"
+)
+
+// QueueConfig is a config of in-process queue used in all-in-one mode.
+type QueueConfig struct {
+	// Max number of messages waiting for sender.
+	Capacity int
+}
+
+// Runs scheduler and sender in the calendar process, they exchange notifications through in-process queue.
+// Reloaded scheduler and deduplication configs are passed to them.
+func startAllInOne(
+	ctx context.Context,
+	stor storage.Storage,
+	config Config,
+	schedulerReload <-chan scheduler.Config,
+	dedupReload <-chan dedup.Config,
+) error {
+	store, err := dedup.New(ctx, config.Dedup, clock.Real)
+	if err != nil {
+		return fmt.Errorf("failed to create deduplication store: %w", err)
+	}
+	queue := broker.NewMemory(config.Queue.Capacity)
+	go scheduler.New(stor, queue, leader.Single{}, config.Scheduler, clock.Real).Run(ctx, schedulerReload)
+	go func() {
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second*3)
+			defer cancel()
+			store.Close(ctx)
+		}()
+		s := sender.New(store, sender.LogNotifier{}, config.Dedup.TTL, clock.Real)
+		if err := s.Run(ctx, queue, dedupReload); err != nil {
+			log.Errorf("sender is stopped: %v", err)
+		}
+	}()
+	return nil
+}
@@ -1,54 +1,112 @@
 package main
 
 import (
-	"fmt"
+	"os"
 	"strings"
 
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/blob"
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/config"
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/dedup"
 	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/logger"
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/scheduler"
 	internalgrpc "github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/server/grpc"
 	internalhttp "github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/server/http"
 	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/storagebuilder"
-	"github.com/spf13/viper"
+	log "github.com/sirupsen/logrus"
 )
 
-const envConfigPrefix = "$env:"
-
 type Config struct {
 	HTTPServer internalhttp.Config
 	GrpcServer internalgrpc.Config
 	Logger     logger.Config
 	Storage    storagebuilder.Config
+	Blob       blob.Config
+	// Scheduler, sender and their queue are used in all-in-one mode only.
+	Scheduler scheduler.Config
+	Dedup     dedup.Config
+	Queue     QueueConfig
+}
+
+var defaults = map[string]interface{}{
+	"httpServer.host":                "127.0.0.1",
+	"httpServer.port":                "8005",
+	"grpcServer.host":                "127.0.0.1",
+	"grpcServer.port":                "8006",
+	"grpcServer.reflection":          false,
+	"grpcServer.healthCheckInterval": "10s",
+	"grpcServer.keepaliveTime":       "1m",
+	"grpcServer.keepaliveTimeout":    "20s",
+	"grpcServer.keepaliveMinTime":    "30s",
+	"grpcServer.maxRecvMsgSize":      4 << 20,
+	"grpcServer.maxSendMsgSize":      4 << 20,
+	"grpcServer.maxAttachmentSize":   10 << 20,
+	"grpcServer.rateLimit.rate":      0,
+	"grpcServer.rateLimit.burst":     20,
+	"logger.level":                   "WARN",
+	"storage.storageType":            "memory",
+	"blob.type":                      "memory",
+	"blob.sweepInterval":             "1m",
+	"scheduler.checkInterval":        "1m",
+	"scheduler.purgeInterval":        "5m",
+	"scheduler.trashRetention":       "720h",
+	"dedup.storageType":              "memory",
+	"dedup.capacity":                 100000,
+	"dedup.ttl":                      "48h",
+	"queue.capacity":                 10000,
 }
 
 func NewConfig(configFile string) (Config, error) {
-	config := Config{}
-	viper.SetConfigFile(configFile)
+	c := Config{}
+	err := config.Load(configFile, defaults, &c)
+	return c, err
+}
 
-	viper.SetDefault("httpServer.host", "127.0.0.1")
-	viper.SetDefault("httpServer.port", "8005")
-	viper.SetDefault("grpcServer.host", "127.0.0.1")
-	viper.SetDefault("grpcServer.port", "8006")
-	viper.SetDefault("logger.level", "WARN")
-	viper.SetDefault("storage.storageType", "memory")
+func (c Config) Validate(p *config.Problems) {
+	c.HTTPServer.Validate(p, "httpServer")
+	c.GrpcServer.Validate(p, "grpcServer")
+	c.Logger.Validate(p, "logger")
+	c.Storage.Validate(p, "storage")
+	c.Blob.Validate(p, "blob")
+	c.Scheduler.Validate(p, "scheduler")
+	c.Dedup.Validate(p, "dedup")
+	if c.Queue.Capacity <= 0 {
+		p.Add("queue.capacity", "should be positive, got %d", c.Queue.Capacity)
+	}
+}
 
-	err := viper.ReadInConfig()
+// Implements `config check` subcommand, returns exit code.
+func checkConfig() int {
+	_, err := NewConfig(configFile)
+	return config.PrintCheck(os.Stdout, configFile, err)
+}
+
+// Reads the config file again and applies the logger settings, current config is kept if the new one is incorrect.
+// Returned config has new logger, rate limit, blob sweep interval, scheduler intervals
+// and deduplication limits, caller applies all but the logger.
+// Other server, storage, queue and deduplication settings are applied after restart only.
+func reloadConfig(current Config) Config {
+	c, err := NewConfig(configFile)
+	if err == nil {
+		err = logger.PrepareLogger(c.Logger)
+	}
 	if err != nil {
-		return config, fmt.Errorf("failed to read config %q: %w", configFile, err)
+		log.Errorf("failed to reload config, current config is kept: %v", err)
+		return current
 	}
-	keys := viper.AllKeys()
-	for _, key := range keys {
-		env := viper.GetString(key)
-		if strings.HasPrefix(env, envConfigPrefix) {
-			err := viper.BindEnv(key, env[len(envConfigPrefix):])
-			if err != nil {
-				return Config{}, fmt.Errorf("failed to prepare config: %w", err)
-			}
-		}
+	if allInOne {
+		// Memory storage is used in all-in-one mode regardless of the file.
+		c.Storage = current.Storage
 	}
-
-	err = viper.Unmarshal(&config)
-	if err != nil {
-		return config, fmt.Errorf("unable to decode into config struct: %w", err)
+	applied := current
+	applied.Logger = c.Logger
+	applied.GrpcServer.RateLimit = c.GrpcServer.RateLimit
+	applied.Blob.SweepInterval = c.Blob.SweepInterval
+	applied.Scheduler = c.Scheduler
+	applied.Dedup.Capacity, applied.Dedup.TTL = c.Dedup.Capacity, c.Dedup.TTL
+	sections := []string{"httpServer", "grpcServer", "storage", "blob", "dedup", "queue"}
+	if keys := config.Changed(applied, c, sections...); len(keys) > 0 {
+		log.Warnf("changed keys are applied after restart: %s", strings.Join(keys, ", "))
 	}
-	return config, nil
+	log.Info("config is reloaded")
+	return applied
 }
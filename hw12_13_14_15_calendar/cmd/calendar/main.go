@@ -9,17 +9,26 @@ import (
 	"time"
 
 	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/app"
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/blob"
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/clock"
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/dedup"
 	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/logger"
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/scheduler"
 	internalgrpc "github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/server/grpc"
 	internalhttp "github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/server/http"
 	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/storagebuilder"
 	log "github.com/sirupsen/logrus"
 )
 
-var configFile string
+var (
+	configFile string
+	allInOne   bool
+)
 
 func init() {
 	flag.StringVar(&configFile, "config", "./configs/config.yaml", "Path to configuration file")
+	flag.BoolVar(&allInOne, "all-in-one", false,
+		"Run scheduler and sender in the process with memory storage and in-process queue")
 	log.SetFormatter(&log.TextFormatter{})
 	log.SetOutput(os.Stdout)
 	log.SetLevel(log.WarnLevel)
@@ -32,6 +41,9 @@ func main() {
 		printVersion()
 		return
 	}
+	if flag.Arg(0) == "config" && flag.Arg(1) == "check" {
+		os.Exit(checkConfig())
+	}
 
 	config, err := NewConfig(configFile)
 	if err != nil {
@@ -43,18 +55,30 @@ func main() {
 		log.Errorf("failed to start %v", err)
 		return
 	}
-	stor, err := storagebuilder.NewStorage(config.Storage)
+	if allInOne {
+		config.Storage = storagebuilder.Config{StorageType: "memory"}
+	}
+	stor, err := storagebuilder.NewStorage(config.Storage, clock.Real)
 	if err != nil {
 		log.Errorf("failed to start %v", err)
 		return
 	}
 
-	calendar := app.New(stor)
+	blobs, err := blob.New(config.Blob)
+	if err != nil {
+		log.Errorf("failed to start %v", err)
+		return
+	}
+
+	calendar := app.New(stor, blobs, clock.Real)
 	httpServer := internalhttp.NewServer(config.HTTPServer, calendar)
 	grpcServer := internalgrpc.NewServer(config.GrpcServer, calendar)
 
-	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer cancel()
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
 
 	go func() {
 		<-ctx.Done()
@@ -79,6 +103,44 @@ func main() {
 		}
 	}()
 
+	schedulerReload := make(chan scheduler.Config)
+	dedupReload := make(chan dedup.Config)
+	if allInOne {
+		if err := startAllInOne(ctx, stor, config, schedulerReload, dedupReload); err != nil {
+			log.Errorf("failed to start %v", err)
+			cancel()
+			return
+		}
+	}
+	sweepReload := make(chan time.Duration)
+	go calendar.RunBlobSweeper(ctx, config.Blob.SweepInterval, sweepReload)
+	// Only this goroutine uses the config since here.
+	go func(config Config) {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-hup:
+				config = reloadConfig(config)
+				grpcServer.SetRateLimit(config.GrpcServer.RateLimit)
+				select {
+				case sweepReload <- config.Blob.SweepInterval:
+				case <-ctx.Done():
+				}
+				if !allInOne {
+					continue
+				}
+				select {
+				case schedulerReload <- config.Scheduler:
+				case <-ctx.Done():
+				}
+				select {
+				case dedupReload <- config.Dedup:
+				case <-ctx.Done():
+				}
+			}
+		}
+	}(config)
 	log.Info("calendar is running...")
 
 	go func() {
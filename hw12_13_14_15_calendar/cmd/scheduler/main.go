@@ -2,36 +2,23 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"flag"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/broker"
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/clock"
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/leader"
 	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/logger"
-	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/rabbit"
-	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/storage"
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/scheduler"
 	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/storagebuilder"
 	log "github.com/sirupsen/logrus"
 )
 
 var configFile string
 
-const (
-	removeTimeout = time.Minute * 5
-	checkTimout   = time.Minute
-)
-
-func newMessage(event storage.Event) rabbit.Message {
-	return rabbit.Message{
-		ID:      event.ID,
-		Name:    event.Title,
-		Time:    event.StartTime,
-		OwnerID: event.OwnerID,
-	}
-}
-
 func init() {
 	flag.StringVar(&configFile, "config", "./configs/scheduler_config.yaml", "Path to configuration file")
 	log.SetFormatter(&log.TextFormatter{})
@@ -42,6 +29,10 @@ func init() {
 func main() {
 	flag.Parse()
 
+	if flag.Arg(0) == "config" && flag.Arg(1) == "check" {
+		os.Exit(checkConfig())
+	}
+
 	config, err := NewConfig(configFile)
 	if err != nil {
 		log.Errorf("failed to start %v", err)
@@ -53,11 +44,14 @@ func main() {
 		return
 	}
 
-	r := rabbit.New(config.Rabbit)
-	r.Connect()
-	defer r.Close()
+	b, err := broker.New(config.Broker)
+	if err != nil {
+		log.Errorf("failed to start %v", err)
+		return
+	}
+	defer b.Close()
 
-	stor, err := storagebuilder.NewStorage(config.Storage)
+	stor, err := storagebuilder.NewStorage(config.Storage, clock.Real)
 	if err != nil {
 		log.Errorf("failed to start %v", err)
 		return
@@ -68,41 +62,39 @@ func main() {
 		stor.Close(ctx)
 	}()
 
-	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer cancel()
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
 
-	startTime := time.Now().Add(-time.Minute)
-	endTime := time.Now()
-	checkTicker := time.NewTicker(checkTimout)
-	removeTicker := time.NewTicker(removeTimeout)
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		default:
+	elector, err := leader.New(ctx, config.Storage, config.Scheduler.LeaderLockKey)
+	if err != nil {
+		log.Errorf("failed to start %v", err)
+		return
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*3)
+		defer cancel()
+		elector.Close(ctx)
+	}()
 
-			log.Debugf("get events: %s - %s", startTime, endTime)
-			events, err := stor.GetEventsByNotifier(ctx, startTime, endTime)
-			if err != nil {
-				log.Errorf("failed to get events: %s", err)
-				continue
-			}
-			for _, event := range events {
-				log.Debugf("send event: %v", event)
-				m := newMessage(event)
-				data, _ := json.Marshal(m)
-				r.Publish(data)
-			}
+	sched := scheduler.New(stor, b, elector, config.Scheduler, clock.Real)
+	reload := make(chan scheduler.Config)
+	go func() {
+		for {
 			select {
 			case <-ctx.Done():
 				return
-			case <-checkTicker.C:
-				log.Debug("ticker")
-				startTime = endTime
-				endTime = time.Now()
-			case <-removeTicker.C:
-				stor.RemoveAfter(ctx, time.Now().Add(-1*(time.Hour*24*365)))
+			case <-hup:
+				config = reloadConfig(config)
+				select {
+				case reload <- config.Scheduler:
+				case <-ctx.Done():
+				}
 			}
 		}
-	}
+	}()
+
+	sched.Run(ctx, reload)
 }
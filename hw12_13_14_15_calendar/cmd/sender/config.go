@@ -1,50 +1,85 @@
 package main
 
 import (
-	"fmt"
+	"os"
 	"strings"
 
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/broker"
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/config"
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/dedup"
 	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/logger"
-	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/rabbit"
-	"github.com/spf13/viper"
+	log "github.com/sirupsen/logrus"
 )
 
-const envConfigPrefix = "$env:"
-
 type Config struct {
-	Logger logger.Config
-	Rabbit rabbit.Config
+	Logger  logger.Config
+	Broker  broker.Config
+	Dedup   dedup.Config
+	Metrics MetricsConfig
 }
 
-func NewConfig(configFile string) (Config, error) {
-	config := Config{}
-	viper.SetConfigFile(configFile)
+// MetricsConfig is an address of expvar metrics endpoint (/debug/vars), zero port disables it.
+type MetricsConfig struct {
+	Host string
+	Port int
+}
 
-	viper.SetDefault("rabbit.host", "127.0.0.1")
-	viper.SetDefault("rabbit.port", "5672")
-	viper.SetDefault("rabbit.user", "user")
-	viper.SetDefault("rabbit.password", "pass")
-	viper.SetDefault("rabbit.queue", "calendar.notify")
-	viper.SetDefault("logger.level", "WARN")
+var defaults = map[string]interface{}{
+	"broker.type":              "amqp",
+	"broker.amqp.host":         "127.0.0.1",
+	"broker.amqp.port":         "5672",
+	"broker.amqp.user":         "user",
+	"broker.amqp.password":     "pass",
+	"broker.amqp.queue":        "calendar.notify",
+	"broker.file.pollInterval": "1s",
+	"logger.level":             "WARN",
+	"dedup.storageType":        "memory",
+	"dedup.capacity":           100000,
+	"dedup.ttl":                "48h",
+	"metrics.host":             "127.0.0.1",
+	"metrics.port":             0,
+}
 
-	err := viper.ReadInConfig()
-	if err != nil {
-		return config, fmt.Errorf("failed to read config %q: %w", configFile, err)
-	}
-	keys := viper.AllKeys()
-	for _, key := range keys {
-		env := viper.GetString(key)
-		if strings.HasPrefix(env, envConfigPrefix) {
-			err := viper.BindEnv(key, env[len(envConfigPrefix):])
-			if err != nil {
-				return Config{}, fmt.Errorf("failed to prepare config: %w", err)
-			}
-		}
+func NewConfig(configFile string) (Config, error) {
+	c := Config{}
+	err := config.Load(configFile, defaults, &c)
+	return c, err
+}
+
+func (c Config) Validate(p *config.Problems) {
+	c.Logger.Validate(p, "logger")
+	c.Broker.Validate(p, "broker")
+	c.Dedup.Validate(p, "dedup")
+	if c.Metrics.Port != 0 {
+		p.Port("metrics.port", c.Metrics.Port)
 	}
+}
 
-	err = viper.Unmarshal(&config)
+// Implements `config check` subcommand, returns exit code.
+func checkConfig() int {
+	_, err := NewConfig(configFile)
+	return config.PrintCheck(os.Stdout, configFile, err)
+}
+
+// Reads the config file again and applies the logger settings, current config is kept if the new one is incorrect.
+// Deduplication capacity and TTL are applied by sender, other queue, deduplication and metrics settings
+// are applied after restart only.
+func reloadConfig(current Config) Config {
+	c, err := NewConfig(configFile)
+	if err == nil {
+		err = logger.PrepareLogger(c.Logger)
+	}
 	if err != nil {
-		return config, fmt.Errorf("unable to decode into config struct: %w", err)
+		log.Errorf("failed to reload config, current config is kept: %v", err)
+		return current
+	}
+	if keys := config.Changed(current, c, "broker", "dedup.storageType", "dedup.database", "metrics"); len(keys) > 0 {
+		log.Warnf("changed keys are applied after restart: %s", strings.Join(keys, ", "))
 	}
-	return config, nil
+	c.Broker = current.Broker
+	c.Dedup.StorageType = current.Dedup.StorageType
+	c.Dedup.Database = current.Dedup.Database
+	c.Metrics = current.Metrics
+	log.Info("config is reloaded")
+	return c
 }
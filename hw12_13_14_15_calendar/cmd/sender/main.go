@@ -2,22 +2,27 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"flag"
+	"net"
+	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
+	"time"
 
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/broker"
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/clock"
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/dedup"
 	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/logger"
-	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/rabbit"
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/sender"
 	log "github.com/sirupsen/logrus"
-	"github.com/streadway/amqp"
 )
 
 var configFile string
 
 func init() {
-	flag.StringVar(&configFile, "config", "./configs/scheduler_config.yaml", "Path to configuration file")
+	flag.StringVar(&configFile, "config", "./configs/sender_config.yaml", "Path to configuration file")
 	log.SetFormatter(&log.TextFormatter{})
 	log.SetOutput(os.Stdout)
 	log.SetLevel(log.WarnLevel)
@@ -26,6 +31,10 @@ func init() {
 func main() {
 	flag.Parse()
 
+	if flag.Arg(0) == "config" && flag.Arg(1) == "check" {
+		os.Exit(checkConfig())
+	}
+
 	config, err := NewConfig(configFile)
 	if err != nil {
 		log.Errorf("failed to start %v", err)
@@ -37,21 +46,62 @@ func main() {
 		return
 	}
 
-	r := rabbit.New(config.Rabbit)
-	r.Connect()
-	defer r.Close()
+	b, err := broker.New(config.Broker)
+	if err != nil {
+		log.Errorf("failed to start %v", err)
+		return
+	}
+	defer b.Close()
 
-	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer cancel()
 
-	r.Consume(ctx, func(msg amqp.Delivery) {
-		m := rabbit.Message{}
-		err := json.Unmarshal(msg.Body, &m)
-		if err != nil {
-			log.Errorf("failed to parse bytes: %s", err)
-			cancel()
-			return
+	store, err := dedup.New(ctx, config.Dedup, clock.Real)
+	if err != nil {
+		log.Errorf("failed to start %v", err)
+		return
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*3)
+		defer cancel()
+		store.Close(ctx)
+	}()
+
+	if config.Metrics.Port != 0 {
+		go serveMetrics(net.JoinHostPort(config.Metrics.Host, strconv.Itoa(config.Metrics.Port)))
+	}
+
+	s := sender.New(store, sender.LogNotifier{}, config.Dedup.TTL, clock.Real)
+	reload := make(chan dedup.Config)
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-hup:
+				config = reloadConfig(config)
+				select {
+				case reload <- config.Dedup:
+				case <-ctx.Done():
+				}
+			}
 		}
-		log.Printf("sending message %v", m)
-	})
+	}()
+
+	if err := s.Run(ctx, b, reload); err != nil {
+		log.Errorf("sender is stopped: %v", err)
+	}
+}
+
+func serveMetrics(addr string) {
+	log.Printf("starting metrics server on %s", addr)
+	// expvar publishes metrics on default mux.
+	server := &http.Server{Addr: addr, Handler: http.DefaultServeMux, ReadHeaderTimeout: 10 * time.Second}
+	if err := server.ListenAndServe(); err != nil {
+		log.Errorf("metrics server failed: %v", err)
+	}
 }
@@ -2,54 +2,268 @@ package app
 
 import (
 	"context"
+	"errors"
 	"time"
 
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/blob"
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/clock"
 	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/storage"
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/validator"
 )
 
 type App struct {
 	Storage storage.Storage
+	// Content of event attachments.
+	Blobs blob.Store
+	Clock clock.Clock
 }
 
-func New(storage storage.Storage) *App {
-	return &App{Storage: storage}
+func New(storage storage.Storage, blobs blob.Store, clk clock.Clock) *App {
+	return &App{Storage: storage, Blobs: blobs, Clock: clk}
 }
 
-func (a *App) CreateEvent(ctx context.Context, e storage.Event) (string, error) {
+// Ping checks connectivity of the storage.
+func (a *App) Ping(ctx context.Context) error {
+	return a.Storage.Ping(ctx)
+}
+
+// CreateEvent returns validator.ValidationErrors if the event is invalid.
+func (a *App) CreateEvent(ctx context.Context, e storage.Event) (storage.Event, error) {
+	if err := a.ValidateEvent(e); err != nil {
+		return storage.Event{}, err
+	}
 	if err := a.Storage.AddEvent(ctx, &e); err != nil {
-		return "", err
+		return storage.Event{}, err
+	}
+	return e, nil
+}
+
+// UpdateEvent returns updated event, e.Version is checked against stored one if not zero.
+// Returns validator.ValidationErrors if the event is invalid.
+func (a *App) UpdateEvent(ctx context.Context, id string, e storage.Event) (storage.Event, error) {
+	if err := a.ValidateEvent(e); err != nil {
+		return storage.Event{}, err
+	}
+	if err := a.Storage.UpdateEvent(ctx, id, &e); err != nil {
+		return storage.Event{}, err
+	}
+	return e, nil
+}
+
+func (a *App) RemoveEvent(ctx context.Context, id string, version int64) error {
+	return a.Storage.RemoveEvent(ctx, id, version)
+}
+
+func (a *App) RestoreEvent(ctx context.Context, id string) error {
+	return a.Storage.RestoreEvent(ctx, id)
+}
+
+func (a *App) CreateEvents(
+	ctx context.Context,
+	events []storage.Event,
+	opts storage.BatchOptions,
+) ([]storage.BatchResult, error) {
+	return a.runBatch(events, opts, func(valid []storage.Event) ([]storage.BatchResult, error) {
+		return a.Storage.AddEvents(ctx, valid, opts)
+	})
+}
+
+func (a *App) UpdateEvents(
+	ctx context.Context,
+	events []storage.Event,
+	opts storage.BatchOptions,
+) ([]storage.BatchResult, error) {
+	return a.runBatch(events, opts, func(valid []storage.Event) ([]storage.BatchResult, error) {
+		return a.Storage.UpdateEvents(ctx, valid, opts)
+	})
+}
+
+// ValidateEvent checks event fields by rules of storage.Event and its time against the clock,
+// returns validator.ValidationErrors with all violations.
+func (a *App) ValidateEvent(e storage.Event) error {
+	err := validator.Validate(e)
+	var validationErrors validator.ValidationErrors
+	if err != nil && !errors.As(err, &validationErrors) {
+		return err
+	}
+	storage.NormalizeAllDay(&e)
+	if err := storage.CheckEventTime(e, a.Clock.Now()); err != nil {
+		field := "startTime"
+		if !e.EndTime.After(e.StartTime) {
+			field = "endTime"
+		}
+		validationErrors = append(validationErrors, validator.ValidationError{Field: field, Err: err})
 	}
-	return e.ID, nil
+	if len(validationErrors) == 0 {
+		return nil
+	}
+	return validationErrors
 }
 
-func (a *App) UpdateEvent(ctx context.Context, id string, e storage.Event) error {
-	return a.Storage.UpdateEvent(ctx, id, e)
+// Stores valid events of the batch, invalid ones get validation errors in results.
+// All-or-nothing batch is rolled back without storing if any event is invalid.
+func (a *App) runBatch(
+	events []storage.Event,
+	opts storage.BatchOptions,
+	store func(valid []storage.Event) ([]storage.BatchResult, error),
+) ([]storage.BatchResult, error) {
+	results := make([]storage.BatchResult, len(events))
+	valid := make([]storage.Event, 0, len(events))
+	idx := make([]int, 0, len(events))
+	for i, e := range events {
+		if err := a.ValidateEvent(e); err != nil {
+			results[i].Err = err
+			continue
+		}
+		valid = append(valid, e)
+		idx = append(idx, i)
+	}
+	if opts.AllOrNothing && storage.BatchFailed(results) {
+		storage.RollbackBatch(results)
+		return results, storage.ErrBatchRolledBack
+	}
+	if len(valid) == 0 {
+		return results, nil
+	}
+
+	stored, err := store(valid)
+	if err != nil && !errors.Is(err, storage.ErrBatchRolledBack) {
+		return nil, err
+	}
+	for n, i := range idx {
+		results[i] = stored[n]
+	}
+	return results, err
 }
 
-func (a *App) RemoveEvent(ctx context.Context, id string) error {
-	return a.Storage.RemoveEvent(ctx, id)
+func (a *App) RemoveEvents(
+	ctx context.Context,
+	refs []storage.EventRef,
+	opts storage.BatchOptions,
+) ([]storage.BatchResult, error) {
+	return a.Storage.RemoveEvents(ctx, refs, opts)
 }
 
-func (a *App) GetEventsForDay(ctx context.Context, date time.Time) ([]storage.Event, error) {
-	events, err := a.Storage.GetEventsForDay(ctx, date)
+func (a *App) GetDeletedEvents(ctx context.Context, ownerID string) ([]storage.Event, error) {
+	events, err := a.Storage.GetDeletedEvents(ctx, ownerID)
 	if err != nil {
 		return nil, err
 	}
 	return events, nil
 }
 
-func (a *App) GetEventsForWeek(ctx context.Context, startDate time.Time) ([]storage.Event, error) {
-	events, err := a.Storage.GetEventsForWeek(ctx, startDate)
+func (a *App) GetEventHistory(ctx context.Context, id string) ([]storage.EventChange, error) {
+	changes, err := a.Storage.GetEventHistory(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+func (a *App) SearchEvents(ctx context.Context, query storage.SearchQuery) ([]storage.Event, error) {
+	events, err := a.Storage.SearchEvents(ctx, query)
 	if err != nil {
 		return nil, err
 	}
 	return events, nil
 }
 
-func (a *App) GetEventsForMonth(ctx context.Context, startDate time.Time) ([]storage.Event, error) {
-	events, err := a.Storage.GetEventsForMonth(ctx, startDate)
+func (a *App) GetFreeBusy(
+	ctx context.Context,
+	ownerIDs []string,
+	from time.Time,
+	to time.Time,
+) ([]storage.FreeBusy, error) {
+	freeBusy, err := a.Storage.GetFreeBusy(ctx, ownerIDs, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return freeBusy, nil
+}
+
+func (a *App) AcknowledgeReminder(ctx context.Context, eventID string) error {
+	return a.Storage.AcknowledgeReminder(ctx, eventID)
+}
+
+// SnoozeReminder postpones the reminder for the period from now.
+func (a *App) SnoozeReminder(ctx context.Context, eventID string, period time.Duration) error {
+	return a.Storage.SnoozeReminder(ctx, eventID, a.Clock.Now().Add(period))
+}
+
+func (a *App) GetEventsForDay(
+	ctx context.Context,
+	date time.Time,
+	filter storage.EventFilter,
+) ([]storage.Event, error) {
+	events, err := a.Storage.GetEventsForDay(ctx, date, filter)
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+func (a *App) GetEventsForWeek(
+	ctx context.Context,
+	startDate time.Time,
+	filter storage.EventFilter,
+) ([]storage.Event, error) {
+	events, err := a.Storage.GetEventsForWeek(ctx, startDate, filter)
 	if err != nil {
 		return nil, err
 	}
 	return events, nil
 }
+
+func (a *App) GetEventsForMonth(
+	ctx context.Context,
+	startDate time.Time,
+	filter storage.EventFilter,
+) ([]storage.Event, error) {
+	events, err := a.Storage.GetEventsForMonth(ctx, startDate, filter)
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+func (a *App) CreateCalendar(ctx context.Context, c storage.Calendar) (storage.Calendar, error) {
+	if err := a.Storage.CreateCalendar(ctx, &c); err != nil {
+		return storage.Calendar{}, err
+	}
+	return c, nil
+}
+
+func (a *App) UpdateCalendar(ctx context.Context, c storage.Calendar) (storage.Calendar, error) {
+	if err := a.Storage.UpdateCalendar(ctx, &c); err != nil {
+		return storage.Calendar{}, err
+	}
+	return c, nil
+}
+
+func (a *App) RemoveCalendar(ctx context.Context, id string) error {
+	return a.Storage.RemoveCalendar(ctx, id)
+}
+
+func (a *App) GetCalendars(ctx context.Context) ([]storage.Calendar, error) {
+	calendars, err := a.Storage.GetCalendars(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return calendars, nil
+}
+
+func (a *App) ShareCalendar(ctx context.Context, g storage.Grant) error {
+	return a.Storage.ShareCalendar(ctx, g)
+}
+
+func (a *App) RevokeCalendarAccess(ctx context.Context, calendarID string, userID string) error {
+	return a.Storage.RevokeCalendarAccess(ctx, calendarID, userID)
+}
+
+func (a *App) GetCalendarGrants(ctx context.Context, calendarID string) ([]storage.Grant, error) {
+	grants, err := a.Storage.GetCalendarGrants(ctx, calendarID)
+	if err != nil {
+		return nil, err
+	}
+	return grants, nil
+}
@@ -0,0 +1,156 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/blob"
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/storage"
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/validator"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	defaultContentType = "application/octet-stream"
+	sweepBatchSize     = 100
+)
+
+var errURLWithContent = errors.New("attachment with content can not have URL")
+
+// AddAttachment adds link attachment if content is nil, otherwise content is saved to blob store
+// after access to the event is checked and removed from there if the attachment can not be added.
+// Returns validator.ValidationErrors if the attachment is invalid.
+func (a *App) AddAttachment(
+	ctx context.Context,
+	att storage.Attachment,
+	content io.Reader,
+) (storage.Attachment, error) {
+	if err := validateAttachment(att, content != nil); err != nil {
+		return storage.Attachment{}, err
+	}
+	att.BlobKey, att.Size = "", 0
+	if content != nil {
+		if err := a.Storage.CheckAttachmentWrite(ctx, att.EventID); err != nil {
+			return storage.Attachment{}, err
+		}
+		key, err := blob.NewKey()
+		if err != nil {
+			return storage.Attachment{}, err
+		}
+		size, err := a.Blobs.Put(ctx, key, content)
+		if err != nil {
+			return storage.Attachment{}, fmt.Errorf("failed to save attachment content: %w", err)
+		}
+		att.BlobKey, att.Size = key, size
+		if att.ContentType == "" {
+			att.ContentType = defaultContentType
+		}
+	}
+	if err := a.Storage.AddAttachment(ctx, &att); err != nil {
+		a.deleteContent(ctx, att)
+		return storage.Attachment{}, err
+	}
+	return att, nil
+}
+
+func (a *App) GetAttachments(ctx context.Context, eventID string) ([]storage.Attachment, error) {
+	return a.Storage.GetAttachments(ctx, eventID)
+}
+
+// GetAttachmentContent returns attachment with reader of its content which must be closed,
+// storage.ErrNoAttachmentContent is returned for link.
+func (a *App) GetAttachmentContent(ctx context.Context, id string) (storage.Attachment, io.ReadCloser, error) {
+	att, err := a.Storage.GetAttachment(ctx, id)
+	if err != nil {
+		return storage.Attachment{}, nil, err
+	}
+	if att.IsLink() {
+		return storage.Attachment{}, nil, fmt.Errorf("attachment with id %q: %w", id, storage.ErrNoAttachmentContent)
+	}
+	r, err := a.Blobs.Get(ctx, att.BlobKey)
+	if err != nil {
+		return storage.Attachment{}, nil, fmt.Errorf("failed to read attachment content: %w", err)
+	}
+	return att, r, nil
+}
+
+// RemoveAttachment removes the attachment with its content, failure to remove content is only logged.
+func (a *App) RemoveAttachment(ctx context.Context, id string) error {
+	att, err := a.Storage.GetAttachment(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := a.Storage.RemoveAttachment(ctx, id); err != nil {
+		return err
+	}
+	a.deleteContent(ctx, att)
+	return nil
+}
+
+// SweepBlobs deletes content of attachments removed with their events, see storage.Storage.TakeOrphanBlobs.
+// Failure to delete content is only logged.
+func (a *App) SweepBlobs(ctx context.Context) error {
+	for {
+		keys, err := a.Storage.TakeOrphanBlobs(ctx, sweepBatchSize)
+		if err != nil {
+			return fmt.Errorf("failed to get content of removed attachments: %w", err)
+		}
+		for _, key := range keys {
+			if err := a.Blobs.Delete(ctx, key); err != nil {
+				log.Errorf("failed to delete content of removed attachment: %v", err)
+			}
+		}
+		if len(keys) < sweepBatchSize {
+			return nil
+		}
+	}
+}
+
+// RunBlobSweeper calls SweepBlobs with the interval until the context is done,
+// new interval from reload is used since the next call.
+func (a *App) RunBlobSweeper(ctx context.Context, interval time.Duration, reload <-chan time.Duration) {
+	ticker := a.Clock.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case interval = <-reload:
+			ticker.Reset(interval)
+		case <-ticker.C():
+			if err := a.SweepBlobs(ctx); err != nil {
+				log.Error(err)
+			}
+		}
+	}
+}
+
+func (a *App) deleteContent(ctx context.Context, att storage.Attachment) {
+	if att.IsLink() {
+		return
+	}
+	if err := a.Blobs.Delete(ctx, att.BlobKey); err != nil {
+		log.Errorf("failed to delete content of attachment: %v", err)
+	}
+}
+
+// Link attachment must have URL, attachment with content can not have it.
+func validateAttachment(att storage.Attachment, hasContent bool) error {
+	var validationErrors validator.ValidationErrors
+	if err := validator.Validate(att); err != nil && !errors.As(err, &validationErrors) {
+		return err
+	}
+	switch {
+	case hasContent && att.URL != "":
+		validationErrors = append(validationErrors, validator.ValidationError{Field: "url", Err: errURLWithContent})
+	case !hasContent && att.URL == "":
+		validationErrors = append(validationErrors, validator.ValidationError{Field: "url", Err: validator.ErrRequired})
+	}
+	if len(validationErrors) == 0 {
+		return nil
+	}
+	return validationErrors
+}
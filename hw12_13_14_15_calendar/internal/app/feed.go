@@ -0,0 +1,63 @@
+package app
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/storage"
+)
+
+const (
+	// Random bytes of feed token, token is unguessable as it is the only credential of feed.
+	feedTokenSize = 32
+	// Recently ended events stay in feed, older ones are dropped.
+	feedHistory = 30 * 24 * time.Hour
+)
+
+// RotateFeedToken returns new feed token of the user, previous token stops working.
+// Only hash of the token is stored, so the token can not be shown again.
+func (a *App) RotateFeedToken(ctx context.Context, userID string) (string, error) {
+	raw := make([]byte, feedTokenSize)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate feed token: %w", err)
+	}
+	token := hex.EncodeToString(raw)
+	if err := a.Storage.SetFeedToken(ctx, userID, hashFeedToken(token)); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+func (a *App) RevokeFeedToken(ctx context.Context, userID string) error {
+	return a.Storage.RemoveFeedToken(ctx, userID)
+}
+
+// GetFeed returns user of the token with events of the feed ordered by start,
+// storage.ErrNotFoundFeedToken is returned for unknown token.
+func (a *App) GetFeed(ctx context.Context, token string) (string, []storage.Event, error) {
+	userID, err := a.Storage.GetFeedUser(ctx, hashFeedToken(token))
+	if err != nil {
+		return "", nil, err
+	}
+	events, err := a.Storage.GetFeedEvents(storage.ContextWithActor(ctx, userID), a.Clock.Now().Add(-feedHistory))
+	if err != nil {
+		return "", nil, err
+	}
+	sort.Slice(events, func(i, j int) bool {
+		if !events[i].StartTime.Equal(events[j].StartTime) {
+			return events[i].StartTime.Before(events[j].StartTime)
+		}
+		return events[i].ID < events[j].ID
+	})
+	return userID, events, nil
+}
+
+func hashFeedToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
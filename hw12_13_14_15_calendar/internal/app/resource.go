@@ -0,0 +1,37 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/storage"
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/validator"
+)
+
+// CreateResource returns validator.ValidationErrors if the resource is invalid.
+func (a *App) CreateResource(ctx context.Context, r storage.Resource) (storage.Resource, error) {
+	if err := validator.Validate(r); err != nil {
+		return storage.Resource{}, err
+	}
+	if err := a.Storage.CreateResource(ctx, &r); err != nil {
+		return storage.Resource{}, err
+	}
+	return r, nil
+}
+
+func (a *App) RemoveResource(ctx context.Context, id string) error {
+	return a.Storage.RemoveResource(ctx, id)
+}
+
+func (a *App) GetResources(ctx context.Context, filter storage.ResourceFilter) ([]storage.Resource, error) {
+	return a.Storage.GetResources(ctx, filter)
+}
+
+func (a *App) GetResourceSchedule(
+	ctx context.Context,
+	id string,
+	from time.Time,
+	to time.Time,
+) ([]storage.Booking, error) {
+	return a.Storage.GetResourceSchedule(ctx, id, from, to)
+}
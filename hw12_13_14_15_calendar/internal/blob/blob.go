@@ -0,0 +1,65 @@
+package blob
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/config"
+)
+
+var (
+	ErrNotFound     = errors.New("blob not found")
+	ErrIncorrectKey = errors.New("incorrect blob key")
+)
+
+// Store keeps content of event attachments by key.
+type Store interface {
+	// Put writes content from r and returns its size, existing blob with the key is replaced.
+	Put(ctx context.Context, key string, r io.Reader) (int64, error)
+	// Get returns ErrNotFound if there is no blob with the key, returned reader must be closed.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete does nothing if there is no blob with the key.
+	Delete(ctx context.Context, key string) error
+}
+
+type Config struct {
+	// One of file and memory, memory store keeps blobs until the process is stopped.
+	Type string
+	File FileConfig
+	// How often content of attachments removed with their events is deleted.
+	SweepInterval time.Duration
+}
+
+func (c Config) Validate(p *config.Problems, prefix string) {
+	p.OneOf(prefix+".type", c.Type, "file", "memory")
+	if c.Type == "file" {
+		c.File.Validate(p, prefix+".file")
+	}
+	p.Positive(prefix+".sweepInterval", c.SweepInterval)
+}
+
+// New returns blob store of the configured type.
+func New(config Config) (Store, error) {
+	switch config.Type {
+	case "file":
+		return NewFile(config.File)
+	case "memory":
+		return NewMemory(), nil
+	default:
+		return nil, fmt.Errorf("unknown blob store type %q", config.Type)
+	}
+}
+
+// NewKey returns random key for a new blob.
+func NewKey() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate blob key: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
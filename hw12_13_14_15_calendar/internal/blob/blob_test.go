@@ -0,0 +1,72 @@
+package blob_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/blob"
+	"github.com/stretchr/testify/require"
+)
+
+type failingReader struct{}
+
+func (failingReader) Read(_ []byte) (int, error) {
+	return 0, errors.New("read failed")
+}
+
+func TestStores(t *testing.T) {
+	file, err := blob.NewFile(blob.FileConfig{Path: t.TempDir()})
+	require.NoError(t, err)
+	stores := map[string]blob.Store{"file": file, "memory": blob.NewMemory()}
+
+	for name, s := range stores {
+		s := s
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			key, err := blob.NewKey()
+			require.NoError(t, err)
+
+			_, err = s.Get(ctx, key)
+			require.ErrorIs(t, err, blob.ErrNotFound)
+
+			n, err := s.Put(ctx, key, strings.NewReader("agenda"))
+			require.NoError(t, err)
+			require.Equal(t, int64(6), n)
+			require.Equal(t, "agenda", read(t, s, key))
+
+			// Failed write keeps previous content.
+			_, err = s.Put(ctx, key, failingReader{})
+			require.Error(t, err)
+			require.Equal(t, "agenda", read(t, s, key))
+
+			_, err = s.Put(ctx, key, strings.NewReader("minutes"))
+			require.NoError(t, err)
+			require.Equal(t, "minutes", read(t, s, key))
+
+			require.NoError(t, s.Delete(ctx, key))
+			_, err = s.Get(ctx, key)
+			require.ErrorIs(t, err, blob.ErrNotFound)
+			require.NoError(t, s.Delete(ctx, key))
+		})
+	}
+
+	t.Run("file incorrect key", func(t *testing.T) {
+		for _, key := range []string{"", "..", "../x", "a/b"} {
+			_, err := file.Put(context.Background(), key, strings.NewReader("x"))
+			require.ErrorIs(t, err, blob.ErrIncorrectKey)
+		}
+	})
+}
+
+func read(t *testing.T, s blob.Store, key string) string {
+	t.Helper()
+	r, err := s.Get(context.Background(), key)
+	require.NoError(t, err)
+	defer r.Close()
+	content, err := ioutil.ReadAll(r)
+	require.NoError(t, err)
+	return string(content)
+}
@@ -0,0 +1,107 @@
+package blob
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/config"
+)
+
+const (
+	fileTmpDir  = "tmp"
+	fileDataDir = "data"
+)
+
+type FileConfig struct {
+	// Directory of blobs.
+	Path string
+}
+
+func (c FileConfig) Validate(p *config.Problems, prefix string) {
+	p.Required(prefix+".path", c.Path)
+}
+
+// File keeps every blob in a file named by the key. Content is written to tmp
+// and moved to data when complete, so readers never see partially written blob.
+type File struct {
+	tmpDir  string
+	dataDir string
+}
+
+func NewFile(config FileConfig) (*File, error) {
+	f := &File{
+		tmpDir:  filepath.Join(config.Path, fileTmpDir),
+		dataDir: filepath.Join(config.Path, fileDataDir),
+	}
+	for _, dir := range []string{f.tmpDir, f.dataDir} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create blob directory: %w", err)
+		}
+	}
+	return f, nil
+}
+
+func (f *File) Put(_ context.Context, key string, r io.Reader) (int64, error) {
+	name, err := f.path(key)
+	if err != nil {
+		return 0, err
+	}
+	tmp, err := ioutil.TempFile(f.tmpDir, key+"-*")
+	if err != nil {
+		return 0, fmt.Errorf("failed to create blob: %w", err)
+	}
+	n, err := io.Copy(tmp, r)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), name)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return 0, fmt.Errorf("failed to write blob %q: %w", key, err)
+	}
+	return n, nil
+}
+
+func (f *File) Get(_ context.Context, key string) (io.ReadCloser, error) {
+	name, err := f.path(key)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(name)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to open blob %q: %w", key, ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open blob %q: %w", key, err)
+	}
+	return file, nil
+}
+
+func (f *File) Delete(_ context.Context, key string) error {
+	name, err := f.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(name); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete blob %q: %w", key, err)
+	}
+	return nil
+}
+
+// Returns file of the blob, key can not point out of data directory.
+func (f *File) path(key string) (string, error) {
+	if key == "" || key == "." || key == ".." || strings.ContainsAny(key, `/\`) {
+		return "", fmt.Errorf("%w: %q", ErrIncorrectKey, key)
+	}
+	return filepath.Join(f.dataDir, key), nil
+}
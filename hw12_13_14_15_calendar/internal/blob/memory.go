@@ -0,0 +1,51 @@
+package blob
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sync"
+)
+
+// Memory keeps blobs in the process memory.
+type Memory struct {
+	mu   sync.RWMutex
+	data map[string][]byte
+}
+
+func NewMemory() *Memory {
+	return &Memory{data: make(map[string][]byte)}
+}
+
+func (m *Memory) Put(_ context.Context, key string, r io.Reader) (int64, error) {
+	if key == "" {
+		return 0, fmt.Errorf("%w: %q", ErrIncorrectKey, key)
+	}
+	content, err := ioutil.ReadAll(r)
+	if err != nil {
+		return 0, fmt.Errorf("failed to write blob %q: %w", key, err)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.data[key] = content
+	return int64(len(content)), nil
+}
+
+func (m *Memory) Get(_ context.Context, key string) (io.ReadCloser, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	content, ok := m.data[key]
+	if !ok {
+		return nil, fmt.Errorf("failed to open blob %q: %w", key, ErrNotFound)
+	}
+	return ioutil.NopCloser(bytes.NewReader(content)), nil
+}
+
+func (m *Memory) Delete(_ context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.data, key)
+	return nil
+}
@@ -0,0 +1,119 @@
+package broker
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/config"
+	"github.com/streadway/amqp"
+)
+
+type AMQPConfig struct {
+	Host     string
+	Port     int
+	User     string
+	Password string
+	Queue    string
+}
+
+func (c AMQPConfig) Validate(p *config.Problems, prefix string) {
+	p.Required(prefix+".host", c.Host)
+	p.Port(prefix+".port", c.Port)
+	p.Required(prefix+".user", c.User)
+	p.Required(prefix+".queue", c.Queue)
+}
+
+// AMQP is a broker on RabbitMQ queue.
+type AMQP struct {
+	conn    *amqp.Connection
+	queue   amqp.Queue
+	channel *amqp.Channel
+}
+
+func NewAMQP(config AMQPConfig) (*AMQP, error) {
+	a := &AMQP{}
+	err := a.connect(
+		fmt.Sprintf("amqp://%s:%s@%s:%d/", config.User, config.Password, config.Host, config.Port),
+		config.Queue,
+	)
+	if err != nil {
+		if a.conn != nil {
+			a.conn.Close()
+		}
+		return nil, fmt.Errorf("failed to connect to rabbit %s %d: %w", config.Host, config.Port, err)
+	}
+	return a, nil
+}
+
+func (a *AMQP) connect(connString string, queueName string) error {
+	var err error
+	a.conn, err = amqp.Dial(connString)
+	if err != nil {
+		return err
+	}
+
+	a.channel, err = a.conn.Channel()
+	if err != nil {
+		return err
+	}
+	a.queue, err = a.channel.QueueDeclare(
+		queueName,
+		false,
+		true,
+		false,
+		false,
+		nil,
+	)
+	return err
+}
+
+func (a *AMQP) Close() error {
+	return a.conn.Close()
+}
+
+func (a *AMQP) Publish(_ context.Context, body []byte) error {
+	return a.channel.Publish(
+		"",           // exchange
+		a.queue.Name, // routing key
+		false,        // mandatory
+		false,        // immediate
+		amqp.Publishing{
+			ContentType: "text/plain",
+			Body:        body,
+		})
+}
+
+func (a *AMQP) Consume(ctx context.Context, handler Handler) error {
+	msgs, err := a.channel.Consume(
+		a.queue.Name, // queue
+		"",           // consumer
+		false,        // auto-ack
+		false,        // exclusive
+		false,        // no-local
+		false,        // no-wait
+		nil,          // args
+	)
+	if err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case m, ok := <-msgs:
+			if !ok {
+				return ErrClosed
+			}
+			handler(ctx, Delivery{
+				Body: m.Body,
+				ack: func() error {
+					return m.Ack(false)
+				},
+				nack: func(requeue bool) error {
+					return m.Nack(false, requeue)
+				},
+			})
+		}
+	}
+}
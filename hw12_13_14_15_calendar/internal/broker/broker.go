@@ -0,0 +1,88 @@
+package broker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/config"
+)
+
+var (
+	ErrClosed    = errors.New("broker is closed")
+	ErrQueueFull = errors.New("queue is full")
+)
+
+// Broker is a queue of notification messages.
+type Broker interface {
+	Publish(ctx context.Context, body []byte) error
+	// Consume passes messages to handler until ctx is done, every delivery must be acknowledged or rejected.
+	Consume(ctx context.Context, handler Handler) error
+	Close() error
+}
+
+type Handler func(ctx context.Context, d Delivery)
+
+// Delivery is a consumed message.
+type Delivery struct {
+	Body []byte
+	ack  func() error
+	nack func(requeue bool) error
+}
+
+// Ack removes the message from the queue.
+func (d Delivery) Ack() error {
+	return d.ack()
+}
+
+// Nack rejects the message, it is delivered again if requeue is true and dropped otherwise.
+func (d Delivery) Nack(requeue bool) error {
+	return d.nack(requeue)
+}
+
+type Config struct {
+	// One of amqp and file, in-process memory queue is used in calendar all-in-one mode only.
+	Type string
+	AMQP AMQPConfig
+	File FileConfig
+}
+
+func (c Config) Validate(p *config.Problems, prefix string) {
+	p.OneOf(prefix+".type", c.Type, "amqp", "file")
+	switch c.Type {
+	case "amqp":
+		c.AMQP.Validate(p, prefix+".amqp")
+	case "file":
+		c.File.Validate(p, prefix+".file")
+	}
+}
+
+// New returns connected broker of the configured type.
+func New(config Config) (Broker, error) {
+	switch config.Type {
+	case "amqp":
+		return NewAMQP(config.AMQP)
+	case "file":
+		return NewFile(config.File)
+	default:
+		return nil, fmt.Errorf("unknown broker type %q", config.Type)
+	}
+}
+
+// Message is a notification about event reminder.
+type Message struct {
+	ID      string
+	Name    string
+	Time    time.Time
+	OwnerID string
+	// Time of the reminder.
+	RemindAt time.Time
+	// Idempotency key, the same for every resend of the reminder.
+	Key string
+}
+
+// MessageKey returns idempotency key of the event reminder.
+func MessageKey(eventID string, remindAt time.Time) string {
+	return eventID + "@" + remindAt.UTC().Format(time.RFC3339)
+}
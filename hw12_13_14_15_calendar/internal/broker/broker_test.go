@@ -0,0 +1,22 @@
+package broker_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/broker"
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig(t *testing.T) {
+	// Messages of memory queue would not reach another process.
+	p := &config.Problems{}
+	broker.Config{Type: "memory"}.Validate(p, "broker")
+	var configErr *config.Error
+	require.True(t, errors.As(p.Err(), &configErr))
+	require.Equal(t, []string{`broker.type: should be one of amqp, file, got "memory"`}, configErr.Problems)
+
+	_, err := broker.New(broker.Config{Type: "memory"})
+	require.Error(t, err)
+}
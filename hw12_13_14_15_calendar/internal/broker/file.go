@@ -0,0 +1,164 @@
+package broker
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/config"
+)
+
+const (
+	fileTmpDir = "tmp"
+	fileNewDir = "new"
+	fileCurDir = "cur"
+)
+
+type FileConfig struct {
+	// Directory of the queue shared by publishing and consuming processes.
+	Path string
+	// How often consumer checks new messages.
+	PollInterval time.Duration
+}
+
+func (c FileConfig) Validate(p *config.Problems, prefix string) {
+	p.Required(prefix+".path", c.Path)
+	p.Positive(prefix+".pollInterval", c.PollInterval)
+}
+
+// Number of published messages, makes file names unique within the process.
+var fileSeq uint64
+
+// File is a durable queue in a directory for processes of one host.
+// Message is written to tmp and moved to new when complete, consumer claims it by moving to cur
+// and removes it on acknowledge. Messages left in cur by stopped consumer are returned to new
+// when consumer starts, so several consumers of one directory can get the same message twice.
+type File struct {
+	tmpDir       string
+	newDir       string
+	curDir       string
+	pollInterval time.Duration
+}
+
+func NewFile(config FileConfig) (*File, error) {
+	f := &File{
+		tmpDir:       filepath.Join(config.Path, fileTmpDir),
+		newDir:       filepath.Join(config.Path, fileNewDir),
+		curDir:       filepath.Join(config.Path, fileCurDir),
+		pollInterval: config.PollInterval,
+	}
+	for _, dir := range []string{f.tmpDir, f.newDir, f.curDir} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create queue directory: %w", err)
+		}
+	}
+	return f, nil
+}
+
+func (f *File) Publish(_ context.Context, body []byte) error {
+	// Names are ordered by publishing time.
+	name := fmt.Sprintf("%020d-%d-%d", time.Now().UnixNano(), os.Getpid(), atomic.AddUint64(&fileSeq, 1))
+	tmp := filepath.Join(f.tmpDir, name)
+	if err := writeSynced(tmp, body); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+	if err := os.Rename(tmp, filepath.Join(f.newDir, name)); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to publish message: %w", err)
+	}
+	return nil
+}
+
+func (f *File) Consume(ctx context.Context, handler Handler) error {
+	if err := f.recoverClaimed(); err != nil {
+		return err
+	}
+	ticker := time.NewTicker(f.pollInterval)
+	defer ticker.Stop()
+	for {
+		if err := f.consumeNew(ctx, handler); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (f *File) Close() error {
+	return nil
+}
+
+// Returns messages claimed by stopped consumers to the queue.
+func (f *File) recoverClaimed() error {
+	entries, err := os.ReadDir(f.curDir)
+	if err != nil {
+		return fmt.Errorf("failed to read claimed messages: %w", err)
+	}
+	for _, e := range entries {
+		if err := os.Rename(filepath.Join(f.curDir, e.Name()), filepath.Join(f.newDir, e.Name())); err != nil {
+			return fmt.Errorf("failed to return claimed message: %w", err)
+		}
+	}
+	return nil
+}
+
+func (f *File) consumeNew(ctx context.Context, handler Handler) error {
+	// Entries are sorted by name, so messages are consumed in publishing order.
+	entries, err := os.ReadDir(f.newDir)
+	if err != nil {
+		return fmt.Errorf("failed to read messages: %w", err)
+	}
+	for _, e := range entries {
+		if ctx.Err() != nil {
+			return nil
+		}
+		name := e.Name()
+		claimed := filepath.Join(f.curDir, name)
+		if err := os.Rename(filepath.Join(f.newDir, name), claimed); err != nil {
+			if os.IsNotExist(err) {
+				// Claimed by another consumer.
+				continue
+			}
+			return fmt.Errorf("failed to claim message: %w", err)
+		}
+		body, err := ioutil.ReadFile(claimed)
+		if err != nil {
+			return fmt.Errorf("failed to read message: %w", err)
+		}
+		handler(ctx, Delivery{
+			Body: body,
+			ack: func() error {
+				return os.Remove(claimed)
+			},
+			nack: func(requeue bool) error {
+				if requeue {
+					return os.Rename(claimed, filepath.Join(f.newDir, name))
+				}
+				return os.Remove(claimed)
+			},
+		})
+	}
+	return nil
+}
+
+func writeSynced(name string, data []byte) error {
+	file, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err = file.Write(data); err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
@@ -0,0 +1,83 @@
+package broker_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/broker"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFile(t *testing.T) {
+	config := broker.FileConfig{Path: t.TempDir(), PollInterval: 10 * time.Millisecond}
+
+	t.Run("order and acknowledge", func(t *testing.T) {
+		b, err := broker.NewFile(config)
+		require.NoError(t, err)
+		for _, m := range []string{"a", "b", "c"} {
+			require.NoError(t, b.Publish(context.Background(), []byte(m)))
+		}
+		require.Equal(t, []string{"a", "b", "c"}, consume(t, b, 3, func(d broker.Delivery) {
+			require.NoError(t, d.Ack())
+		}))
+
+		require.NoError(t, b.Publish(context.Background(), []byte("d")))
+		require.Equal(t, []string{"d"}, consume(t, b, 1, func(d broker.Delivery) {
+			require.NoError(t, d.Ack())
+		}))
+	})
+
+	t.Run("reject", func(t *testing.T) {
+		b, err := broker.NewFile(config)
+		require.NoError(t, err)
+		require.NoError(t, b.Publish(context.Background(), []byte("a")))
+		require.NoError(t, b.Publish(context.Background(), []byte("b")))
+
+		rejected := false
+		require.Equal(t, []string{"a", "b", "a"}, consume(t, b, 3, func(d broker.Delivery) {
+			if string(d.Body) == "a" && !rejected {
+				rejected = true
+				require.NoError(t, d.Nack(true))
+				return
+			}
+			require.NoError(t, d.Ack())
+		}))
+
+		require.NoError(t, b.Publish(context.Background(), []byte("c")))
+		require.Equal(t, []string{"c"}, consume(t, b, 1, func(d broker.Delivery) {
+			require.NoError(t, d.Nack(false))
+		}))
+	})
+
+	t.Run("not acknowledged", func(t *testing.T) {
+		b, err := broker.NewFile(config)
+		require.NoError(t, err)
+		require.NoError(t, b.Publish(context.Background(), []byte("a")))
+		// Consumer is stopped before acknowledge, message is delivered to the next one.
+		require.Equal(t, []string{"a"}, consume(t, b, 1, func(d broker.Delivery) {}))
+
+		other, err := broker.NewFile(config)
+		require.NoError(t, err)
+		require.Equal(t, []string{"a"}, consume(t, other, 1, func(d broker.Delivery) {
+			require.NoError(t, d.Ack())
+		}))
+	})
+}
+
+// Consumes count messages and stops consumer.
+func consume(t *testing.T, b broker.Broker, count int, handle func(d broker.Delivery)) []string {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	bodies := make([]string, 0, count)
+	err := b.Consume(ctx, func(_ context.Context, d broker.Delivery) {
+		bodies = append(bodies, string(d.Body))
+		handle(d)
+		if len(bodies) == count {
+			cancel()
+		}
+	})
+	require.NoError(t, err)
+	return bodies
+}
@@ -0,0 +1,47 @@
+package broker
+
+import "context"
+
+// Memory is an in-process queue, messages are lost on exit.
+type Memory struct {
+	messages chan []byte
+}
+
+func NewMemory(capacity int) *Memory {
+	return &Memory{messages: make(chan []byte, capacity)}
+}
+
+func (m *Memory) Publish(_ context.Context, body []byte) error {
+	select {
+	case m.messages <- body:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+func (m *Memory) Consume(ctx context.Context, handler Handler) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case body := <-m.messages:
+			handler(ctx, Delivery{
+				Body: body,
+				ack: func() error {
+					return nil
+				},
+				nack: func(requeue bool) error {
+					if requeue {
+						return m.Publish(ctx, body)
+					}
+					return nil
+				},
+			})
+		}
+	}
+}
+
+func (m *Memory) Close() error {
+	return nil
+}
@@ -0,0 +1,60 @@
+package clock_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/clock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFakeTicker(t *testing.T) {
+	start := time.Date(2300, 0o1, 0o1, 10, 0, 0, 0, time.UTC)
+	c := clock.NewFake(start)
+	ticker := c.NewTicker(time.Minute)
+
+	c.Advance(30 * time.Second)
+	require.Equal(t, 0, c.Pending())
+
+	c.Advance(30 * time.Second)
+	require.Equal(t, start.Add(time.Minute), <-ticker.C())
+
+	// Missed ticks are dropped.
+	c.Advance(5 * time.Minute)
+	c.Advance(time.Minute)
+	require.Equal(t, 1, c.Pending())
+	require.Equal(t, start.Add(6*time.Minute), <-ticker.C())
+
+	ticker.Reset(time.Hour)
+	c.Advance(time.Minute)
+	require.Equal(t, 0, c.Pending())
+	c.Advance(time.Hour)
+	require.Equal(t, start.Add(68*time.Minute), <-ticker.C())
+
+	ticker.Stop()
+	require.Equal(t, 0, c.Tickers())
+	c.Advance(time.Hour)
+	require.Equal(t, 0, len(ticker.C()))
+	require.Equal(t, start.Add(128*time.Minute), c.Now())
+}
+
+func TestFakeTimer(t *testing.T) {
+	start := time.Date(2300, 0o1, 0o1, 10, 0, 0, 0, time.UTC)
+	c := clock.NewFake(start)
+	timer := c.NewTimer(time.Minute)
+
+	c.Advance(30 * time.Second)
+	require.Equal(t, 0, len(timer.C()))
+	c.Advance(time.Minute)
+	require.Equal(t, start.Add(90*time.Second), <-timer.C())
+	require.Equal(t, 0, c.Timers())
+
+	c.Advance(time.Hour)
+	require.Equal(t, 0, len(timer.C()))
+
+	require.False(t, timer.Reset(time.Minute))
+	require.True(t, timer.Stop())
+	c.Advance(time.Hour)
+	require.Equal(t, 0, len(timer.C()))
+	require.False(t, timer.Stop())
+}
@@ -0,0 +1,197 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// EnvPrefix is a prefix of environment variables overriding config values,
+// e.g. CALENDAR_STORAGE_DATABASE_HOST overrides storage.database.host.
+const EnvPrefix = "CALENDAR"
+
+// Validator is implemented by configs checking their values after loading.
+type Validator interface {
+	Validate(p *Problems)
+}
+
+// Error lists all problems found in the config.
+type Error struct {
+	Problems []string
+}
+
+func (e *Error) Error() string {
+	return "incorrect config:\n\t" + strings.Join(e.Problems, "\n\t")
+}
+
+// Problems collects config problems to report all of them at once.
+type Problems struct {
+	list []string
+}
+
+func (p *Problems) Add(key string, format string, args ...interface{}) {
+	p.list = append(p.list, key+": "+fmt.Sprintf(format, args...))
+}
+
+func (p *Problems) Required(key string, value string) {
+	if value == "" {
+		p.Add(key, "is required")
+	}
+}
+
+func (p *Problems) Port(key string, port int) {
+	if port < 1 || port > 65535 {
+		p.Add(key, "should be in range 1-65535, got %d", port)
+	}
+}
+
+func (p *Problems) OneOf(key string, value string, allowed ...string) {
+	for _, a := range allowed {
+		if value == a {
+			return
+		}
+	}
+	p.Add(key, "should be one of %s, got %q", strings.Join(allowed, ", "), value)
+}
+
+func (p *Problems) Positive(key string, d time.Duration) {
+	if d <= 0 {
+		p.Add(key, "should be positive, got %s", d)
+	}
+}
+
+// Err returns nil if there are no problems.
+func (p *Problems) Err() error {
+	if len(p.list) == 0 {
+		return nil
+	}
+	return &Error{Problems: p.list}
+}
+
+// Load reads the config file into config (pointer to struct), defaults are set by dotted keys.
+// Every key can be overridden by environment variable with EnvPrefix.
+// Unknown keys, values of wrong types and problems found by Validator are returned in one Error.
+func Load(configFile string, defaults map[string]interface{}, config interface{}) error {
+	v := viper.New()
+	v.SetConfigFile(configFile)
+	for key, value := range defaults {
+		v.SetDefault(key, value)
+	}
+
+	v.SetEnvPrefix(EnvPrefix)
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	known := make(map[string]bool)
+	for _, key := range keys(reflect.TypeOf(config).Elem(), "") {
+		known[key] = true
+		if err := v.BindEnv(key); err != nil {
+			return fmt.Errorf("failed to bind environment variable for %q: %w", key, err)
+		}
+	}
+
+	if err := v.ReadInConfig(); err != nil {
+		return fmt.Errorf("failed to read config %q: %w", configFile, err)
+	}
+
+	problems := &Problems{}
+	allKeys := v.AllKeys()
+	sort.Strings(allKeys)
+	for _, key := range allKeys {
+		if !known[key] {
+			problems.Add(key, "unknown key")
+		}
+	}
+	if err := v.Unmarshal(config); err != nil {
+		problems.Add("config", "failed to decode values: %v", err)
+	}
+	if validator, ok := config.(Validator); ok {
+		validator.Validate(problems)
+	}
+	return problems.Err()
+}
+
+// PrintCheck prints result of `config check` subcommand and returns exit code.
+func PrintCheck(w io.Writer, configFile string, err error) int {
+	if err != nil {
+		fmt.Fprintf(w, "config %q: %v\n", configFile, err)
+		return 1
+	}
+	fmt.Fprintf(w, "config %q is correct\n", configFile)
+	return 0
+}
+
+// Changed returns keys of leaf fields which differ in configs of the same struct type, keys are named
+// like unknown keys in Error. If sections are given, only keys of these sections are returned.
+func Changed(old interface{}, updated interface{}, sections ...string) []string {
+	var result []string
+	for _, key := range changed(reflect.ValueOf(old), reflect.ValueOf(updated), "") {
+		if len(sections) == 0 {
+			result = append(result, key)
+			continue
+		}
+		for _, s := range sections {
+			if s = strings.ToLower(s); key == s || strings.HasPrefix(key, s+".") {
+				result = append(result, key)
+				break
+			}
+		}
+	}
+	return result
+}
+
+// Returns lowercase dotted keys of all leaf fields, as viper names them.
+func keys(t reflect.Type, prefix string) []string {
+	var result []string
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		name := keyName(f, prefix)
+		if isSection(f.Type) {
+			result = append(result, keys(f.Type, name)...)
+			continue
+		}
+		result = append(result, name)
+	}
+	return result
+}
+
+// Returns keys of leaf fields with different values, see keys.
+func changed(old reflect.Value, updated reflect.Value, prefix string) []string {
+	var result []string
+	for i := 0; i < old.NumField(); i++ {
+		f := old.Type().Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		name := keyName(f, prefix)
+		if isSection(f.Type) {
+			result = append(result, changed(old.Field(i), updated.Field(i), name)...)
+			continue
+		}
+		if !reflect.DeepEqual(old.Field(i).Interface(), updated.Field(i).Interface()) {
+			result = append(result, name)
+		}
+	}
+	return result
+}
+
+func keyName(f reflect.StructField, prefix string) string {
+	name := strings.ToLower(f.Name)
+	if tag := f.Tag.Get("mapstructure"); tag != "" {
+		name = strings.ToLower(strings.Split(tag, ",")[0])
+	}
+	if prefix != "" {
+		name = prefix + "." + name
+	}
+	return name
+}
+
+func isSection(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t != reflect.TypeOf(time.Time{})
+}
@@ -0,0 +1,107 @@
+package config_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/config"
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/storagebuilder"
+	"github.com/stretchr/testify/require"
+)
+
+type testConfig struct {
+	Storage  storagebuilder.Config
+	Interval time.Duration
+}
+
+func (c testConfig) Validate(p *config.Problems) {
+	c.Storage.Validate(p, "storage")
+	p.Positive("interval", c.Interval)
+}
+
+func TestLoad(t *testing.T) {
+	defaults := map[string]interface{}{"interval": "1m"}
+
+	t.Run("defaults and file", func(t *testing.T) {
+		file := writeConfig(t, "storage:\n  storageType: sql\n  database:\n    host: db\n    port: 5432\n"+
+			"    database: calendar\n    username: user\n")
+		c := testConfig{}
+		require.NoError(t, config.Load(file, defaults, &c))
+		require.Equal(t, "sql", c.Storage.StorageType)
+		require.Equal(t, "db", c.Storage.Database.Host)
+		require.Equal(t, 5432, c.Storage.Database.Port)
+		require.Equal(t, time.Minute, c.Interval)
+	})
+
+	t.Run("environment overrides", func(t *testing.T) {
+		file := writeConfig(t, "storage:\n  storageType: memory\n")
+		setEnv(t, "CALENDAR_STORAGE_STORAGETYPE", "sql")
+		setEnv(t, "CALENDAR_STORAGE_DATABASE_HOST", "db")
+		setEnv(t, "CALENDAR_STORAGE_DATABASE_PORT", "5432")
+		setEnv(t, "CALENDAR_STORAGE_DATABASE_DATABASE", "calendar")
+		setEnv(t, "CALENDAR_STORAGE_DATABASE_USERNAME", "user")
+		setEnv(t, "CALENDAR_INTERVAL", "5s")
+		c := testConfig{}
+		require.NoError(t, config.Load(file, defaults, &c))
+		require.Equal(t, "sql", c.Storage.StorageType)
+		require.Equal(t, "db", c.Storage.Database.Host)
+		require.Equal(t, 5432, c.Storage.Database.Port)
+		require.Equal(t, 5*time.Second, c.Interval)
+	})
+
+	t.Run("all problems", func(t *testing.T) {
+		file := writeConfig(t, "storage:\n  storageType: sq\n  databse:\n    host: db\ninterval: -1s\n")
+		err := config.Load(file, defaults, &testConfig{})
+		var configErr *config.Error
+		require.True(t, errors.As(err, &configErr))
+		require.Equal(t, []string{
+			"storage.databse.host: unknown key",
+			`storage.storageType: should be one of memory, sql, got "sq"`,
+			"interval: should be positive, got -1s",
+		}, configErr.Problems)
+	})
+
+	t.Run("no file", func(t *testing.T) {
+		err := config.Load(filepath.Join(t.TempDir(), "config.yaml"), defaults, &testConfig{})
+		require.Error(t, err)
+		var configErr *config.Error
+		require.False(t, errors.As(err, &configErr))
+	})
+}
+
+func TestChanged(t *testing.T) {
+	old := testConfig{Storage: storagebuilder.Config{StorageType: "memory"}, Interval: time.Minute}
+	updated := old
+	require.Empty(t, config.Changed(old, updated))
+
+	updated.Storage.StorageType = "sql"
+	updated.Storage.Database.Host = "db"
+	updated.Interval = time.Hour
+	require.Equal(
+		t,
+		[]string{"storage.storagetype", "storage.database.host", "interval"},
+		config.Changed(old, updated),
+	)
+	require.Equal(t, []string{"storage.storagetype", "storage.database.host"}, config.Changed(old, updated, "storage"))
+	require.Equal(t, []string{"interval"}, config.Changed(old, updated, "Interval", "stor"))
+}
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, ioutil.WriteFile(file, []byte(content), 0o600))
+	return file
+}
+
+// Sets environment variable for the test, t.Setenv is not available in go 1.16.
+func setEnv(t *testing.T, key string, value string) {
+	t.Helper()
+	require.NoError(t, os.Setenv(key, value))
+	t.Cleanup(func() {
+		os.Unsetenv(key)
+	})
+}
@@ -0,0 +1,106 @@
+package dedup
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/clock"
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/config"
+	sqlstorage "github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/storage/sql"
+)
+
+// Store remembers keys of delivered notifications for TTL.
+type Store interface {
+	// Add remembers the key, false is returned if the key is already known.
+	Add(ctx context.Context, key string) (bool, error)
+	// Forget removes the key, it is used when notification is not delivered after Add.
+	Forget(ctx context.Context, key string) error
+	// Purge forgets expired keys.
+	Purge(ctx context.Context) error
+	// SetLimits changes capacity and TTL of kept keys, it is used to apply reloaded config.
+	SetLimits(capacity int, ttl time.Duration)
+	Close(ctx context.Context) error
+}
+
+type Config struct {
+	// Keys are kept in memory and additionally in database for sql storage type.
+	StorageType string
+	Database    sqlstorage.Config
+	// Max number of keys kept in memory.
+	Capacity int
+	TTL      time.Duration
+}
+
+func (c Config) Validate(p *config.Problems, prefix string) {
+	p.OneOf(prefix+".storageType", c.StorageType, "memory", "sql")
+	if c.StorageType == "sql" {
+		c.Database.Validate(p, prefix+".database")
+	}
+	if c.Capacity <= 0 {
+		p.Add(prefix+".capacity", "should be positive, got %d", c.Capacity)
+	}
+	p.Positive(prefix+".ttl", c.TTL)
+}
+
+// New returns in-memory store backed by database for sql storage type.
+func New(ctx context.Context, config Config, clk clock.Clock) (Store, error) {
+	memory := NewMemory(config.Capacity, config.TTL, clk)
+	switch config.StorageType {
+	case "memory":
+		return memory, nil
+	case "sql":
+		db, err := sqlx.ConnectContext(ctx, "postgres", config.Database.DSN())
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to database %s %d: %w", config.Database.Host, config.Database.Port, err)
+		}
+		return NewTiered(memory, NewSQL(db, config.TTL, clk)), nil
+	default:
+		return nil, fmt.Errorf("unknown storage type %q", config.StorageType)
+	}
+}
+
+// Tiered checks keys in fast store first and then in shared one.
+type Tiered struct {
+	fast   Store
+	shared Store
+}
+
+func NewTiered(fast Store, shared Store) *Tiered {
+	return &Tiered{fast: fast, shared: shared}
+}
+
+func (t *Tiered) Add(ctx context.Context, key string) (bool, error) {
+	added, err := t.fast.Add(ctx, key)
+	if err != nil || !added {
+		return added, err
+	}
+	return t.shared.Add(ctx, key)
+}
+
+func (t *Tiered) Forget(ctx context.Context, key string) error {
+	if err := t.fast.Forget(ctx, key); err != nil {
+		return err
+	}
+	return t.shared.Forget(ctx, key)
+}
+
+func (t *Tiered) Purge(ctx context.Context) error {
+	if err := t.fast.Purge(ctx); err != nil {
+		return err
+	}
+	return t.shared.Purge(ctx)
+}
+
+func (t *Tiered) SetLimits(capacity int, ttl time.Duration) {
+	t.fast.SetLimits(capacity, ttl)
+	t.shared.SetLimits(capacity, ttl)
+}
+
+func (t *Tiered) Close(ctx context.Context) error {
+	if err := t.fast.Close(ctx); err != nil {
+		return err
+	}
+	return t.shared.Close(ctx)
+}
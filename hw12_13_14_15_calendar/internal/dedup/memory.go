@@ -0,0 +1,94 @@
+package dedup
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/clock"
+)
+
+// Memory keeps limited number of keys, the oldest keys are dropped when capacity is reached.
+type Memory struct {
+	mu       sync.Mutex
+	capacity int
+	ttl      time.Duration
+	keys     map[string]*list.Element
+	// Entries in order of adding, the oldest go first.
+	order *list.List
+	clock clock.Clock
+}
+
+type entry struct {
+	key     string
+	addedAt time.Time
+}
+
+func NewMemory(capacity int, ttl time.Duration, clk clock.Clock) *Memory {
+	return &Memory{
+		capacity: capacity,
+		ttl:      ttl,
+		keys:     make(map[string]*list.Element),
+		order:    list.New(),
+		clock:    clk,
+	}
+}
+
+func (m *Memory) Add(_ context.Context, key string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.clock.Now()
+	m.purge(now)
+	if _, ok := m.keys[key]; ok {
+		return false, nil
+	}
+	for m.order.Len() > 0 && m.order.Len() >= m.capacity {
+		m.remove(m.order.Front())
+	}
+	m.keys[key] = m.order.PushBack(entry{key: key, addedAt: now})
+	return true, nil
+}
+
+func (m *Memory) Forget(_ context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if e, ok := m.keys[key]; ok {
+		m.remove(e)
+	}
+	return nil
+}
+
+func (m *Memory) Purge(_ context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.purge(m.clock.Now())
+	return nil
+}
+
+// SetLimits drops the oldest keys if there are more than the new capacity.
+func (m *Memory) SetLimits(capacity int, ttl time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.capacity, m.ttl = capacity, ttl
+	for m.order.Len() > m.capacity {
+		m.remove(m.order.Front())
+	}
+}
+
+func (m *Memory) Close(_ context.Context) error {
+	return nil
+}
+
+// Must be called under lock.
+func (m *Memory) purge(now time.Time) {
+	for e := m.order.Front(); e != nil && now.Sub(e.Value.(entry).addedAt) >= m.ttl; e = m.order.Front() {
+		m.remove(e)
+	}
+}
+
+// Must be called under lock.
+func (m *Memory) remove(e *list.Element) {
+	delete(m.keys, e.Value.(entry).key)
+	m.order.Remove(e)
+}
@@ -0,0 +1,69 @@
+package dedup_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/clock"
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/dedup"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemory(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("duplicates", func(t *testing.T) {
+		m := dedup.NewMemory(10, time.Hour, clock.Real)
+		requireAdded(t, m, "a", true)
+		requireAdded(t, m, "b", true)
+		requireAdded(t, m, "a", false)
+		requireAdded(t, m, "b", false)
+	})
+
+	t.Run("capacity", func(t *testing.T) {
+		m := dedup.NewMemory(2, time.Hour, clock.Real)
+		requireAdded(t, m, "a", true)
+		requireAdded(t, m, "b", true)
+		requireAdded(t, m, "c", true)
+		requireAdded(t, m, "b", false)
+		requireAdded(t, m, "a", true)
+	})
+
+	t.Run("ttl", func(t *testing.T) {
+		clk := clock.NewFake(time.Now())
+		m := dedup.NewMemory(10, time.Hour, clk)
+		requireAdded(t, m, "a", true)
+		requireAdded(t, m, "a", false)
+		clk.Advance(time.Hour)
+		require.NoError(t, m.Purge(ctx))
+		requireAdded(t, m, "a", true)
+	})
+
+	t.Run("forget", func(t *testing.T) {
+		m := dedup.NewMemory(10, time.Hour, clock.Real)
+		requireAdded(t, m, "a", true)
+		require.NoError(t, m.Forget(ctx, "a"))
+		require.NoError(t, m.Forget(ctx, "b"))
+		requireAdded(t, m, "a", true)
+	})
+
+	t.Run("tiered", func(t *testing.T) {
+		shared := dedup.NewMemory(10, time.Hour, clock.Real)
+		first := dedup.NewTiered(dedup.NewMemory(10, time.Hour, clock.Real), shared)
+		second := dedup.NewTiered(dedup.NewMemory(10, time.Hour, clock.Real), shared)
+		requireAdded(t, first, "a", true)
+		requireAdded(t, second, "a", false)
+		requireAdded(t, second, "b", true)
+		requireAdded(t, first, "b", false)
+		require.NoError(t, first.Forget(ctx, "b"))
+		requireAdded(t, first, "b", true)
+	})
+}
+
+func requireAdded(t *testing.T, store dedup.Store, key string, expected bool) {
+	t.Helper()
+	added, err := store.Add(context.Background(), key)
+	require.NoError(t, err)
+	require.Equal(t, expected, added, "key %q", key)
+}
@@ -0,0 +1,78 @@
+package dedup
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/clock"
+)
+
+// SQL keeps keys in database table shared between instances.
+type SQL struct {
+	db    *sqlx.DB
+	mu    sync.RWMutex
+	ttl   time.Duration
+	clock clock.Clock
+}
+
+func NewSQL(db *sqlx.DB, ttl time.Duration, clk clock.Clock) *SQL {
+	return &SQL{db: db, ttl: ttl, clock: clk}
+}
+
+func (s *SQL) Add(ctx context.Context, key string) (bool, error) {
+	now := s.clock.Now().UTC()
+	// Expired key is taken as a new one.
+	res, err := s.db.ExecContext(
+		ctx,
+		"INSERT INTO notification_keys(key, added_at) VALUES($1, $2) "+
+			"ON CONFLICT (key) DO UPDATE SET added_at = EXCLUDED.added_at WHERE notification_keys.added_at <= $3",
+		key,
+		now,
+		now.Add(-s.getTTL()),
+	)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
+}
+
+func (s *SQL) Forget(ctx context.Context, key string) error {
+	_, err := s.db.ExecContext(ctx, "DELETE FROM notification_keys WHERE key = $1", key)
+	return err
+}
+
+func (s *SQL) Purge(ctx context.Context) error {
+	_, err := s.db.ExecContext(
+		ctx,
+		"DELETE FROM notification_keys WHERE added_at <= $1",
+		s.clock.Now().UTC().Add(-s.getTTL()),
+	)
+	return err
+}
+
+// SetLimits changes TTL only, capacity of database table is not limited.
+func (s *SQL) SetLimits(_ int, ttl time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ttl = ttl
+}
+
+func (s *SQL) getTTL() time.Duration {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.ttl
+}
+
+func (s *SQL) Close(_ context.Context) error {
+	if err := s.db.Close(); err != nil {
+		return fmt.Errorf("failed to close connection: %w", err)
+	}
+	return nil
+}
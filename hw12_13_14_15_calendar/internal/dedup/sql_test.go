@@ -0,0 +1,52 @@
+//go:build sql
+// +build sql
+
+package dedup_test
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/clock"
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/dedup"
+	sqlstorage "github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/storage/sql"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQL(t *testing.T) {
+	config := dedup.Config{
+		StorageType: "sql",
+		Database: sqlstorage.Config{
+			Host: "127.0.0.1", Port: 5432, Database: "testing", Username: "postgres", Password: "pas",
+		},
+		Capacity: 10,
+		TTL:      time.Hour,
+	}
+	if host := os.Getenv("TEST_POSTGRES_HOST"); host != "" {
+		config.Database.Host = host
+	}
+	if port := os.Getenv("TEST_POSTGRES_PORT"); port != "" {
+		var err error
+		config.Database.Port, err = strconv.Atoi(port)
+		require.NoError(t, err)
+	}
+
+	// Instances share keys through database.
+	first, err := dedup.New(context.Background(), config, clock.Real)
+	require.NoError(t, err)
+	defer first.Close(context.Background())
+	second, err := dedup.New(context.Background(), config, clock.Real)
+	require.NoError(t, err)
+	defer second.Close(context.Background())
+
+	key := "event@" + time.Now().Format(time.RFC3339Nano)
+	requireAdded(t, first, key, true)
+	requireAdded(t, second, key, false)
+	require.NoError(t, first.Purge(context.Background()))
+	requireAdded(t, first, key, false)
+	require.NoError(t, first.Forget(context.Background(), key))
+	requireAdded(t, second, key, true)
+}
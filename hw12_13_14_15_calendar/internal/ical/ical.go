@@ -0,0 +1,116 @@
+// Package ical encodes events to iCalendar format (RFC 5545).
+package ical
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/storage"
+)
+
+const (
+	productID = "-//otus-golang//calendar//EN"
+	// Added to event IDs to make UIDs globally unique.
+	uidDomain = "calendar"
+	// Max length of content line in octets, longer lines are folded.
+	maxLineLength = 75
+
+	dateTimeFormat = "20060102T150405Z"
+	dateFormat     = "20060102"
+)
+
+var textEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`)
+
+// Marshal returns calendar with the events in order they are given.
+// Events in trash are marked cancelled, reminder days become display alarms.
+func Marshal(name string, events []storage.Event) []byte {
+	var b builder
+	b.line("BEGIN", "VCALENDAR")
+	b.line("VERSION", "2.0")
+	b.line("PRODID", productID)
+	b.line("CALSCALE", "GREGORIAN")
+	b.line("METHOD", "PUBLISH")
+	b.line("X-WR-CALNAME", escape(name))
+	for _, e := range events {
+		writeEvent(&b, e)
+	}
+	b.line("END", "VCALENDAR")
+	return []byte(b.String())
+}
+
+func writeEvent(b *builder, e storage.Event) {
+	b.line("BEGIN", "VEVENT")
+	b.line("UID", escape(e.ID+"@"+uidDomain))
+	b.line("DTSTAMP", e.UpdatedAt.UTC().Format(dateTimeFormat))
+	b.line("LAST-MODIFIED", e.UpdatedAt.UTC().Format(dateTimeFormat))
+	b.line("SEQUENCE", strconv.FormatInt(e.Version, 10))
+	if e.AllDay {
+		b.line("DTSTART;VALUE=DATE", e.StartTime.Format(dateFormat))
+		b.line("DTEND;VALUE=DATE", e.EndTime.Format(dateFormat))
+	} else {
+		b.line("DTSTART", e.StartTime.UTC().Format(dateTimeFormat))
+		b.line("DTEND", e.EndTime.UTC().Format(dateTimeFormat))
+	}
+	b.line("SUMMARY", escape(e.Title))
+	if e.Description != "" {
+		b.line("DESCRIPTION", escape(e.Description))
+	}
+	if categories := categoriesOf(e); len(categories) > 0 {
+		b.line("CATEGORIES", strings.Join(categories, ","))
+	}
+	if e.Busy() {
+		b.line("TRANSP", "OPAQUE")
+	} else {
+		b.line("TRANSP", "TRANSPARENT")
+	}
+	if e.DeletedAt != nil {
+		b.line("STATUS", "CANCELLED")
+	} else if e.NotifyBefore > 0 {
+		b.line("BEGIN", "VALARM")
+		b.line("ACTION", "DISPLAY")
+		b.line("DESCRIPTION", escape(e.Title))
+		b.line("TRIGGER", "-P"+strconv.Itoa(int(e.NotifyBefore))+"D")
+		b.line("END", "VALARM")
+	}
+	b.line("END", "VEVENT")
+}
+
+// Category and tags of the event, escaped.
+func categoriesOf(e storage.Event) []string {
+	categories := make([]string, 0, len(e.Tags)+1)
+	if e.Category != "" {
+		categories = append(categories, escape(e.Category))
+	}
+	for _, tag := range e.Tags {
+		categories = append(categories, escape(tag))
+	}
+	return categories
+}
+
+func escape(s string) string {
+	return textEscaper.Replace(s)
+}
+
+// Writes content lines ended by CRLF, long lines are folded without splitting UTF-8 characters.
+type builder struct {
+	strings.Builder
+}
+
+func (b *builder) line(name string, value string) {
+	line := name + ":" + value
+	limit := maxLineLength
+	for len(line) > limit {
+		n := limit
+		for n > 0 && !utf8.RuneStart(line[n]) {
+			n--
+		}
+		b.WriteString(line[:n])
+		b.WriteString("\r\n ")
+		line = line[n:]
+		// Leading space of continuation line counts in its length.
+		limit = maxLineLength - 1
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
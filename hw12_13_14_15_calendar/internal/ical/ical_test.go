@@ -0,0 +1,81 @@
+package ical_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/ical"
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/storage"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarshal(t *testing.T) {
+	start := time.Date(2300, 1, 2, 10, 0, 0, 0, time.FixedZone("UTC+3", 3*60*60))
+	updated := time.Date(2299, 12, 1, 8, 30, 0, 0, time.UTC)
+	deletedAt := updated.Add(time.Hour)
+	events := []storage.Event{
+		{
+			ID: "1", Title: "Planning; Q1, budget", StartTime: start, EndTime: start.Add(time.Hour),
+			Description: "Agenda:\nbudget", Category: "work", Tags: []string{"q1"}, NotifyBefore: 1,
+			Version: 2, UpdatedAt: updated,
+		},
+		{
+			ID: "2", Title: "Vacation", StartTime: time.Date(2300, 1, 3, 0, 0, 0, 0, time.UTC),
+			EndTime: time.Date(2300, 1, 5, 0, 0, 0, 0, time.UTC), AllDay: true, Transparency: storage.TransparencyFree,
+			DeletedAt: &deletedAt, Version: 3, UpdatedAt: deletedAt,
+		},
+	}
+
+	expected := "BEGIN:VCALENDAR\r\n" +
+		"VERSION:2.0\r\n" +
+		"PRODID:-//otus-golang//calendar//EN\r\n" +
+		"CALSCALE:GREGORIAN\r\n" +
+		"METHOD:PUBLISH\r\n" +
+		"X-WR-CALNAME:alice\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"UID:1@calendar\r\n" +
+		"DTSTAMP:22991201T083000Z\r\n" +
+		"LAST-MODIFIED:22991201T083000Z\r\n" +
+		"SEQUENCE:2\r\n" +
+		"DTSTART:23000102T070000Z\r\n" +
+		"DTEND:23000102T080000Z\r\n" +
+		`SUMMARY:Planning\; Q1\, budget` + "\r\n" +
+		`DESCRIPTION:Agenda:\nbudget` + "\r\n" +
+		"CATEGORIES:work,q1\r\n" +
+		"TRANSP:OPAQUE\r\n" +
+		"BEGIN:VALARM\r\n" +
+		"ACTION:DISPLAY\r\n" +
+		`DESCRIPTION:Planning\; Q1\, budget` + "\r\n" +
+		"TRIGGER:-P1D\r\n" +
+		"END:VALARM\r\n" +
+		"END:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"UID:2@calendar\r\n" +
+		"DTSTAMP:22991201T093000Z\r\n" +
+		"LAST-MODIFIED:22991201T093000Z\r\n" +
+		"SEQUENCE:3\r\n" +
+		"DTSTART;VALUE=DATE:23000103\r\n" +
+		"DTEND;VALUE=DATE:23000105\r\n" +
+		"SUMMARY:Vacation\r\n" +
+		"TRANSP:TRANSPARENT\r\n" +
+		"STATUS:CANCELLED\r\n" +
+		"END:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+	require.Equal(t, expected, string(ical.Marshal("alice", events)))
+
+	t.Run("folding", func(t *testing.T) {
+		title := strings.Repeat("й", 100)
+		data := string(ical.Marshal("", []storage.Event{{ID: "1", Title: title}}))
+		var unfolded []string
+		for _, line := range strings.Split(strings.TrimSuffix(data, "\r\n"), "\r\n") {
+			require.LessOrEqual(t, len(line), 75)
+			if strings.HasPrefix(line, " ") {
+				unfolded[len(unfolded)-1] += line[1:]
+				continue
+			}
+			unfolded = append(unfolded, line)
+		}
+		require.Contains(t, unfolded, "SUMMARY:"+title)
+	})
+}
@@ -0,0 +1,97 @@
+package leader
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/storagebuilder"
+	log "github.com/sirupsen/logrus"
+)
+
+// Elector chooses one active instance among replicas of a process.
+type Elector interface {
+	// Campaign takes leadership if it is free and checks that taken leadership is still kept.
+	Campaign(ctx context.Context) (bool, error)
+	// Close releases leadership.
+	Close(ctx context.Context) error
+}
+
+// New returns elector for the storage, instances with memory storage do not share state
+// so every instance is a leader.
+func New(ctx context.Context, config storagebuilder.Config, lockKey int64) (Elector, error) {
+	if config.StorageType != "sql" {
+		return Single{}, nil
+	}
+	db, err := sqlx.ConnectContext(ctx, "postgres", config.Database.DSN())
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database %s %d: %w", config.Database.Host, config.Database.Port, err)
+	}
+	return NewPgElector(db, lockKey), nil
+}
+
+// Single is an elector of the only instance.
+type Single struct{}
+
+func (Single) Campaign(_ context.Context) (bool, error) {
+	return true, nil
+}
+
+func (Single) Close(_ context.Context) error {
+	return nil
+}
+
+// PgElector holds postgres session-level advisory lock on dedicated connection.
+// Postgres releases the lock when connection is lost, so another instance takes leadership on its next campaign.
+type PgElector struct {
+	db   *sqlx.DB
+	key  int64
+	conn *sql.Conn
+}
+
+func NewPgElector(db *sqlx.DB, lockKey int64) *PgElector {
+	return &PgElector{db: db, key: lockKey}
+}
+
+func (e *PgElector) Campaign(ctx context.Context) (bool, error) {
+	if e.conn != nil {
+		_, err := e.conn.ExecContext(ctx, "SELECT 1")
+		if err == nil {
+			return true, nil
+		}
+		log.Warnf("leadership is lost: %v", err)
+		e.conn.Close()
+		e.conn = nil
+	}
+
+	conn, err := e.db.Conn(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to get connection: %w", err)
+	}
+	var locked bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", e.key).Scan(&locked); err != nil {
+		conn.Close()
+		return false, fmt.Errorf("failed to take lock: %w", err)
+	}
+	if !locked {
+		conn.Close()
+		return false, nil
+	}
+	e.conn = conn
+	return true, nil
+}
+
+func (e *PgElector) Close(ctx context.Context) error {
+	if e.conn != nil {
+		if _, err := e.conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", e.key); err != nil {
+			log.Warnf("failed to release lock: %v", err)
+		}
+		e.conn.Close()
+		e.conn = nil
+	}
+	if err := e.db.Close(); err != nil {
+		return fmt.Errorf("failed to close connection: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+//go:build sql
+// +build sql
+
+package leader_test
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/leader"
+	sqlstorage "github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/storage/sql"
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/storagebuilder"
+	"github.com/stretchr/testify/require"
+)
+
+const lockKey = 424242
+
+func TestPgElector(t *testing.T) {
+	ctx := context.Background()
+	first := createElector(t)
+	second := createElector(t)
+
+	ok, err := first.Campaign(ctx)
+	require.NoError(t, err)
+	require.True(t, ok)
+	ok, err = second.Campaign(ctx)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	ok, err = first.Campaign(ctx)
+	require.NoError(t, err)
+	require.True(t, ok, "leadership should be kept")
+
+	require.NoError(t, first.Close(ctx))
+	ok, err = second.Campaign(ctx)
+	require.NoError(t, err)
+	require.True(t, ok, "leadership should be taken after leader is closed")
+}
+
+func TestSingleElector(t *testing.T) {
+	elector, err := leader.New(context.Background(), storagebuilder.Config{StorageType: "memory"}, lockKey)
+	require.NoError(t, err)
+	ok, err := elector.Campaign(context.Background())
+	require.NoError(t, err)
+	require.True(t, ok)
+}
+
+func createElector(t *testing.T) leader.Elector {
+	t.Helper()
+	config := sqlstorage.Config{Host: "127.0.0.1", Port: 5432, Database: "testing", Username: "postgres", Password: "pas"}
+	if host := os.Getenv("TEST_POSTGRES_HOST"); host != "" {
+		config.Host = host
+	}
+	if port := os.Getenv("TEST_POSTGRES_PORT"); port != "" {
+		var err error
+		config.Port, err = strconv.Atoi(port)
+		require.NoError(t, err)
+	}
+
+	elector, err := leader.New(context.Background(), storagebuilder.Config{StorageType: "sql", Database: config}, lockKey)
+	require.NoError(t, err)
+	t.Cleanup(func() { elector.Close(context.Background()) })
+	return elector
+}
@@ -3,6 +3,7 @@ package logger
 import (
 	"fmt"
 
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/config"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -10,6 +11,12 @@ type Config struct {
 	Level string
 }
 
+func (c Config) Validate(p *config.Problems, prefix string) {
+	if _, err := log.ParseLevel(c.Level); err != nil {
+		p.Add(prefix+".level", "unknown level %q", c.Level)
+	}
+}
+
 func PrepareLogger(config Config) error {
 	level, err := log.ParseLevel(config.Level)
 	if err != nil {
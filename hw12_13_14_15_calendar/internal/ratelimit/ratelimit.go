@@ -0,0 +1,94 @@
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/clock"
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/config"
+)
+
+// Buckets are dropped when there are more keys, only buckets refilled up to burst are dropped.
+const maxBuckets = 10000
+
+// Config limits requests of one key, zero rate disables the limit.
+type Config struct {
+	// Requests per second.
+	Rate float64
+	// Requests allowed at once after idle time.
+	Burst int
+}
+
+func (c Config) Validate(p *config.Problems, prefix string) {
+	if c.Rate < 0 {
+		p.Add(prefix+".rate", "should not be negative, got %g", c.Rate)
+	}
+	if c.Rate > 0 && c.Burst <= 0 {
+		p.Add(prefix+".burst", "should be positive, got %d", c.Burst)
+	}
+}
+
+// Limiter keeps token bucket per key, its config can be changed at runtime.
+type Limiter struct {
+	mu      sync.Mutex
+	config  Config
+	buckets map[string]*bucket
+	clock   clock.Clock
+}
+
+type bucket struct {
+	tokens    float64
+	updatedAt time.Time
+}
+
+func New(config Config, clk clock.Clock) *Limiter {
+	return &Limiter{config: config, buckets: make(map[string]*bucket), clock: clk}
+}
+
+// Allow takes a token of the key, false is returned if the key has no tokens.
+func (l *Limiter) Allow(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.config.Rate == 0 {
+		return true
+	}
+	now := l.clock.Now()
+	b, ok := l.buckets[key]
+	if !ok {
+		if len(l.buckets) >= maxBuckets {
+			l.dropFull(now)
+		}
+		b = &bucket{tokens: float64(l.config.Burst), updatedAt: now}
+		l.buckets[key] = b
+	}
+	l.refill(b, now)
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// SetConfig applies new limits, all keys start with full buckets.
+func (l *Limiter) SetConfig(c Config) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.config = c
+	l.buckets = make(map[string]*bucket)
+}
+
+// Must be called under lock.
+func (l *Limiter) refill(b *bucket, now time.Time) {
+	b.tokens = math.Min(float64(l.config.Burst), b.tokens+now.Sub(b.updatedAt).Seconds()*l.config.Rate)
+	b.updatedAt = now
+}
+
+// Full buckets are the same as new ones, must be called under lock.
+func (l *Limiter) dropFull(now time.Time) {
+	for key, b := range l.buckets {
+		if l.refill(b, now); b.tokens >= float64(l.config.Burst) {
+			delete(l.buckets, key)
+		}
+	}
+}
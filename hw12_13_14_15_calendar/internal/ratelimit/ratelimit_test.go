@@ -0,0 +1,43 @@
+package ratelimit_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/clock"
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/ratelimit"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimiter(t *testing.T) {
+	t.Run("burst and refill", func(t *testing.T) {
+		clk := clock.NewFake(time.Now())
+		l := ratelimit.New(ratelimit.Config{Rate: 2, Burst: 3}, clk)
+		for i := 0; i < 3; i++ {
+			require.True(t, l.Allow("alice"))
+		}
+		require.False(t, l.Allow("alice"))
+		require.True(t, l.Allow("bob"), "keys are limited separately")
+
+		clk.Advance(500 * time.Millisecond)
+		require.True(t, l.Allow("alice"))
+		require.False(t, l.Allow("alice"))
+		clk.Advance(time.Hour)
+		for i := 0; i < 3; i++ {
+			require.True(t, l.Allow("alice"), "bucket is not refilled over burst")
+		}
+		require.False(t, l.Allow("alice"))
+	})
+
+	t.Run("set config", func(t *testing.T) {
+		l := ratelimit.New(ratelimit.Config{}, clock.NewFake(time.Now()))
+		for i := 0; i < 100; i++ {
+			require.True(t, l.Allow("alice"), "zero rate does not limit")
+		}
+		l.SetConfig(ratelimit.Config{Rate: 1, Burst: 1})
+		require.True(t, l.Allow("alice"))
+		require.False(t, l.Allow("alice"))
+		l.SetConfig(ratelimit.Config{})
+		require.True(t, l.Allow("alice"))
+	})
+}
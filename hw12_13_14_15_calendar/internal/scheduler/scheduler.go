@@ -0,0 +1,139 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/broker"
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/clock"
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/config"
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/leader"
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/storage"
+	log "github.com/sirupsen/logrus"
+)
+
+// Publisher sends notification messages to the queue.
+type Publisher interface {
+	Publish(ctx context.Context, body []byte) error
+}
+
+type Config struct {
+	// How often events are checked for notifications.
+	CheckInterval time.Duration
+	// How often trash is purged.
+	PurgeInterval time.Duration
+	// How long removed events are kept in trash before purging.
+	TrashRetention time.Duration
+	// Key of postgres advisory lock held by active scheduler, other instances are on standby.
+	LeaderLockKey int64
+}
+
+func (c Config) Validate(p *config.Problems, prefix string) {
+	p.Positive(prefix+".checkInterval", c.CheckInterval)
+	p.Positive(prefix+".purgeInterval", c.PurgeInterval)
+	if c.TrashRetention < 0 {
+		p.Add(prefix+".trashRetention", "should not be negative, got %s", c.TrashRetention)
+	}
+}
+
+// Scheduler publishes due reminders and purges trash while its instance is a leader.
+type Scheduler struct {
+	storage   storage.Storage
+	publisher Publisher
+	elector   leader.Elector
+	config    Config
+	clock     clock.Clock
+}
+
+func New(
+	stor storage.Storage,
+	publisher Publisher,
+	elector leader.Elector,
+	config Config,
+	clk clock.Clock,
+) *Scheduler {
+	return &Scheduler{storage: stor, publisher: publisher, elector: elector, config: config, clock: clk}
+}
+
+// Run works until ctx is done, intervals of configs received from reload are applied immediately.
+func (s *Scheduler) Run(ctx context.Context, reload <-chan Config) {
+	endTime := s.clock.Now()
+	startTime := endTime.Add(-s.config.CheckInterval)
+	checkTicker := s.clock.NewTicker(s.config.CheckInterval)
+	defer checkTicker.Stop()
+	purgeTicker := s.clock.NewTicker(s.config.PurgeInterval)
+	defer purgeTicker.Stop()
+	leading := s.campaign(ctx, false)
+	if leading {
+		s.publishReminders(ctx, startTime, endTime)
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case s.config = <-reload:
+			checkTicker.Reset(s.config.CheckInterval)
+			purgeTicker.Reset(s.config.PurgeInterval)
+		case now := <-checkTicker.C():
+			log.Debug("ticker")
+			// Standby instance moves notification window too, so it continues from the last check on failover.
+			startTime = endTime
+			endTime = now
+			leading = s.campaign(ctx, leading)
+			if leading {
+				s.publishReminders(ctx, startTime, endTime)
+			}
+		case now := <-purgeTicker.C():
+			if leading = s.campaign(ctx, leading); !leading {
+				continue
+			}
+			if err := s.storage.PurgeDeleted(ctx, now.Add(-s.config.TrashRetention)); err != nil {
+				log.Errorf("failed to purge deleted events: %s", err)
+			}
+		}
+	}
+}
+
+// Returns true if the instance is a leader, leading is a previous state to log changes.
+func (s *Scheduler) campaign(ctx context.Context, leading bool) bool {
+	ok, err := s.elector.Campaign(ctx)
+	if err != nil {
+		log.Errorf("failed to check leadership: %s", err)
+	}
+	if ok != leading {
+		if ok {
+			log.Info("scheduler is active")
+		} else {
+			log.Info("scheduler is on standby")
+		}
+	}
+	return ok
+}
+
+func (s *Scheduler) publishReminders(ctx context.Context, startTime, endTime time.Time) {
+	log.Debugf("get events: %s - %s", startTime, endTime)
+	reminders, err := s.storage.GetReminders(ctx, startTime, endTime)
+	if err != nil {
+		log.Errorf("failed to get reminders: %s", err)
+		return
+	}
+	for _, reminder := range reminders {
+		log.Debugf("send reminder: %v", reminder)
+		data, _ := json.Marshal(newMessage(reminder))
+		if err := s.publisher.Publish(ctx, data); err != nil {
+			log.Errorf("failed to publish reminder of event %q: %s", reminder.Event.ID, err)
+		}
+	}
+}
+
+func newMessage(reminder storage.Reminder) broker.Message {
+	return broker.Message{
+		ID:       reminder.Event.ID,
+		Name:     reminder.Event.Title,
+		Time:     reminder.Event.StartTime,
+		OwnerID:  reminder.Event.OwnerID,
+		RemindAt: reminder.RemindAt,
+		Key:      broker.MessageKey(reminder.Event.ID, reminder.RemindAt),
+	}
+}
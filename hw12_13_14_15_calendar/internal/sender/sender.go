@@ -0,0 +1,146 @@
+package sender
+
+import (
+	"context"
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"time"
+
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/broker"
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/clock"
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/dedup"
+	log "github.com/sirupsen/logrus"
+)
+
+// Failed notification is sent again after the delay, so unavailable notifier is not called in a loop.
+const retryDelay = 5 * time.Second
+
+var (
+	notificationsSent    = expvar.NewInt("notifications_sent")
+	duplicatesSuppressed = expvar.NewInt("notifications_duplicates_suppressed")
+)
+
+// Consumer delivers messages from the queue until ctx is done.
+type Consumer interface {
+	Consume(ctx context.Context, handler broker.Handler) error
+}
+
+// Notifier delivers notification to the user.
+type Notifier interface {
+	Notify(ctx context.Context, m broker.Message) error
+}
+
+// LogNotifier writes notifications to the log.
+type LogNotifier struct{}
+
+func (LogNotifier) Notify(_ context.Context, m broker.Message) error {
+	log.Printf("sending message %v", m)
+	return nil
+}
+
+// Sender sends notifications from the queue, every reminder is sent once.
+type Sender struct {
+	store         dedup.Store
+	notifier      Notifier
+	purgeInterval time.Duration
+	clock         clock.Clock
+}
+
+func New(store dedup.Store, notifier Notifier, purgeInterval time.Duration, clk clock.Clock) *Sender {
+	return &Sender{store: store, notifier: notifier, purgeInterval: purgeInterval, clock: clk}
+}
+
+// Run consumes messages until ctx is done, incorrect messages are dropped
+// and messages failed to be sent are requeued after retryDelay.
+// Limits of deduplication from reload are applied to the store, keys are purged with TTL interval then.
+func (s *Sender) Run(ctx context.Context, consumer Consumer, reload <-chan dedup.Config) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go s.purgeKeys(ctx, reload)
+
+	err := consumer.Consume(ctx, func(ctx context.Context, d broker.Delivery) {
+		requeue, err := s.process(ctx, d.Body)
+		if err != nil {
+			if requeue {
+				log.Errorf("requeue message: %s", err)
+				s.wait(ctx, retryDelay)
+			} else {
+				log.Errorf("drop message: %s", err)
+			}
+			if err := d.Nack(requeue); err != nil {
+				log.Errorf("failed to reject message: %s", err)
+			}
+			return
+		}
+		if err := d.Ack(); err != nil {
+			log.Errorf("failed to acknowledge message: %s", err)
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("failed to consume messages: %w", err)
+	}
+	return nil
+}
+
+// Returns true with error if the message should be delivered again.
+func (s *Sender) process(ctx context.Context, body []byte) (bool, error) {
+	m := broker.Message{}
+	if err := json.Unmarshal(body, &m); err != nil {
+		return false, fmt.Errorf("failed to parse message: %w", err)
+	}
+	if m.Key != "" {
+		added, err := s.store.Add(ctx, m.Key)
+		if err != nil {
+			// Duplicate is better than lost notification.
+			log.Errorf("failed to check notification key %q: %s", m.Key, err)
+		} else if !added {
+			log.Debugf("skip duplicate message %v", m)
+			duplicatesSuppressed.Add(1)
+			return false, nil
+		}
+	}
+	if err := s.notifier.Notify(ctx, m); err != nil {
+		// Key is forgotten, so the requeued message is not taken as a duplicate.
+		if m.Key != "" {
+			if err := s.store.Forget(ctx, m.Key); err != nil {
+				log.Errorf("failed to forget notification key %q: %s", m.Key, err)
+			}
+		}
+		return true, fmt.Errorf("failed to notify about event %q: %w", m.ID, err)
+	}
+	notificationsSent.Add(1)
+	return false, nil
+}
+
+// Waits for d or until ctx is done.
+func (s *Sender) wait(ctx context.Context, d time.Duration) {
+	timer := s.clock.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+	case <-timer.C():
+	}
+}
+
+// Purges expired keys, the next purge is planned after the previous one is finished.
+func (s *Sender) purgeKeys(ctx context.Context, reload <-chan dedup.Config) {
+	timer := s.clock.NewTimer(s.purgeInterval)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case c := <-reload:
+			s.store.SetLimits(c.Capacity, c.TTL)
+			s.purgeInterval = c.TTL
+			timer.Stop()
+			timer.Reset(s.purgeInterval)
+		case <-timer.C():
+			if err := s.store.Purge(ctx); err != nil {
+				log.Errorf("failed to purge notification keys: %s", err)
+			}
+			timer.Reset(s.purgeInterval)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package sender_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"testing"
+	"time"
+
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/broker"
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/clock"
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/dedup"
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/sender"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSender(t *testing.T) {
+	queue := broker.NewMemory(10)
+	remindAt := time.Date(2300, 0o1, 0o1, 10, 0, 0, 0, time.UTC)
+	m := broker.Message{ID: "1", Name: "Meeting", RemindAt: remindAt, Key: broker.MessageKey("1", remindAt)}
+	data, err := json.Marshal(m)
+	require.NoError(t, err)
+	require.NoError(t, queue.Publish(context.Background(), data))
+	require.NoError(t, queue.Publish(context.Background(), []byte("not a message")))
+	require.NoError(t, queue.Publish(context.Background(), data))
+
+	sent := counter("notifications_sent")
+	suppressed := counter("notifications_duplicates_suppressed")
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		s := sender.New(dedup.NewMemory(10, time.Hour, clock.Real), sender.LogNotifier{}, time.Hour, clock.Real)
+		done <- s.Run(ctx, queue, nil)
+	}()
+	require.Eventually(t, func() bool {
+		return counter("notifications_duplicates_suppressed") == suppressed+1
+	}, 5*time.Second, 10*time.Millisecond)
+	cancel()
+	require.NoError(t, <-done)
+	require.Equal(t, sent+1, counter("notifications_sent"))
+}
+
+// Fails the first notification.
+type flakyNotifier struct {
+	calls chan broker.Message
+}
+
+func (n flakyNotifier) Notify(_ context.Context, m broker.Message) error {
+	n.calls <- m
+	if len(n.calls) == 1 {
+		return errors.New("notifier is unavailable")
+	}
+	return nil
+}
+
+func TestSenderRetry(t *testing.T) {
+	queue := broker.NewMemory(10)
+	remindAt := time.Date(2300, 0o1, 0o1, 10, 0, 0, 0, time.UTC)
+	data, err := json.Marshal(broker.Message{ID: "1", RemindAt: remindAt, Key: broker.MessageKey("1", remindAt)})
+	require.NoError(t, err)
+	require.NoError(t, queue.Publish(context.Background(), data))
+
+	clk := clock.NewFake(time.Now())
+	notifier := flakyNotifier{calls: make(chan broker.Message, 10)}
+	sent := counter("notifications_sent")
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		s := sender.New(dedup.NewMemory(10, time.Hour, clk), notifier, time.Hour, clk)
+		done <- s.Run(ctx, queue, nil)
+	}()
+	// Purge timer and retry timer.
+	require.Eventually(t, func() bool {
+		return clk.Timers() == 2
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, 1, len(notifier.calls), "message is not requeued before retry delay")
+	clk.Advance(5 * time.Second)
+	// Failed message is sent again, its key is not taken as a duplicate.
+	require.Eventually(t, func() bool {
+		return counter("notifications_sent") == sent+1
+	}, 5*time.Second, 10*time.Millisecond)
+	cancel()
+	require.NoError(t, <-done)
+	require.Equal(t, 2, len(notifier.calls))
+}
+
+// Counts purges of the memory store.
+type purgeCounter struct {
+	*dedup.Memory
+	purges chan struct{}
+}
+
+func (p purgeCounter) Purge(ctx context.Context) error {
+	p.purges <- struct{}{}
+	return p.Memory.Purge(ctx)
+}
+
+func TestSenderPurge(t *testing.T) {
+	clk := clock.NewFake(time.Now())
+	store := purgeCounter{Memory: dedup.NewMemory(10, time.Hour, clk), purges: make(chan struct{}, 10)}
+	reload := make(chan dedup.Config)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- sender.New(store, sender.LogNotifier{}, time.Hour, clk).Run(ctx, broker.NewMemory(10), reload)
+	}()
+	for i := 0; i < 2; i++ {
+		// Timer is created or reset before the clock is moved.
+		require.Eventually(t, func() bool {
+			return clk.Timers() == 1
+		}, 5*time.Second, 10*time.Millisecond)
+		clk.Advance(time.Hour)
+		select {
+		case <-store.purges:
+		case <-time.After(5 * time.Second):
+			require.Fail(t, "keys are not purged")
+		}
+	}
+
+	// Reloaded TTL is the new purge interval, limits are applied before the timer is reset.
+	reload <- dedup.Config{Capacity: 1, TTL: time.Minute}
+	require.Eventually(t, func() bool {
+		clk.Advance(time.Minute)
+		select {
+		case <-store.purges:
+			return true
+		default:
+			return false
+		}
+	}, 5*time.Second, 10*time.Millisecond)
+	requireAdded(t, store, "a")
+	requireAdded(t, store, "b")
+	// The oldest key is dropped with reloaded capacity.
+	requireAdded(t, store, "a")
+	cancel()
+	require.NoError(t, <-done)
+}
+
+func requireAdded(t *testing.T, store dedup.Store, key string) {
+	t.Helper()
+	added, err := store.Add(context.Background(), key)
+	require.NoError(t, err)
+	require.True(t, added, "key %q", key)
+}
+
+func counter(name string) int64 {
+	return expvar.Get(name).(*expvar.Int).Value()
+}
@@ -0,0 +1,195 @@
+package internalgrpc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/api"
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/storage"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	errAttachmentNotProvided = "attachment is not provided"
+	errAttachmentNotFound    = "attachment not found"
+	errNoAttachmentContent   = "attachment has no content"
+	errAttachmentTooLarge    = "attachment is too large"
+	errInvalidAttachment     = "invalid attachment"
+	errUnexpectedAttachment  = "attachment should be sent in the first message only"
+)
+
+const (
+	// Used if the limit is not set in config.
+	defaultMaxAttachmentSize = 10 << 20
+	// Size of content chunks sent by DownloadAttachment.
+	downloadChunkSize = 64 << 10
+)
+
+var (
+	errContentTooLarge       = errors.New(errAttachmentTooLarge)
+	errAttachmentInTheMiddle = errors.New(errUnexpectedAttachment)
+)
+
+func (s *Server) AddAttachment(
+	ctx context.Context,
+	r *api.AddAttachmentRequest,
+) (*api.AddAttachmentResponse, error) {
+	if r.GetAttachment() == nil {
+		return nil, invalidField("attachment", errAttachmentNotProvided)
+	}
+	var content io.Reader
+	if len(r.GetContent()) > 0 {
+		if int64(len(r.GetContent())) > s.maxAttachmentSize() {
+			return nil, invalidField("content", errAttachmentTooLarge)
+		}
+		content = bytes.NewReader(r.GetContent())
+	}
+
+	attachment, err := s.app.AddAttachment(ctx, toStorageAttachment(r.GetAttachment()), content)
+	if err != nil {
+		return nil, toEntityStatusError(err, "attachment", errInvalidAttachment)
+	}
+	return &api.AddAttachmentResponse{Attachment: toAPIAttachment(attachment)}, nil
+}
+
+func (s *Server) UploadAttachment(stream api.Events_UploadAttachmentServer) error {
+	r, err := stream.Recv()
+	if errors.Is(err, io.EOF) || (err == nil && r.GetAttachment() == nil) {
+		return invalidField("attachment", errAttachmentNotProvided)
+	}
+	if err != nil {
+		return err
+	}
+
+	content := &uploadReader{stream: stream, left: s.maxAttachmentSize()}
+	attachment, err := s.app.AddAttachment(stream.Context(), toStorageAttachment(r.GetAttachment()), content)
+	switch {
+	case errors.Is(err, errContentTooLarge):
+		return invalidField("chunk", errAttachmentTooLarge)
+	case errors.Is(err, errAttachmentInTheMiddle):
+		return invalidField("attachment", errUnexpectedAttachment)
+	case err != nil && stream.Context().Err() != nil:
+		return status.FromContextError(stream.Context().Err()).Err()
+	case err != nil:
+		return toEntityStatusError(err, "attachment", errInvalidAttachment)
+	}
+	return stream.SendAndClose(&api.AddAttachmentResponse{Attachment: toAPIAttachment(attachment)})
+}
+
+func (s *Server) DownloadAttachment(
+	r *api.DownloadAttachmentRequest,
+	stream api.Events_DownloadAttachmentServer,
+) error {
+	attachment, content, err := s.app.GetAttachmentContent(stream.Context(), r.GetId())
+	if err != nil {
+		return toStatusError(err)
+	}
+	defer content.Close()
+
+	err = stream.Send(&api.DownloadAttachmentResponse{
+		Data: &api.DownloadAttachmentResponse_Attachment{Attachment: toAPIAttachment(attachment)},
+	})
+	if err != nil {
+		return err
+	}
+	buf := make([]byte, downloadChunkSize)
+	for {
+		n, err := content.Read(buf)
+		if n > 0 {
+			sendErr := stream.Send(&api.DownloadAttachmentResponse{
+				Data: &api.DownloadAttachmentResponse_Chunk{Chunk: buf[:n]},
+			})
+			if sendErr != nil {
+				return sendErr
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return toStatusError(err)
+		}
+	}
+}
+
+func (s *Server) ListAttachments(
+	ctx context.Context,
+	r *api.ListAttachmentsRequest,
+) (*api.ListAttachmentsResponse, error) {
+	attachments, err := s.app.GetAttachments(ctx, r.GetEventId())
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	resp := &api.ListAttachmentsResponse{Attachments: make([]*api.Attachment, 0, len(attachments))}
+	for _, a := range attachments {
+		resp.Attachments = append(resp.Attachments, toAPIAttachment(a))
+	}
+	return resp, nil
+}
+
+func (s *Server) RemoveAttachment(ctx context.Context, r *api.RemoveAttachmentRequest) (*empty.Empty, error) {
+	if err := s.app.RemoveAttachment(ctx, r.GetId()); err != nil {
+		return nil, toStatusError(err)
+	}
+	return &empty.Empty{}, nil
+}
+
+func (s *Server) maxAttachmentSize() int64 {
+	if s.config.MaxAttachmentSize > 0 {
+		return s.config.MaxAttachmentSize
+	}
+	return defaultMaxAttachmentSize
+}
+
+// Reads content from chunks of upload stream until client closes sending,
+// fails with errContentTooLarge when content exceeds the limit.
+type uploadReader struct {
+	stream api.Events_UploadAttachmentServer
+	chunk  []byte
+	left   int64
+}
+
+func (r *uploadReader) Read(p []byte) (int, error) {
+	for len(r.chunk) == 0 {
+		req, err := r.stream.Recv()
+		if err != nil {
+			return 0, err
+		}
+		if req.GetAttachment() != nil {
+			return 0, errAttachmentInTheMiddle
+		}
+		r.chunk = req.GetChunk()
+		r.left -= int64(len(r.chunk))
+		if r.left < 0 {
+			return 0, errContentTooLarge
+		}
+	}
+	n := copy(p, r.chunk)
+	r.chunk = r.chunk[n:]
+	return n, nil
+}
+
+func toStorageAttachment(a *api.Attachment) storage.Attachment {
+	return storage.Attachment{
+		EventID:     a.GetEventId(),
+		Name:        a.GetName(),
+		ContentType: a.GetContentType(),
+		URL:         a.GetUrl(),
+	}
+}
+
+func toAPIAttachment(a storage.Attachment) *api.Attachment {
+	return &api.Attachment{
+		Id:          a.ID,
+		EventId:     a.EventID,
+		Name:        a.Name,
+		ContentType: a.ContentType,
+		Size:        a.Size,
+		Url:         a.URL,
+		CreatedAt:   timestamppb.New(a.CreatedAt),
+	}
+}
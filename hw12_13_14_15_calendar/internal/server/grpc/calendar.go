@@ -0,0 +1,157 @@
+package internalgrpc
+
+import (
+	"context"
+	"time"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/api"
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/storage"
+)
+
+const (
+	errCalendarNotProvided = "calendar is not provided"
+	errCalendarNotFound    = "calendar not found"
+	errCalendarName        = "calendar name is not provided"
+	errIncorrectTimeZone   = "incorrect time zone"
+	errGrantNotProvided    = "grant is not provided"
+	errIncorrectAccess     = "incorrect access"
+	errUserNotProvided     = "user is not provided"
+	errAccessDenied        = "access denied"
+)
+
+const defaultTimeZone = "UTC"
+
+func (s *Server) CreateCalendar(
+	ctx context.Context,
+	r *api.CreateCalendarRequest,
+) (*api.CreateCalendarResponse, error) {
+	if r.GetCalendar() == nil {
+		return nil, invalidField("calendar", errCalendarNotProvided)
+	}
+	calendar, err := toStorageCalendar(r.GetCalendar())
+	if err != nil {
+		return nil, err
+	}
+	calendar.OwnerID, err = requireActor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	calendar, err = s.app.CreateCalendar(ctx, calendar)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return &api.CreateCalendarResponse{Calendar: toAPICalendar(calendar)}, nil
+}
+
+func (s *Server) UpdateCalendar(ctx context.Context, r *api.UpdateCalendarRequest) (*empty.Empty, error) {
+	if r.GetCalendar() == nil {
+		return nil, invalidField("calendar", errCalendarNotProvided)
+	}
+	calendar, err := toStorageCalendar(r.GetCalendar())
+	if err != nil {
+		return nil, err
+	}
+	calendar.ID = r.GetId()
+
+	if _, err = s.app.UpdateCalendar(ctx, calendar); err != nil {
+		return nil, toStatusError(err)
+	}
+	return &empty.Empty{}, nil
+}
+
+func (s *Server) RemoveCalendar(ctx context.Context, r *api.RemoveCalendarRequest) (*empty.Empty, error) {
+	if err := s.app.RemoveCalendar(ctx, r.GetId()); err != nil {
+		return nil, toStatusError(err)
+	}
+	return &empty.Empty{}, nil
+}
+
+func (s *Server) ListCalendars(ctx context.Context, _ *empty.Empty) (*api.ListCalendarsResponse, error) {
+	calendars, err := s.app.GetCalendars(ctx)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	apiCalendars := make([]*api.Calendar, 0, len(calendars))
+	for _, c := range calendars {
+		apiCalendars = append(apiCalendars, toAPICalendar(c))
+	}
+	return &api.ListCalendarsResponse{Calendars: apiCalendars}, nil
+}
+
+func (s *Server) ShareCalendar(ctx context.Context, r *api.ShareCalendarRequest) (*empty.Empty, error) {
+	g := r.GetGrant()
+	if g == nil {
+		return nil, invalidField("grant", errGrantNotProvided)
+	}
+	if g.GetUserId() == "" {
+		return nil, invalidField("grant.userId", errUserNotProvided)
+	}
+	access := storage.Access(g.GetAccess())
+	if !access.Valid() {
+		return nil, invalidField("grant.access", errIncorrectAccess)
+	}
+
+	err := s.app.ShareCalendar(ctx, storage.Grant{CalendarID: g.GetCalendarId(), UserID: g.GetUserId(), Access: access})
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return &empty.Empty{}, nil
+}
+
+func (s *Server) RevokeCalendarAccess(
+	ctx context.Context,
+	r *api.RevokeCalendarAccessRequest,
+) (*empty.Empty, error) {
+	if r.GetUserId() == "" {
+		return nil, invalidField("userId", errUserNotProvided)
+	}
+	if err := s.app.RevokeCalendarAccess(ctx, r.GetCalendarId(), r.GetUserId()); err != nil {
+		return nil, toStatusError(err)
+	}
+	return &empty.Empty{}, nil
+}
+
+func (s *Server) ListCalendarGrants(
+	ctx context.Context,
+	r *api.ListCalendarGrantsRequest,
+) (*api.ListCalendarGrantsResponse, error) {
+	grants, err := s.app.GetCalendarGrants(ctx, r.GetCalendarId())
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	apiGrants := make([]*api.CalendarGrant, 0, len(grants))
+	for _, g := range grants {
+		apiGrants = append(apiGrants, &api.CalendarGrant{
+			CalendarId: g.CalendarID,
+			UserId:     g.UserID,
+			Access:     string(g.Access),
+		})
+	}
+	return &api.ListCalendarGrantsResponse{Grants: apiGrants}, nil
+}
+
+func toStorageCalendar(c *api.Calendar) (storage.Calendar, error) {
+	if c.GetName() == "" {
+		return storage.Calendar{}, invalidField("calendar.name", errCalendarName)
+	}
+	timeZone := c.GetTimeZone()
+	if timeZone == "" {
+		timeZone = defaultTimeZone
+	}
+	if _, err := time.LoadLocation(timeZone); err != nil {
+		return storage.Calendar{}, invalidField("calendar.timeZone", errIncorrectTimeZone)
+	}
+	return storage.Calendar{Name: c.GetName(), TimeZone: timeZone}, nil
+}
+
+func toAPICalendar(c storage.Calendar) *api.Calendar {
+	return &api.Calendar{
+		Id:       c.ID,
+		Name:     c.Name,
+		OwnerId:  c.OwnerID,
+		TimeZone: c.TimeZone,
+		Access:   string(c.Access),
+	}
+}
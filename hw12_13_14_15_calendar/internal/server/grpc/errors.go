@@ -0,0 +1,160 @@
+package internalgrpc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/api"
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/storage"
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/validator"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/genproto/googleapis/rpc/code"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// Domain of ErrorInfo details.
+const errorDomain = "calendar"
+
+type errorMapping struct {
+	err  error
+	code codes.Code
+	// Stable ErrorInfo reason for clients.
+	reason  string
+	message string
+}
+
+// Storage and app errors, the first matched one is used.
+var errorMappings = []errorMapping{
+	{storage.ErrNotFoundEvent, codes.NotFound, "EVENT_NOT_FOUND", errEventNotFound},
+	{storage.ErrVersionConflict, codes.FailedPrecondition, "VERSION_CONFLICT", errVersionConflict},
+	{storage.ErrDuplicateEventID, codes.AlreadyExists, "DUPLICATE_EVENT_ID", errDuplicateEventID},
+	{storage.ErrIncorrectEventID, codes.InvalidArgument, "INCORRECT_EVENT_ID", errIncorrectEventID},
+	{storage.ErrIncorrectEventTime, codes.InvalidArgument, "INCORRECT_EVENT_TIME", errIncorrectEventTime},
+	{storage.ErrIncorrectStartDate, codes.InvalidArgument, "INCORRECT_START_DATE", errIncorrectStartDate},
+	{storage.ErrEmptySearchQuery, codes.InvalidArgument, "EMPTY_SEARCH_QUERY", errEmptySearchQuery},
+	{storage.ErrIncorrectPeriod, codes.InvalidArgument, "INCORRECT_PERIOD", errIncorrectPeriod},
+	{storage.ErrNoReminder, codes.FailedPrecondition, "NO_REMINDER", errNoReminder},
+	{storage.ErrNotFoundCalendar, codes.NotFound, "CALENDAR_NOT_FOUND", errCalendarNotFound},
+	{storage.ErrAccessDenied, codes.PermissionDenied, "ACCESS_DENIED", errAccessDenied},
+	{storage.ErrIncorrectAccess, codes.InvalidArgument, "INCORRECT_ACCESS", errIncorrectAccess},
+	{storage.ErrBatchRolledBack, codes.Aborted, "BATCH_ROLLED_BACK", errBatchRolledBack},
+	{storage.ErrNotFoundAttachment, codes.NotFound, "ATTACHMENT_NOT_FOUND", errAttachmentNotFound},
+	{storage.ErrNoAttachmentContent, codes.FailedPrecondition, "NO_ATTACHMENT_CONTENT", errNoAttachmentContent},
+	{storage.ErrNotFoundResource, codes.NotFound, "RESOURCE_NOT_FOUND", errResourceNotFound},
+	{storage.ErrResourceBusy, codes.AlreadyExists, "RESOURCE_BUSY", errResourceBusy},
+	{storage.ErrNotFoundFeedToken, codes.NotFound, "FEED_TOKEN_NOT_FOUND", errFeedTokenNotFound},
+}
+
+// Statuses of gateway responses by ErrorInfo reason, other errors use default mapping of gRPC code.
+var httpStatuses = map[string]int{
+	// Version is checked against If-Match header or version in the request.
+	"VERSION_CONFLICT": http.StatusPreconditionFailed,
+	// Request conflicts with state of the event, not with conditional headers.
+	"NO_REMINDER":           http.StatusConflict,
+	"NO_ATTACHMENT_CONTENT": http.StatusConflict,
+}
+
+// Maps error of the app to status with ErrorInfo or BadRequest details,
+// unknown errors are logged and returned as Internal.
+func toStatusError(err error) error {
+	return toEntityStatusError(err, "event", errInvalidEvent)
+}
+
+// Same as toStatusError, fields of validation errors are prefixed with the entity name.
+func toEntityStatusError(err error, entity string, invalidMessage string) error {
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		violations := make([]*errdetails.BadRequest_FieldViolation, 0, len(validationErrors))
+		for _, e := range validationErrors {
+			violations = append(violations, &errdetails.BadRequest_FieldViolation{
+				Field:       entity + "." + e.Field,
+				Description: e.Err.Error(),
+			})
+		}
+		return withDetails(codes.InvalidArgument, invalidMessage, &errdetails.BadRequest{FieldViolations: violations})
+	}
+	for _, m := range errorMappings {
+		if errors.Is(err, m.err) {
+			return withDetails(m.code, m.message, &errdetails.ErrorInfo{Reason: m.reason, Domain: errorDomain})
+		}
+	}
+	log.Errorf("failed to process request: %v", err)
+	return status.Errorf(codes.Internal, errInternalServerError)
+}
+
+// Returns InvalidArgument status with BadRequest details for the request field.
+func invalidField(field string, message string) error {
+	return withDetails(codes.InvalidArgument, message, &errdetails.BadRequest{
+		FieldViolations: []*errdetails.BadRequest_FieldViolation{{Field: field, Description: message}},
+	})
+}
+
+func withDetails(code codes.Code, message string, details proto.Message) error {
+	st, err := status.New(code, message).WithDetails(details)
+	if err != nil {
+		log.Errorf("failed to add error details: %v", err)
+		return status.Errorf(code, message)
+	}
+	return st.Err()
+}
+
+func toErrorResponse(st *status.Status) *api.ErrorResponse {
+	resp := &api.ErrorResponse{Code: code.Code(st.Code()).String(), Message: st.Message()}
+	for _, d := range st.Details() {
+		switch d := d.(type) {
+		case *errdetails.ErrorInfo:
+			resp.Reason = d.GetReason()
+		case *errdetails.BadRequest:
+			for _, v := range d.GetFieldViolations() {
+				resp.FieldViolations = append(resp.FieldViolations, &api.FieldViolation{
+					Field:       v.GetField(),
+					Description: v.GetDescription(),
+				})
+			}
+		}
+	}
+	return resp
+}
+
+// Writes api.ErrorResponse with status from httpStatuses if the error reason is there.
+func httpErrorHandler(
+	_ context.Context,
+	_ *runtime.ServeMux,
+	_ runtime.Marshaler,
+	w http.ResponseWriter,
+	_ *http.Request,
+	err error,
+) {
+	httpStatus := 0
+	var httpErr *runtime.HTTPStatusError
+	if errors.As(err, &httpErr) {
+		httpStatus = httpErr.HTTPStatus
+		err = httpErr.Err
+	}
+	st := status.Convert(err)
+	resp := toErrorResponse(st)
+	if httpStatus == 0 {
+		httpStatus = httpStatuses[resp.GetReason()]
+	}
+	if httpStatus == 0 {
+		httpStatus = runtime.HTTPStatusFromCode(st.Code())
+	}
+
+	data, err := protojson.Marshal(resp)
+	if err != nil {
+		log.Errorf("failed to marshal error body: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(httpStatus)
+	if _, err := w.Write(data); err != nil {
+		log.Debugf("failed to write error body: %v", err)
+	}
+}
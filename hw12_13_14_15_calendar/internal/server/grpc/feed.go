@@ -0,0 +1,36 @@
+package internalgrpc
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/api"
+)
+
+const errFeedTokenNotFound = "feed token not found"
+
+// Same as path of feed handler of HTTP server.
+const feedPathPrefix = "/feeds/"
+
+func (s *Server) RotateFeedToken(ctx context.Context, _ *empty.Empty) (*api.RotateFeedTokenResponse, error) {
+	userID, err := requireActor(ctx)
+	if err != nil {
+		return nil, err
+	}
+	token, err := s.app.RotateFeedToken(ctx, userID)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return &api.RotateFeedTokenResponse{Token: token, Path: feedPathPrefix + token}, nil
+}
+
+func (s *Server) RevokeFeedToken(ctx context.Context, _ *empty.Empty) (*empty.Empty, error) {
+	userID, err := requireActor(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.app.RevokeFeedToken(ctx, userID); err != nil {
+		return nil, toStatusError(err)
+	}
+	return &empty.Empty{}, nil
+}
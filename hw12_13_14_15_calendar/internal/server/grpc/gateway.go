@@ -0,0 +1,62 @@
+package internalgrpc
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	etagHeader    = "etag"
+	ifMatchHeader = "if-match"
+	userIDHeader  = "x-user-id"
+)
+
+// Gateway passes If-Match as permanent HTTP header with own prefix, gRPC clients set it as is.
+var ifMatchKeys = []string{ifMatchHeader, runtime.MetadataPrefix + ifMatchHeader}
+
+func incomingHeaderMatcher(key string) (string, bool) {
+	if strings.EqualFold(key, userIDHeader) {
+		return userIDHeader, true
+	}
+	return runtime.DefaultHeaderMatcher(key)
+}
+
+func outgoingHeaderMatcher(key string) (string, bool) {
+	if key == etagHeader {
+		return "ETag", true
+	}
+	return runtime.MetadataHeaderPrefix + key, true
+}
+
+func setETag(ctx context.Context, version int64) {
+	err := grpc.SetHeader(ctx, metadata.Pairs(etagHeader, strconv.Quote(strconv.FormatInt(version, 10))))
+	if err != nil {
+		log.Debugf("failed to set etag: %v", err)
+	}
+}
+
+// Returns expected event version from If-Match header, zero if header is not set or "*".
+func versionFromContext(ctx context.Context) (int64, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return 0, nil
+	}
+	for _, key := range ifMatchKeys {
+		values := md.Get(key)
+		if len(values) == 0 {
+			continue
+		}
+		etag := strings.TrimPrefix(strings.TrimSpace(values[0]), "W/")
+		if etag == "*" {
+			return 0, nil
+		}
+		return strconv.ParseInt(strings.Trim(etag, `"`), 10, 64)
+	}
+	return 0, nil
+}
@@ -2,10 +2,14 @@ package internalgrpc
 
 import (
 	"context"
+	"strings"
 	"time"
 
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/storage"
 	log "github.com/sirupsen/logrus"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/peer"
 )
@@ -18,8 +22,25 @@ func loggingHandler(
 ) (interface{}, error) {
 	start := time.Now()
 	resp, err := handler(ctx, req)
+	logRequest(ctx, info.FullMethod, start, err)
+	return resp, err
+}
+
+func streamLoggingHandler(
+	srv interface{},
+	ss grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+	start := time.Now()
+	err := handler(srv, ss)
+	logRequest(ss.Context(), info.FullMethod, start, err)
+	return err
+}
+
+func logRequest(ctx context.Context, method string, start time.Time, err error) {
 	if err != nil {
-		log.Printf("method %q failed: %s", info.FullMethod, err)
+		log.Printf("method %q failed: %s", method, err)
 	}
 	ip := ""
 	if peer, ok := peer.FromContext(ctx); ok {
@@ -30,9 +51,93 @@ func loggingHandler(
 		userAgent = md.Get("user-agent")
 	}
 	log.WithField("ip", ip).
-		WithField("method", info.FullMethod).
+		WithField("method", method).
 		WithField("user-agent", userAgent).
 		WithField("latency", time.Since(start)).
 		Info("GRPC request processed")
-	return resp, err
+}
+
+// Puts ID of user performing the request into context.
+func actorHandler(
+	ctx context.Context,
+	req interface{},
+	_ *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (interface{}, error) {
+	return handler(withActor(ctx), req)
+}
+
+func streamActorHandler(
+	srv interface{},
+	ss grpc.ServerStream,
+	_ *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+	return handler(srv, &actorStream{ServerStream: ss, ctx: withActor(ss.Context())})
+}
+
+func withActor(ctx context.Context) context.Context {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(userIDHeader); len(values) > 0 {
+			return storage.ContextWithActor(ctx, values[0])
+		}
+	}
+	return ctx
+}
+
+// Returns user from context or Unauthenticated status error if user is not provided.
+func requireActor(ctx context.Context) (string, error) {
+	actorID := storage.ActorFromContext(ctx)
+	if actorID == "" {
+		return "", withDetails(codes.Unauthenticated, errUserNotProvided, &errdetails.ErrorInfo{
+			Reason: "USER_NOT_PROVIDED",
+			Domain: errorDomain,
+		})
+	}
+	return actorID, nil
+}
+
+// Rejects requests of actor exceeding the rate limit, health checks are not limited.
+func (s *Server) rateLimitHandler(
+	ctx context.Context,
+	req interface{},
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (interface{}, error) {
+	if err := s.checkRateLimit(ctx, info.FullMethod); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (s *Server) streamRateLimitHandler(
+	srv interface{},
+	ss grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+	if err := s.checkRateLimit(ss.Context(), info.FullMethod); err != nil {
+		return err
+	}
+	return handler(srv, ss)
+}
+
+func (s *Server) checkRateLimit(ctx context.Context, method string) error {
+	if strings.HasPrefix(method, "/grpc.health.v1.Health/") || s.limiter.Allow(storage.ActorFromContext(ctx)) {
+		return nil
+	}
+	return withDetails(codes.ResourceExhausted, errTooManyRequests, &errdetails.ErrorInfo{
+		Reason: "RATE_LIMITED",
+		Domain: errorDomain,
+	})
+}
+
+// Server stream with context holding the actor.
+type actorStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *actorStream) Context() context.Context {
+	return s.ctx
 }
@@ -0,0 +1,46 @@
+package internalgrpc
+
+import (
+	"context"
+	"time"
+
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/api"
+	log "github.com/sirupsen/logrus"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// Used if the interval is not set in config.
+const defaultHealthCheckInterval = 10 * time.Second
+
+// Updates health status of the server and Events service by storage connectivity until the context is done.
+func (s *Server) watchStorage(ctx context.Context) {
+	interval := s.config.HealthCheckInterval
+	if interval <= 0 {
+		interval = defaultHealthCheckInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		s.setHealthStatus(s.checkStorage(ctx, interval))
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Server) checkStorage(ctx context.Context, timeout time.Duration) healthpb.HealthCheckResponse_ServingStatus {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	if err := s.app.Ping(ctx); err != nil {
+		log.Warnf("storage is not available: %v", err)
+		return healthpb.HealthCheckResponse_NOT_SERVING
+	}
+	return healthpb.HealthCheckResponse_SERVING
+}
+
+func (s *Server) setHealthStatus(status healthpb.HealthCheckResponse_ServingStatus) {
+	s.healthServer.SetServingStatus("", status)
+	s.healthServer.SetServingStatus(api.Events_ServiceDesc.ServiceName, status)
+}
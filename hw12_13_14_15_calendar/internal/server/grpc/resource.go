@@ -0,0 +1,91 @@
+package internalgrpc
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/api"
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/storage"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	errResourceNotProvided   = "resource is not provided"
+	errResourceNotFound      = "resource not found"
+	errResourceBusy          = "resource is booked by another event"
+	errIncorrectResourceType = "incorrect resource type"
+	errInvalidResource       = "invalid resource"
+)
+
+func (s *Server) CreateResource(
+	ctx context.Context,
+	r *api.CreateResourceRequest,
+) (*api.CreateResourceResponse, error) {
+	if r.GetResource() == nil {
+		return nil, invalidField("resource", errResourceNotProvided)
+	}
+	resource, err := s.app.CreateResource(ctx, storage.Resource{
+		Name:     r.GetResource().GetName(),
+		Type:     storage.ResourceType(r.GetResource().GetType()),
+		Capacity: r.GetResource().GetCapacity(),
+	})
+	if err != nil {
+		return nil, toEntityStatusError(err, "resource", errInvalidResource)
+	}
+	return &api.CreateResourceResponse{Resource: toAPIResource(resource)}, nil
+}
+
+func (s *Server) RemoveResource(ctx context.Context, r *api.RemoveResourceRequest) (*empty.Empty, error) {
+	if err := s.app.RemoveResource(ctx, r.GetId()); err != nil {
+		return nil, toStatusError(err)
+	}
+	return &empty.Empty{}, nil
+}
+
+func (s *Server) ListResources(
+	ctx context.Context,
+	r *api.ListResourcesRequest,
+) (*api.ListResourcesResponse, error) {
+	filter := storage.ResourceFilter{Type: storage.ResourceType(r.GetType()), MinCapacity: r.GetMinCapacity()}
+	if filter.Type != "" && filter.Type != storage.ResourceRoom && filter.Type != storage.ResourceEquipment {
+		return nil, invalidField("type", errIncorrectResourceType)
+	}
+	resources, err := s.app.GetResources(ctx, filter)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	resp := &api.ListResourcesResponse{Resources: make([]*api.Resource, 0, len(resources))}
+	for _, res := range resources {
+		resp.Resources = append(resp.Resources, toAPIResource(res))
+	}
+	return resp, nil
+}
+
+func (s *Server) GetResourceSchedule(
+	ctx context.Context,
+	r *api.GetResourceScheduleRequest,
+) (*api.GetResourceScheduleResponse, error) {
+	if !r.GetFrom().IsValid() {
+		return nil, invalidField("from", errIncorrectPeriod)
+	}
+	if !r.GetTo().IsValid() {
+		return nil, invalidField("to", errIncorrectPeriod)
+	}
+	bookings, err := s.app.GetResourceSchedule(ctx, r.GetId(), r.GetFrom().AsTime(), r.GetTo().AsTime())
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	resp := &api.GetResourceScheduleResponse{Bookings: make([]*api.Booking, 0, len(bookings))}
+	for _, b := range bookings {
+		resp.Bookings = append(resp.Bookings, &api.Booking{
+			EventId: b.EventID,
+			Start:   timestamppb.New(b.Start),
+			End:     timestamppb.New(b.End),
+		})
+	}
+	return resp, nil
+}
+
+func toAPIResource(r storage.Resource) *api.Resource {
+	return &api.Resource{Id: r.ID, Name: r.Name, Type: string(r.Type), Capacity: r.Capacity}
+}
@@ -1,6 +1,7 @@
 //go:generate -command PROTOC protoc -I../../../api/proto ../../../api/proto/event.proto ../../../api/proto/service.proto
 //go:generate PROTOC --go_out=../../../api/ --go-grpc_out=../../../api/
-//go:generate PROTOC --grpc-gateway_out ../../../api/ --grpc-gateway_opt logtostderr=true --grpc-gateway_opt paths=source_relative --grpc-gateway_opt generate_unbound_methods=true
+//go:generate PROTOC --grpc-gateway_out ../../../api/ --grpc-gateway_opt logtostderr=true --grpc-gateway_opt paths=source_relative
+//go:generate PROTOC --openapiv2_out ../../../api/ --openapiv2_opt logtostderr=true,allow_merge=true,merge_file_name=calendar,disable_default_errors=true
 
 package internalgrpc
 
@@ -9,16 +10,24 @@ import (
 	"errors"
 	"net"
 	"strconv"
+	"time"
 
 	"github.com/golang/protobuf/ptypes/empty"
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
 	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/api"
 	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/app"
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/config"
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/ratelimit"
 	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/storage"
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/validator"
 	log "github.com/sirupsen/logrus"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/reflection"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
@@ -30,27 +39,100 @@ const (
 	errIncorrectEventTime  = "incorrect event time"
 	errIncorrectDate       = "incorrect date"
 	errDateIsNotProvided   = "date is not provided"
+	errVersionConflict     = "event version conflict"
+	errIncorrectVersion    = "incorrect event version"
+	errIncorrectEventID    = "incorrect event ID"
+	errDuplicateEventID    = "event with same ID exists"
+	errBatchRolledBack     = "batch is rolled back"
+	errEmptySearchQuery    = "search query has no words"
+	errOwnersNotProvided   = "owners are not provided"
+	errIncorrectPeriod     = "incorrect period"
+	errTransparency        = "incorrect transparency"
+	errNoReminder          = "event has no reminder"
+	errIncorrectSnooze     = "snooze period should be positive"
+	errIncorrectStartDate  = "date should be a first day of requested period"
+	errInvalidEvent        = "invalid event"
+	errTooManyRequests     = "too many requests"
 )
 
 type Config struct {
 	Host string
 	Port int
+	// Enables server reflection service used by grpcurl and similar tools.
+	Reflection bool
+	// How often storage connectivity is checked to update health status.
+	HealthCheckInterval time.Duration
+	// Keepalive pings are sent to idle clients after KeepaliveTime and connection is closed
+	// if ping is not acknowledged in KeepaliveTimeout, clients may not ping more often than KeepaliveMinTime.
+	// Zero values keep gRPC defaults.
+	KeepaliveTime    time.Duration
+	KeepaliveTimeout time.Duration
+	KeepaliveMinTime time.Duration
+	// Max message sizes in bytes, zero values keep gRPC defaults.
+	MaxRecvMsgSize int
+	MaxSendMsgSize int
+	// Max size of attachment content in bytes, inline content is also limited by MaxRecvMsgSize.
+	MaxAttachmentSize int64
+	// Limits requests of each user, anonymous requests share one limit. Can be changed by SetRateLimit.
+	RateLimit ratelimit.Config
+}
+
+func (c Config) Validate(p *config.Problems, prefix string) {
+	p.Port(prefix+".port", c.Port)
+	p.Positive(prefix+".healthCheckInterval", c.HealthCheckInterval)
+	durations := []struct {
+		key string
+		d   time.Duration
+	}{
+		{".keepaliveTime", c.KeepaliveTime},
+		{".keepaliveTimeout", c.KeepaliveTimeout},
+		{".keepaliveMinTime", c.KeepaliveMinTime},
+	}
+	for _, k := range durations {
+		if k.d < 0 {
+			p.Add(prefix+k.key, "should not be negative, got %s", k.d)
+		}
+	}
+	if c.MaxRecvMsgSize < 0 {
+		p.Add(prefix+".maxRecvMsgSize", "should not be negative, got %d", c.MaxRecvMsgSize)
+	}
+	if c.MaxSendMsgSize < 0 {
+		p.Add(prefix+".maxSendMsgSize", "should not be negative, got %d", c.MaxSendMsgSize)
+	}
+	if c.MaxAttachmentSize < 0 {
+		p.Add(prefix+".maxAttachmentSize", "should not be negative, got %d", c.MaxAttachmentSize)
+	}
+	c.RateLimit.Validate(p, prefix+".rateLimit")
 }
 
 type Server struct {
 	api.UnimplementedEventsServer
-	grpcServer *grpc.Server
-	app        *app.App
-	addr       string
+	grpcServer   *grpc.Server
+	healthServer *health.Server
+	stopHealth   context.CancelFunc
+	app          *app.App
+	config       Config
+	limiter      *ratelimit.Limiter
+	addr         string
 }
 
 func NewServer(config Config, app *app.App) *Server {
-	return &Server{app: app, addr: net.JoinHostPort(config.Host, strconv.Itoa(config.Port))}
+	return &Server{
+		app:          app,
+		config:       config,
+		limiter:      ratelimit.New(config.RateLimit, app.Clock),
+		healthServer: health.NewServer(),
+		addr:         net.JoinHostPort(config.Host, strconv.Itoa(config.Port)),
+	}
 }
 
-func (s *Server) Start(_ context.Context) error {
-	s.grpcServer = grpc.NewServer(grpc.UnaryInterceptor(loggingHandler))
+func (s *Server) Start(ctx context.Context) error {
+	s.grpcServer = grpc.NewServer(s.serverOptions()...)
 	api.RegisterEventsServer(s.grpcServer, s)
+	healthpb.RegisterHealthServer(s.grpcServer, s.healthServer)
+	if s.config.Reflection {
+		reflection.Register(s.grpcServer)
+	}
 
 	lsn, err := net.Listen("tcp", s.addr)
 	if err != nil {
@@ -58,13 +140,41 @@ func (s *Server) Start(_ context.Context) error {
 		return err
 	}
 
+	ctx, s.stopHealth = context.WithCancel(ctx)
+	go s.watchStorage(ctx)
+
 	log.Printf("starting grpc server on %s", s.addr)
 	err = s.grpcServer.Serve(lsn)
 	return err
 }
 
+func (s *Server) serverOptions() []grpc.ServerOption {
+	opts := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(loggingHandler, actorHandler, s.rateLimitHandler),
+		grpc.ChainStreamInterceptor(streamLoggingHandler, streamActorHandler, s.streamRateLimitHandler),
+		grpc.KeepaliveParams(keepalive.ServerParameters{
+			Time:    s.config.KeepaliveTime,
+			Timeout: s.config.KeepaliveTimeout,
+		}),
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime: s.config.KeepaliveMinTime,
+		}),
+	}
+	if s.config.MaxRecvMsgSize > 0 {
+		opts = append(opts, grpc.MaxRecvMsgSize(s.config.MaxRecvMsgSize))
+	}
+	if s.config.MaxSendMsgSize > 0 {
+		opts = append(opts, grpc.MaxSendMsgSize(s.config.MaxSendMsgSize))
+	}
+	return opts
+}
+
 func (s *Server) GatewayMux(ctx context.Context) (*runtime.ServeMux, error) {
-	mux := runtime.NewServeMux()
+	mux := runtime.NewServeMux(
+		runtime.WithErrorHandler(httpErrorHandler),
+		runtime.WithIncomingHeaderMatcher(incomingHeaderMatcher),
+		runtime.WithOutgoingHeaderMatcher(outgoingHeaderMatcher),
+	)
 	opts := []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
 	err := api.RegisterEventsHandlerFromEndpoint(ctx, mux, s.addr, opts)
 	if err != nil {
@@ -73,61 +183,246 @@ func (s *Server) GatewayMux(ctx context.Context) (*runtime.ServeMux, error) {
 	return mux, nil
 }
 
+// SetRateLimit applies new limits to running server.
+func (s *Server) SetRateLimit(c ratelimit.Config) {
+	s.limiter.SetConfig(c)
+}
+
 func (s *Server) Stop(_ context.Context) error {
+	if s.stopHealth != nil {
+		s.stopHealth()
+	}
+	s.healthServer.Shutdown()
 	s.grpcServer.GracefulStop()
 	return nil
 }
 
 func (s *Server) AddEvent(ctx context.Context, r *api.AddEventRequest) (*api.AddEventResponse, error) {
 	if r.Event == nil {
-		return nil, status.Errorf(codes.InvalidArgument, errEventNotProvided)
+		return nil, invalidField("event", errEventNotProvided)
 	}
 	event, err := toStorageEvent(r.GetEvent())
 	if err != nil {
-		if errors.Is(err, storage.ErrIncorrectEventTime) {
-			return nil, status.Errorf(codes.InvalidArgument, errIncorrectEventTime)
-		}
-		log.Errorf("failed to convert events: %v", err)
-		return nil, status.Errorf(codes.Internal, errInternalServerError)
+		return nil, err
 	}
 
-	event.ID, err = s.app.CreateEvent(ctx, event)
+	event, err = s.app.CreateEvent(ctx, event)
 	if err != nil {
-		return nil, err
+		return nil, toStatusError(err)
 	}
+	setETag(ctx, event.Version)
 	return &api.AddEventResponse{Event: toAPIEvent(event)}, nil
 }
 
 func (s *Server) UpdateEvent(ctx context.Context, r *api.UpdateEventRequest) (*empty.Empty, error) {
 	if r.GetEvent() == nil {
-		return nil, status.Errorf(codes.InvalidArgument, errEventNotProvided)
+		return nil, invalidField("event", errEventNotProvided)
 	}
 	event, err := toStorageEvent(r.GetEvent())
 	if err != nil {
-		if errors.Is(err, storage.ErrIncorrectEventTime) {
-			return nil, status.Errorf(codes.InvalidArgument, errIncorrectEventTime)
+		return nil, err
+	}
+
+	if event.Version == 0 {
+		event.Version, err = versionFromContext(ctx)
+		if err != nil {
+			return nil, invalidField("event.version", errIncorrectVersion)
 		}
-		log.Errorf("failed to convert events: %v", err)
-		return nil, status.Errorf(codes.Internal, errInternalServerError)
 	}
 
-	err = s.app.UpdateEvent(ctx, r.GetId(), event)
+	event, err = s.app.UpdateEvent(ctx, r.GetId(), event)
 	if err != nil {
-		if errors.Is(err, storage.ErrNotFoundEvent) {
-			return nil, status.Errorf(codes.NotFound, errEventNotFound)
-		}
-		return nil, status.Errorf(codes.Internal, errInternalServerError)
+		return nil, toStatusError(err)
 	}
+	setETag(ctx, event.Version)
 	return &empty.Empty{}, nil
 }
 
 func (s *Server) RemoveEvent(ctx context.Context, r *api.RemoveEventRequest) (*empty.Empty, error) {
-	err := s.app.RemoveEvent(ctx, r.GetId())
+	version := r.GetVersion()
+	if version == 0 {
+		var err error
+		version, err = versionFromContext(ctx)
+		if err != nil {
+			return nil, invalidField("version", errIncorrectVersion)
+		}
+	}
+
+	err := s.app.RemoveEvent(ctx, r.GetId(), version)
 	if err != nil {
-		if errors.Is(err, storage.ErrNotFoundEvent) {
-			return nil, status.Errorf(codes.NotFound, errEventNotFound)
+		return nil, toStatusError(err)
+	}
+	return &empty.Empty{}, nil
+}
+
+func (s *Server) RestoreEvent(ctx context.Context, r *api.RestoreEventRequest) (*empty.Empty, error) {
+	err := s.app.RestoreEvent(ctx, r.GetId())
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return &empty.Empty{}, nil
+}
+
+func (s *Server) BatchAddEvents(
+	ctx context.Context,
+	r *api.BatchAddEventsRequest,
+) (*api.BatchEventsResponse, error) {
+	results := make([]*api.BatchEventResult, len(r.GetEvents()))
+	events := make([]storage.Event, 0, len(results))
+	idx := make([]int, 0, len(results))
+	for i, e := range r.GetEvents() {
+		if e == nil {
+			results[i] = toBatchErrorResult(codes.InvalidArgument, errEventNotProvided)
+			continue
 		}
-		return nil, err
+		event, err := toStorageEvent(e)
+		if err != nil {
+			st := status.Convert(err)
+			results[i] = toBatchErrorResult(st.Code(), st.Message())
+			continue
+		}
+		events = append(events, event)
+		idx = append(idx, i)
+	}
+
+	return runBatch(results, idx, r.GetAllOrNothing(), func(opts storage.BatchOptions) ([]storage.BatchResult, error) {
+		return s.app.CreateEvents(ctx, events, opts)
+	})
+}
+
+func (s *Server) BatchUpdateEvents(
+	ctx context.Context,
+	r *api.BatchUpdateEventsRequest,
+) (*api.BatchEventsResponse, error) {
+	results := make([]*api.BatchEventResult, len(r.GetEvents()))
+	events := make([]storage.Event, 0, len(results))
+	idx := make([]int, 0, len(results))
+	for i, u := range r.GetEvents() {
+		if u.GetEvent() == nil {
+			results[i] = toBatchErrorResult(codes.InvalidArgument, errEventNotProvided)
+			continue
+		}
+		event, err := toStorageEvent(u.GetEvent())
+		if err != nil {
+			st := status.Convert(err)
+			results[i] = toBatchErrorResult(st.Code(), st.Message())
+			continue
+		}
+		event.ID = u.GetId()
+		events = append(events, event)
+		idx = append(idx, i)
+	}
+
+	return runBatch(results, idx, r.GetAllOrNothing(), func(opts storage.BatchOptions) ([]storage.BatchResult, error) {
+		return s.app.UpdateEvents(ctx, events, opts)
+	})
+}
+
+func (s *Server) BatchRemoveEvents(
+	ctx context.Context,
+	r *api.BatchRemoveEventsRequest,
+) (*api.BatchEventsResponse, error) {
+	results := make([]*api.BatchEventResult, len(r.GetEvents()))
+	refs := make([]storage.EventRef, 0, len(results))
+	idx := make([]int, 0, len(results))
+	for i, ref := range r.GetEvents() {
+		refs = append(refs, storage.EventRef{ID: ref.GetId(), Version: ref.GetVersion()})
+		idx = append(idx, i)
+	}
+
+	return runBatch(results, idx, r.GetAllOrNothing(), func(opts storage.BatchOptions) ([]storage.BatchResult, error) {
+		return s.app.RemoveEvents(ctx, refs, opts)
+	})
+}
+
+func (s *Server) ListDeletedEvents(
+	ctx context.Context,
+	r *api.ListDeletedEventsRequest,
+) (*api.GetEventsResponse, error) {
+	events, err := s.app.GetDeletedEvents(ctx, r.GetOwnerId())
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return &api.GetEventsResponse{Events: toAPIEvents(events)}, nil
+}
+
+func (s *Server) GetEventHistory(
+	ctx context.Context,
+	r *api.GetEventHistoryRequest,
+) (*api.GetEventHistoryResponse, error) {
+	changes, err := s.app.GetEventHistory(ctx, r.GetId())
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return &api.GetEventHistoryResponse{Changes: toAPIEventChanges(changes)}, nil
+}
+
+func (s *Server) SearchEvents(ctx context.Context, r *api.SearchEventsRequest) (*api.GetEventsResponse, error) {
+	from, err := toOptionalTime(r.GetFrom())
+	if err != nil {
+		return nil, invalidField("from", errIncorrectDate)
+	}
+	to, err := toOptionalTime(r.GetTo())
+	if err != nil {
+		return nil, invalidField("to", errIncorrectDate)
+	}
+
+	query := storage.SearchQuery{
+		Text:    r.GetQuery(),
+		OwnerID: r.GetOwnerId(),
+		From:    from,
+		To:      to,
+		Limit:   int(r.GetLimit()),
+	}
+	events, err := s.app.SearchEvents(ctx, query)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return &api.GetEventsResponse{Events: toAPIEvents(events)}, nil
+}
+
+func (s *Server) GetFreeBusy(ctx context.Context, r *api.GetFreeBusyRequest) (*api.GetFreeBusyResponse, error) {
+	if len(r.GetOwnerIds()) == 0 {
+		return nil, invalidField("ownerIds", errOwnersNotProvided)
+	}
+	if !r.GetFrom().IsValid() {
+		return nil, invalidField("from", errIncorrectPeriod)
+	}
+	if !r.GetTo().IsValid() {
+		return nil, invalidField("to", errIncorrectPeriod)
+	}
+
+	freeBusy, err := s.app.GetFreeBusy(ctx, r.GetOwnerIds(), r.GetFrom().AsTime(), r.GetTo().AsTime())
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	users := make([]*api.FreeBusy, 0, len(freeBusy))
+	for _, fb := range freeBusy {
+		busy := make([]*api.BusyInterval, 0, len(fb.Busy))
+		for _, in := range fb.Busy {
+			busy = append(busy, &api.BusyInterval{Start: timestamppb.New(in.Start), End: timestamppb.New(in.End)})
+		}
+		users = append(users, &api.FreeBusy{OwnerId: fb.OwnerID, Busy: busy})
+	}
+	return &api.GetFreeBusyResponse{Users: users}, nil
+}
+
+func (s *Server) AcknowledgeReminder(
+	ctx context.Context,
+	r *api.AcknowledgeReminderRequest,
+) (*empty.Empty, error) {
+	if err := s.app.AcknowledgeReminder(ctx, r.GetEventId()); err != nil {
+		return nil, toStatusError(err)
+	}
+	return &empty.Empty{}, nil
+}
+
+func (s *Server) SnoozeReminder(ctx context.Context, r *api.SnoozeReminderRequest) (*empty.Empty, error) {
+	if r.GetMinutes() <= 0 {
+		return nil, invalidField("minutes", errIncorrectSnooze)
+	}
+	if err := s.app.SnoozeReminder(ctx, r.GetEventId(), time.Duration(r.GetMinutes())*time.Minute); err != nil {
+		return nil, toStatusError(err)
 	}
 	return &empty.Empty{}, nil
 }
@@ -135,14 +430,14 @@ func (s *Server) RemoveEvent(ctx context.Context, r *api.RemoveEventRequest) (*e
 func (s *Server) GetEventsForDay(ctx context.Context, r *api.GetEventsRequest) (*api.GetEventsResponse, error) {
 	date := r.GetStartDate()
 	if date == nil {
-		return nil, status.Errorf(codes.InvalidArgument, errDateIsNotProvided)
+		return nil, invalidField("startDate", errDateIsNotProvided)
 	}
 	if !date.IsValid() {
-		return nil, status.Errorf(codes.InvalidArgument, errIncorrectDate)
+		return nil, invalidField("startDate", errIncorrectDate)
 	}
-	events, err := s.app.GetEventsForDay(ctx, date.AsTime())
+	events, err := s.app.GetEventsForDay(ctx, date.AsTime(), toEventFilter(r))
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, errInternalServerError)
+		return nil, toStatusError(err)
 	}
 
 	return &api.GetEventsResponse{Events: toAPIEvents(events)}, nil
@@ -151,17 +446,14 @@ func (s *Server) GetEventsForDay(ctx context.Context, r *api.GetEventsRequest) (
 func (s *Server) GetEventsForWeek(ctx context.Context, r *api.GetEventsRequest) (*api.GetEventsResponse, error) {
 	date := r.GetStartDate()
 	if date == nil {
-		return nil, status.Errorf(codes.InvalidArgument, errDateIsNotProvided)
+		return nil, invalidField("startDate", errDateIsNotProvided)
 	}
 	if !date.IsValid() {
-		return nil, status.Errorf(codes.InvalidArgument, errIncorrectDate)
+		return nil, invalidField("startDate", errIncorrectDate)
 	}
-	events, err := s.app.GetEventsForWeek(ctx, date.AsTime())
+	events, err := s.app.GetEventsForWeek(ctx, date.AsTime(), toEventFilter(r))
 	if err != nil {
-		if errors.Is(err, storage.ErrIncorrectStartDate) {
-			return nil, status.Errorf(codes.InvalidArgument, "%v", err)
-		}
-		return nil, status.Errorf(codes.Internal, errInternalServerError)
+		return nil, toStatusError(err)
 	}
 
 	return &api.GetEventsResponse{Events: toAPIEvents(events)}, nil
@@ -170,34 +462,92 @@ func (s *Server) GetEventsForWeek(ctx context.Context, r *api.GetEventsRequest)
 func (s *Server) GetEventsForMonth(ctx context.Context, r *api.GetEventsRequest) (*api.GetEventsResponse, error) {
 	date := r.GetStartDate()
 	if date == nil {
-		return nil, status.Errorf(codes.InvalidArgument, errDateIsNotProvided)
+		return nil, invalidField("startDate", errDateIsNotProvided)
 	}
 	if !date.IsValid() {
-		return nil, status.Errorf(codes.InvalidArgument, errIncorrectDate)
+		return nil, invalidField("startDate", errIncorrectDate)
 	}
-	events, err := s.app.GetEventsForMonth(ctx, date.AsTime())
+	events, err := s.app.GetEventsForMonth(ctx, date.AsTime(), toEventFilter(r))
 	if err != nil {
-		if errors.Is(err, storage.ErrIncorrectStartDate) {
-			return nil, status.Errorf(codes.InvalidArgument, "%v", err)
-		}
-		return nil, status.Errorf(codes.Internal, errInternalServerError)
+		return nil, toStatusError(err)
 	}
 
 	return &api.GetEventsResponse{Events: toAPIEvents(events)}, nil
 }
 
+// Runs batch for items passed request validation (idx) and puts their results to request positions.
+func runBatch(
+	results []*api.BatchEventResult,
+	idx []int,
+	allOrNothing bool,
+	run func(opts storage.BatchOptions) ([]storage.BatchResult, error),
+) (*api.BatchEventsResponse, error) {
+	if allOrNothing && len(idx) < len(results) {
+		for _, i := range idx {
+			results[i] = toBatchErrorResult(codes.Aborted, errBatchRolledBack)
+		}
+		return &api.BatchEventsResponse{Results: results}, nil
+	}
+
+	stored, err := run(storage.BatchOptions{AllOrNothing: allOrNothing})
+	if err != nil && !errors.Is(err, storage.ErrBatchRolledBack) {
+		return nil, toStatusError(err)
+	}
+	for n, i := range idx {
+		if stored[n].Err != nil {
+			results[i] = toBatchError(stored[n].Err)
+			continue
+		}
+		results[i] = &api.BatchEventResult{Event: toAPIEvent(stored[n].Event), Code: int32(codes.OK)}
+	}
+	return &api.BatchEventsResponse{Results: results}, nil
+}
+
+func toBatchError(err error) *api.BatchEventResult {
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		return toBatchErrorResult(codes.InvalidArgument, errInvalidEvent+": "+validationErrors.Error())
+	}
+	st := status.Convert(toStatusError(err))
+	return toBatchErrorResult(st.Code(), st.Message())
+}
+
+func toBatchErrorResult(code codes.Code, msg string) *api.BatchEventResult {
+	return &api.BatchEventResult{Code: int32(code), Error: msg}
+}
+
+// Returns status error for invalid event.
 func toStorageEvent(e *api.Event) (storage.Event, error) {
-	if !e.StartTime.IsValid() || !e.EndTime.IsValid() {
-		return storage.Event{}, storage.ErrIncorrectEventTime
+	if !e.StartTime.IsValid() {
+		return storage.Event{}, invalidField("event.startTime", errIncorrectEventTime)
+	}
+	if !(e.EndTime.IsValid() || e.AllDay && e.EndTime == nil) {
+		return storage.Event{}, invalidField("event.endTime", errIncorrectEventTime)
+	}
+	var endTime time.Time
+	if e.EndTime != nil {
+		endTime = e.EndTime.AsTime()
+	}
+	transparency := storage.Transparency(e.Transparency)
+	if !transparency.Valid() {
+		return storage.Event{}, invalidField("event.transparency", errTransparency)
 	}
 	return storage.Event{
 		ID:           e.Id,
 		Title:        e.Title,
 		StartTime:    e.StartTime.AsTime(),
-		EndTime:      e.EndTime.AsTime(),
+		EndTime:      endTime,
 		Description:  e.Description,
 		OwnerID:      e.OwnerId,
 		NotifyBefore: e.NotifyBefore,
+		Category:     e.Category,
+		Tags:         e.Tags,
+		Color:        e.Color,
+		AllDay:       e.AllDay,
+		Transparency: transparency,
+		CalendarID:   e.CalendarId,
+		ResourceIDs:  e.ResourceIds,
+		Version:      e.Version,
 	}, nil
 }
 
@@ -210,9 +560,41 @@ func toAPIEvent(e storage.Event) *api.Event {
 		Description:  e.Description,
 		OwnerId:      e.OwnerID,
 		NotifyBefore: e.NotifyBefore,
+		Category:     e.Category,
+		Tags:         e.Tags,
+		Color:        e.Color,
+		AllDay:       e.AllDay,
+		Transparency: string(e.Transparency),
+		CalendarId:   e.CalendarID,
+		ResourceIds:  e.ResourceIDs,
+		DeletedAt:    toAPITimestamp(e.DeletedAt),
+		Version:      e.Version,
+		UpdatedAt:    timestamppb.New(e.UpdatedAt),
 	}
 }
 
+func toAPITimestamp(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
+
+// Returns zero time if timestamp is not provided.
+func toOptionalTime(ts *timestamppb.Timestamp) (time.Time, error) {
+	if ts == nil {
+		return time.Time{}, nil
+	}
+	if err := ts.CheckValid(); err != nil {
+		return time.Time{}, err
+	}
+	return ts.AsTime(), nil
+}
+
+func toEventFilter(r *api.GetEventsRequest) storage.EventFilter {
+	return storage.EventFilter{Category: r.GetCategory(), Tags: r.GetTags()}
+}
+
 func toAPIEvents(events []storage.Event) []*api.Event {
 	apiEvents := make([]*api.Event, 0, len(events))
 	for _, event := range events {
@@ -220,3 +602,24 @@ func toAPIEvents(events []storage.Event) []*api.Event {
 	}
 	return apiEvents
 }
+
+func toAPIEventChanges(changes []storage.EventChange) []*api.EventChange {
+	apiChanges := make([]*api.EventChange, 0, len(changes))
+	for _, c := range changes {
+		apiChange := &api.EventChange{
+			Id:        c.ID,
+			EventId:   c.EventID,
+			Operation: string(c.Operation),
+			ActorId:   c.ActorID,
+			ChangedAt: timestamppb.New(c.ChangedAt),
+		}
+		if c.Before != nil {
+			apiChange.Before = toAPIEvent(*c.Before)
+		}
+		if c.After != nil {
+			apiChange.After = toAPIEvent(*c.After)
+		}
+		apiChanges = append(apiChanges, apiChange)
+	}
+	return apiChanges
+}
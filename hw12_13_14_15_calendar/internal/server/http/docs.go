@@ -0,0 +1,42 @@
+package internalhttp
+
+import (
+	"net/http"
+
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/api"
+	log "github.com/sirupsen/logrus"
+)
+
+const openAPIPath = "/openapi.json"
+
+// Swagger UI page for the OpenAPI document, UI assets are loaded from CDN.
+const swaggerUIPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>Calendar API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@4.1.3/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@4.1.3/swagger-ui-bundle.js"></script>
+  <script>
+    window.ui = SwaggerUIBundle({url: "` + openAPIPath + `", dom_id: "#swagger-ui"});
+  </script>
+</body>
+</html>
+`
+
+func serveOpenAPI(w http.ResponseWriter, _ *http.Request, _ map[string]string) {
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := w.Write(api.OpenAPI); err != nil {
+		log.Debugf("failed to write OpenAPI document: %v", err)
+	}
+}
+
+func serveSwaggerUI(w http.ResponseWriter, _ *http.Request, _ map[string]string) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if _, err := w.Write([]byte(swaggerUIPage)); err != nil {
+		log.Debugf("failed to write Swagger UI page: %v", err)
+	}
+}
@@ -0,0 +1,52 @@
+package internalhttp
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/ical"
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/storage"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	feedPathPrefix = "/feeds/"
+	feedPath       = feedPathPrefix + "{token}"
+)
+
+// Serves iCalendar feed of the token user. Conditional requests are handled by http.ServeContent
+// with ETag of the content only, feed changes without any event update when event is purged,
+// its calendar access is revoked or it leaves the feed window, so Last-Modified is not sent.
+func (s *Server) serveFeed(w http.ResponseWriter, r *http.Request, pathParams map[string]string) {
+	userID, events, err := s.app.GetFeed(r.Context(), pathParams["token"])
+	if errors.Is(err, storage.ErrNotFoundFeedToken) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		log.Errorf("failed to get feed: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	content := ical.Marshal(userID, events)
+	sum := sha256.Sum256(content)
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("ETag", `"`+hex.EncodeToString(sum[:16])+`"`)
+	// Feed is personal, it must be revalidated as events can change at any time.
+	w.Header().Set("Cache-Control", "private, no-cache")
+	http.ServeContent(w, r, "calendar.ics", time.Time{}, bytes.NewReader(content))
+}
+
+// Returns path of the request without feed token, the token must not get into logs.
+func logPath(r *http.Request) string {
+	if strings.HasPrefix(r.URL.Path, feedPathPrefix) {
+		return feedPathPrefix + "***"
+	}
+	return r.URL.String()
+}
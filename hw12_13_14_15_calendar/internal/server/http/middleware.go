@@ -15,7 +15,7 @@ func loggingMiddleware(next http.Handler) http.Handler {
 		if err != nil {
 			log.Errorf("failed to get client IP: %v", err)
 		}
-		log.WithField("ip", ip).WithField("method", r.Method).WithField("path", r.URL).
+		log.WithField("ip", ip).WithField("method", r.Method).WithField("path", logPath(r)).
 			WithField("HTTP version", r.Proto).WithField("user-agent", r.Header.Get("user-agent")).
 			WithField("latency", time.Since(start)).
 			Info("http request processed")
@@ -10,6 +10,7 @@ import (
 
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
 	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/app"
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/config"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -18,15 +19,21 @@ type Config struct {
 	Port int
 }
 
+func (c Config) Validate(p *config.Problems, prefix string) {
+	p.Port(prefix+".port", c.Port)
+}
+
 type Server struct {
 	srv  *http.Server
 	addr string
+	app  *app.App
 }
 
 func NewServer(config Config, app *app.App) *Server {
 	return &Server{
 		addr: net.JoinHostPort(config.Host, strconv.Itoa(config.Port)),
 		srv:  &http.Server{Addr: net.JoinHostPort(config.Host, strconv.Itoa(config.Port))},
+		app:  app,
 	}
 }
 
@@ -38,6 +45,15 @@ func (s *Server) Start(_ context.Context, mux *runtime.ServeMux) error {
 	mux.HandlePath("GET", "/hello", func(w http.ResponseWriter, r *http.Request, pathParams map[string]string) {
 		w.Write([]byte("HELLO !!!"))
 	})
+	if err := mux.HandlePath("GET", openAPIPath, serveOpenAPI); err != nil {
+		return fmt.Errorf("failed to add OpenAPI handler: %w", err)
+	}
+	if err := mux.HandlePath("GET", "/swagger", serveSwaggerUI); err != nil {
+		return fmt.Errorf("failed to add Swagger UI handler: %w", err)
+	}
+	if err := mux.HandlePath("GET", feedPath, s.serveFeed); err != nil {
+		return fmt.Errorf("failed to add feed handler: %w", err)
+	}
 	s.srv.Handler = loggingMiddleware(mux)
 
 	log.Printf("starting http server on %s", s.addr)
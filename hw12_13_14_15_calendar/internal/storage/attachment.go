@@ -0,0 +1,30 @@
+package storage
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrNotFoundAttachment  = errors.New("attachment not found")
+	ErrNoAttachmentContent = errors.New("attachment has no content")
+)
+
+// Attachment is a document of the event, it is either a link with URL or a content kept in blob store.
+type Attachment struct {
+	ID          string `json:"id"`
+	EventID     string `json:"eventId"`
+	Name        string `json:"name" validate:"required|maxlen:255"`
+	ContentType string `json:"contentType" validate:"maxlen:255"`
+	// Size of the content in bytes, zero for links.
+	Size int64  `json:"size"`
+	URL  string `json:"url" validate:"maxlen:2048|regexp:^(https?://\\S+)?$"`
+	// Key of the content in blob store, empty for links.
+	BlobKey   string    `json:"blobKey"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// IsLink tells whether the attachment has no content in blob store.
+func (a Attachment) IsLink() bool {
+	return a.BlobKey == ""
+}
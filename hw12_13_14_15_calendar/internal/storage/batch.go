@@ -0,0 +1,43 @@
+package storage
+
+import "errors"
+
+// ErrBatchRolledBack is set for succeeded items of all-or-nothing batch when another item failed.
+var ErrBatchRolledBack = errors.New("batch is rolled back")
+
+type BatchOptions struct {
+	// Nothing is saved if any item of the batch fails.
+	AllOrNothing bool
+}
+
+// EventRef identifies event to remove, version is checked if not zero.
+type EventRef struct {
+	ID      string
+	Version int64
+}
+
+// BatchResult is outcome for the batch item with the same index.
+type BatchResult struct {
+	// Saved event state, set if Err is nil.
+	Event Event
+	Err   error
+}
+
+// BatchFailed checks if any item of the batch failed.
+func BatchFailed(results []BatchResult) bool {
+	for _, r := range results {
+		if r.Err != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// RollbackBatch marks succeeded items as rolled back.
+func RollbackBatch(results []BatchResult) {
+	for i := range results {
+		if results[i].Err == nil {
+			results[i] = BatchResult{Err: ErrBatchRolledBack}
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrNotFoundCalendar = errors.New("calendar not found")
+	ErrAccessDenied     = errors.New("access denied")
+	ErrIncorrectAccess  = errors.New("incorrect access")
+)
+
+// Access is a level of user rights to the calendar, higher level includes lower ones.
+type Access string
+
+const (
+	AccessNone Access = ""
+	// Read events of the calendar.
+	AccessRead Access = "read"
+	// Add, change and remove events of the calendar.
+	AccessWrite Access = "write"
+	// Change the calendar and share it with other users.
+	AccessAdmin Access = "admin"
+)
+
+var accessLevels = map[Access]int{AccessRead: 1, AccessWrite: 2, AccessAdmin: 3}
+
+func (a Access) Valid() bool {
+	_, ok := accessLevels[a]
+	return ok
+}
+
+// Allows checks that access includes required one.
+func (a Access) Allows(required Access) bool {
+	return a.Valid() && accessLevels[a] >= accessLevels[required]
+}
+
+// Calendar groups events, events are accessible to owner of the calendar and users it is shared with.
+type Calendar struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	OwnerID  string `json:"ownerId"`
+	TimeZone string `json:"timeZone"`
+	// Access of current user, not stored.
+	Access Access `json:"-"`
+}
+
+// Grant shares calendar with user.
+type Grant struct {
+	CalendarID string `json:"calendarId"`
+	UserID     string `json:"userId"`
+	Access     Access `json:"access"`
+}
+
+// CheckAccess is CheckCalendarAccess for calendar of event, events without calendar are accessible for everyone.
+func CheckAccess(calendarID string, access Access, required Access) error {
+	if calendarID == "" {
+		return nil
+	}
+	return CheckCalendarAccess(calendarID, access, required)
+}
+
+// CheckCalendarAccess returns error if access to the calendar is lower than required one.
+// Calendar without any access is reported as not found.
+func CheckCalendarAccess(calendarID string, access Access, required Access) error {
+	if !access.Valid() {
+		return fmt.Errorf("calendar with id %q: %w", calendarID, ErrNotFoundCalendar)
+	}
+	if !access.Allows(required) {
+		return fmt.Errorf("calendar with id %q: %w", calendarID, ErrAccessDenied)
+	}
+	return nil
+}
+
+// CheckEventAccess is CheckAccess for existing event, event of calendar without access is reported as not found.
+func CheckEventAccess(e Event, access Access, required Access) error {
+	err := CheckAccess(e.CalendarID, access, required)
+	if errors.Is(err, ErrNotFoundCalendar) {
+		return fmt.Errorf("event with id %q: %w", e.ID, ErrNotFoundEvent)
+	}
+	return err
+}
@@ -1,15 +1,115 @@
 package storage
 
 import (
+	"fmt"
 	"time"
 )
 
+// Event fields are checked by validate tags, see validator.Validate.
 type Event struct {
 	ID           string    `json:"id"`
-	Title        string    `json:"title"`
+	Title        string    `json:"title" validate:"required|maxlen:255"`
 	StartTime    time.Time `json:"startTime"`
 	EndTime      time.Time `json:"endTime"`
-	Description  string    `json:"description"`
-	OwnerID      string    `json:"ownerId"`
-	NotifyBefore int32     `json:"notifyBefore"`
+	Description  string    `json:"description" validate:"maxlen:4096"`
+	OwnerID      string    `json:"ownerId" validate:"required|maxlen:255"`
+	NotifyBefore int32     `json:"notifyBefore" validate:"min:0"`
+	Category     string    `json:"category" validate:"maxlen:64"`
+	Tags         []string  `json:"tags" validate:"maxlen:64"`
+	Color        string    `json:"color" validate:"regexp:^(#[0-9a-fA-F]{6})?$"`
+	// All-day event has date-only bounds, see NormalizeAllDay.
+	AllDay bool `json:"allDay"`
+	// Free events do not block time of owner in free/busy.
+	Transparency Transparency `json:"transparency"`
+	// Empty for events out of calendars, such events are accessible for everyone.
+	CalendarID string `json:"calendarId"`
+	// Rooms and equipment booked by the event, one resource can not be booked by overlapping events.
+	ResourceIDs []string `json:"resourceIds" validate:"maxlen:64"`
+	// Set for events moved to trash.
+	DeletedAt *time.Time `json:"deletedAt,omitempty"`
+	// Incremented on every change, used for optimistic locking.
+	Version   int64     `json:"version"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// NormalizeAllDay makes bounds of all-day event date-only: StartTime is midnight of the first day and
+// EndTime is midnight of the day after the last one (both in UTC). Zero EndTime means one-day event.
+func NormalizeAllDay(e *Event) {
+	if !e.AllDay {
+		return
+	}
+	e.StartTime = Date(e.StartTime)
+	if e.EndTime.IsZero() {
+		e.EndTime = e.StartTime.AddDate(0, 0, 1)
+		return
+	}
+	end := Date(e.EndTime)
+	if end.Before(Floating(e.EndTime)) {
+		end = end.AddDate(0, 0, 1)
+	}
+	e.EndTime = end
+}
+
+// CheckEventTime checks that event ends after start and does not start in the past,
+// all-day event can be added for the current day. Only app checks it, storages accept any time.
+func CheckEventTime(e Event, now time.Time) error {
+	if !e.EndTime.After(e.StartTime) {
+		return fmt.Errorf("event end time should be after of start time: %w", ErrIncorrectEventTime)
+	}
+	if (e.AllDay && !e.EndTime.After(Floating(now))) || (!e.AllDay && e.StartTime.Before(now)) {
+		return fmt.Errorf("start time of the event must be in the future: %w", ErrIncorrectEventTime)
+	}
+	return nil
+}
+
+// Overlaps checks that event overlaps range [from:to).
+// All-day event is compared with dates of the range in its location.
+func (e Event) Overlaps(from time.Time, to time.Time) bool {
+	if e.AllDay {
+		from, to = Floating(from), Floating(to)
+	}
+	return e.StartTime.Before(to) && e.EndTime.After(from)
+}
+
+// Floating returns wall clock of the time in UTC, it is used to compare time with all-day event bounds.
+func Floating(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+}
+
+// InLocation is reverse of Floating, it returns the wall clock of floating time in the location.
+func InLocation(t time.Time, loc *time.Location) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), loc)
+}
+
+// Date returns midnight of the date of the time in UTC.
+func Date(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// EventFilter narrows list queries. Zero value matches all events.
+type EventFilter struct {
+	Category string
+	// Event must have all listed tags.
+	Tags []string
+}
+
+func (f EventFilter) Match(e Event) bool {
+	if f.Category != "" && f.Category != e.Category {
+		return false
+	}
+	for _, tag := range f.Tags {
+		if !hasTag(e.Tags, tag) {
+			return false
+		}
+	}
+	return true
+}
+
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
 }
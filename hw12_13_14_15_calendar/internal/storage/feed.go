@@ -0,0 +1,11 @@
+package storage
+
+import "errors"
+
+var ErrNotFoundFeedToken = errors.New("feed token not found")
+
+// FeedEvent tells whether the event belongs to the feed of the user: events out of calendars
+// are included for the owner only, events of calendars for everyone who can read them.
+func FeedEvent(e Event, userID string) bool {
+	return e.CalendarID != "" || e.OwnerID == userID
+}
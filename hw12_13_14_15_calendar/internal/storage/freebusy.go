@@ -0,0 +1,80 @@
+package storage
+
+import (
+	"sort"
+	"time"
+)
+
+// Transparency tells whether event blocks time of its owner, empty value means busy.
+type Transparency string
+
+const (
+	TransparencyBusy Transparency = "busy"
+	TransparencyFree Transparency = "free"
+)
+
+func (t Transparency) Valid() bool {
+	return t == "" || t == TransparencyBusy || t == TransparencyFree
+}
+
+// Interval is a period [Start:End).
+type Interval struct {
+	Start time.Time
+	End   time.Time
+}
+
+// FreeBusy is busy time of the user, intervals are ordered and do not overlap.
+type FreeBusy struct {
+	OwnerID string
+	Busy    []Interval
+}
+
+// Busy tells whether event blocks time of its owner.
+func (e Event) Busy() bool {
+	return e.Transparency != TransparencyFree
+}
+
+// MergeBusy returns busy time of owners in order of ownerIDs.
+// Busy events of the owners are clipped by [from:to), overlapping and adjacent intervals are merged.
+// All-day event takes its dates in location of from, like in Event.Overlaps.
+func MergeBusy(ownerIDs []string, events []Event, from time.Time, to time.Time) []FreeBusy {
+	intervals := make(map[string][]Interval, len(ownerIDs))
+	for _, e := range events {
+		if !e.Busy() || !e.Overlaps(from, to) {
+			continue
+		}
+		in := Interval{Start: e.StartTime, End: e.EndTime}
+		if e.AllDay {
+			in = Interval{Start: InLocation(e.StartTime, from.Location()), End: InLocation(e.EndTime, from.Location())}
+		}
+		if in.Start.Before(from) {
+			in.Start = from
+		}
+		if in.End.After(to) {
+			in.End = to
+		}
+		intervals[e.OwnerID] = append(intervals[e.OwnerID], in)
+	}
+
+	result := make([]FreeBusy, 0, len(ownerIDs))
+	for _, ownerID := range ownerIDs {
+		result = append(result, FreeBusy{OwnerID: ownerID, Busy: mergeIntervals(intervals[ownerID])})
+	}
+	return result
+}
+
+func mergeIntervals(intervals []Interval) []Interval {
+	sort.Slice(intervals, func(i, j int) bool { return intervals[i].Start.Before(intervals[j].Start) })
+	merged := make([]Interval, 0, len(intervals))
+	for _, in := range intervals {
+		last := len(merged) - 1
+		if last >= 0 && !in.Start.After(merged[last].End) {
+			if in.End.After(merged[last].End) {
+				merged[last].End = in.End
+			}
+			continue
+		}
+		merged = append(merged, in)
+	}
+	return merged
+}
@@ -0,0 +1,47 @@
+package storage
+
+import (
+	"context"
+	"time"
+)
+
+type Operation string
+
+const (
+	OperationCreate  Operation = "create"
+	OperationUpdate  Operation = "update"
+	OperationDelete  Operation = "delete"
+	OperationRestore Operation = "restore"
+)
+
+// EventChange is an audit record of event modification.
+type EventChange struct {
+	ID        int64     `json:"id"`
+	EventID   string    `json:"eventId"`
+	Operation Operation `json:"operation"`
+	ActorID   string    `json:"actorId"`
+	// Event state before change, nil for created event.
+	Before    *Event    `json:"before"`
+	After     *Event    `json:"after"`
+	ChangedAt time.Time `json:"changedAt"`
+}
+
+type actorKey struct{}
+
+// ContextWithActor stores ID of user performing the request.
+func ContextWithActor(ctx context.Context, actorID string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actorID)
+}
+
+func ActorFromContext(ctx context.Context) string {
+	actorID, _ := ctx.Value(actorKey{}).(string)
+	return actorID
+}
+
+// Actor returns user from context or event owner if user is unknown.
+func Actor(ctx context.Context, e Event) string {
+	if actorID := ActorFromContext(ctx); actorID != "" {
+		return actorID
+	}
+	return e.OwnerID
+}
@@ -0,0 +1,108 @@
+package memorystorage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/storage"
+)
+
+func (s *Storage) AddAttachment(ctx context.Context, a *storage.Attachment) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.checkAttachmentAccess(ctx, a.EventID, storage.AccessWrite); err != nil {
+		return fmt.Errorf("failed to add attachment: %w", err)
+	}
+	a.ID = s.nextID()
+	a.CreatedAt = s.clock.Now()
+	s.attachments[a.EventID] = append(s.attachments[a.EventID], *a)
+	return nil
+}
+
+func (s *Storage) CheckAttachmentWrite(ctx context.Context, eventID string) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if err := s.checkAttachmentAccess(ctx, eventID, storage.AccessWrite); err != nil {
+		return fmt.Errorf("failed to add attachment: %w", err)
+	}
+	return nil
+}
+
+func (s *Storage) GetAttachments(ctx context.Context, eventID string) ([]storage.Attachment, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if err := s.checkAttachmentAccess(ctx, eventID, storage.AccessRead); err != nil {
+		return nil, fmt.Errorf("failed to get attachments: %w", err)
+	}
+	return append(make([]storage.Attachment, 0, len(s.attachments[eventID])), s.attachments[eventID]...), nil
+}
+
+func (s *Storage) GetAttachment(ctx context.Context, id string) (storage.Attachment, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	a, _, ok := s.findAttachment(id)
+	if !ok {
+		return storage.Attachment{}, fmt.Errorf("failed to get attachment with id %q: %w", id, storage.ErrNotFoundAttachment)
+	}
+	if err := s.checkAttachmentAccess(ctx, a.EventID, storage.AccessRead); err != nil {
+		return storage.Attachment{}, fmt.Errorf("failed to get attachment: %w", err)
+	}
+	return a, nil
+}
+
+func (s *Storage) RemoveAttachment(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, i, ok := s.findAttachment(id)
+	if !ok {
+		return fmt.Errorf("failed to remove attachment with id %q: %w", id, storage.ErrNotFoundAttachment)
+	}
+	if err := s.checkAttachmentAccess(ctx, a.EventID, storage.AccessWrite); err != nil {
+		return fmt.Errorf("failed to remove attachment: %w", err)
+	}
+	attachments := s.attachments[a.EventID]
+	s.attachments[a.EventID] = append(attachments[:i:i], attachments[i+1:]...)
+	return nil
+}
+
+func (s *Storage) TakeOrphanBlobs(_ context.Context, limit int) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if limit > len(s.orphanBlobs) {
+		limit = len(s.orphanBlobs)
+	}
+	keys := append(make([]string, 0, limit), s.orphanBlobs[:limit]...)
+	s.orphanBlobs = s.orphanBlobs[limit:]
+	return keys, nil
+}
+
+// Removes attachments of the removed event keeping keys of their content, must be called under write lock.
+func (s *Storage) removeAttachments(eventID string) {
+	for _, a := range s.attachments[eventID] {
+		if !a.IsLink() {
+			s.orphanBlobs = append(s.orphanBlobs, a.BlobKey)
+		}
+	}
+	delete(s.attachments, eventID)
+}
+
+// Returns attachment with its index in attachments of the event, must be called under lock.
+func (s *Storage) findAttachment(id string) (storage.Attachment, int, bool) {
+	for _, attachments := range s.attachments {
+		for i, a := range attachments {
+			if a.ID == id {
+				return a, i, true
+			}
+		}
+	}
+	return storage.Attachment{}, 0, false
+}
+
+// Checks access to active event of the attachment, must be called under lock.
+func (s *Storage) checkAttachmentAccess(ctx context.Context, eventID string, required storage.Access) error {
+	e, ok := s.data[eventID]
+	if !ok || e.DeletedAt != nil {
+		return fmt.Errorf("event with id %q: %w", eventID, storage.ErrNotFoundEvent)
+	}
+	return s.checkEventAccess(ctx, e, required)
+}
@@ -0,0 +1,142 @@
+package memorystorage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/storage"
+)
+
+func (s *Storage) CreateCalendar(_ context.Context, c *storage.Calendar) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c.ID = s.nextID()
+	c.Access = storage.AccessAdmin
+	s.calendars[c.ID] = *c
+	return nil
+}
+
+func (s *Storage) UpdateCalendar(ctx context.Context, c *storage.Calendar) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := storage.CheckCalendarAccess(c.ID, s.access(ctx, c.ID), storage.AccessAdmin); err != nil {
+		return err
+	}
+	stored := s.calendars[c.ID]
+	stored.Name = c.Name
+	stored.TimeZone = c.TimeZone
+	s.calendars[c.ID] = stored
+	*c = stored
+	c.Access = s.access(ctx, c.ID)
+	return nil
+}
+
+func (s *Storage) RemoveCalendar(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := storage.CheckCalendarAccess(id, s.access(ctx, id), storage.AccessAdmin); err != nil {
+		return err
+	}
+	if s.calendars[id].OwnerID != storage.ActorFromContext(ctx) {
+		return fmt.Errorf("calendar with id %q can be removed by owner only: %w", id, storage.ErrAccessDenied)
+	}
+	for eventID, e := range s.data {
+		if e.CalendarID == id {
+			delete(s.data, eventID)
+			delete(s.reminders, eventID)
+			s.removeAttachments(eventID)
+			s.index.invalidate(eventID)
+		}
+	}
+	delete(s.calendars, id)
+	delete(s.grants, id)
+	return nil
+}
+
+func (s *Storage) GetCalendars(ctx context.Context) ([]storage.Calendar, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	calendars := make([]storage.Calendar, 0)
+	for id, c := range s.calendars {
+		c.Access = s.access(ctx, id)
+		if c.Access.Valid() {
+			calendars = append(calendars, c)
+		}
+	}
+	sort.Slice(calendars, func(i, j int) bool {
+		if calendars[i].Name != calendars[j].Name {
+			return calendars[i].Name < calendars[j].Name
+		}
+		return calendars[i].ID < calendars[j].ID
+	})
+	return calendars, nil
+}
+
+func (s *Storage) ShareCalendar(ctx context.Context, g storage.Grant) error {
+	if !g.Access.Valid() {
+		return fmt.Errorf("access %q: %w", g.Access, storage.ErrIncorrectAccess)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := storage.CheckCalendarAccess(g.CalendarID, s.access(ctx, g.CalendarID), storage.AccessAdmin); err != nil {
+		return err
+	}
+	if s.grants[g.CalendarID] == nil {
+		s.grants[g.CalendarID] = make(map[string]storage.Access)
+	}
+	s.grants[g.CalendarID][g.UserID] = g.Access
+	return nil
+}
+
+func (s *Storage) RevokeCalendarAccess(ctx context.Context, calendarID string, userID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := storage.CheckCalendarAccess(calendarID, s.access(ctx, calendarID), storage.AccessAdmin); err != nil {
+		return err
+	}
+	delete(s.grants[calendarID], userID)
+	return nil
+}
+
+func (s *Storage) GetCalendarGrants(ctx context.Context, calendarID string) ([]storage.Grant, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if err := storage.CheckCalendarAccess(calendarID, s.access(ctx, calendarID), storage.AccessAdmin); err != nil {
+		return nil, err
+	}
+	grants := make([]storage.Grant, 0, len(s.grants[calendarID]))
+	for userID, access := range s.grants[calendarID] {
+		grants = append(grants, storage.Grant{CalendarID: calendarID, UserID: userID, Access: access})
+	}
+	sort.Slice(grants, func(i, j int) bool { return grants[i].UserID < grants[j].UserID })
+	return grants, nil
+}
+
+// Returns access of user from context to the calendar, must be called under lock.
+func (s *Storage) access(ctx context.Context, calendarID string) storage.Access {
+	c, ok := s.calendars[calendarID]
+	actorID := storage.ActorFromContext(ctx)
+	switch {
+	case !ok || actorID == "":
+		return storage.AccessNone
+	case c.OwnerID == actorID:
+		return storage.AccessAdmin
+	}
+	return s.grants[calendarID][actorID]
+}
+
+// Must be called under lock.
+func (s *Storage) checkAccess(ctx context.Context, calendarID string, required storage.Access) error {
+	return storage.CheckAccess(calendarID, s.access(ctx, calendarID), required)
+}
+
+// Must be called under lock.
+func (s *Storage) checkEventAccess(ctx context.Context, e storage.Event, required storage.Access) error {
+	return storage.CheckEventAccess(e, s.access(ctx, e.CalendarID), required)
+}
+
+// Must be called under lock.
+func (s *Storage) visible(ctx context.Context, e storage.Event) bool {
+	return s.checkEventAccess(ctx, e, storage.AccessRead) == nil
+}
@@ -0,0 +1,50 @@
+package memorystorage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/storage"
+)
+
+func (s *Storage) SetFeedToken(_ context.Context, userID string, tokenHash string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.feedTokens[userID] = tokenHash
+	return nil
+}
+
+func (s *Storage) RemoveFeedToken(_ context.Context, userID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.feedTokens[userID]; !ok {
+		return fmt.Errorf("failed to remove feed token of user %q: %w", userID, storage.ErrNotFoundFeedToken)
+	}
+	delete(s.feedTokens, userID)
+	return nil
+}
+
+func (s *Storage) GetFeedUser(_ context.Context, tokenHash string) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for userID, hash := range s.feedTokens {
+		if hash == tokenHash {
+			return userID, nil
+		}
+	}
+	return "", storage.ErrNotFoundFeedToken
+}
+
+func (s *Storage) GetFeedEvents(ctx context.Context, endAfter time.Time) ([]storage.Event, error) {
+	userID := storage.ActorFromContext(ctx)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	events := make([]storage.Event, 0)
+	for _, e := range s.data {
+		if e.EndTime.After(endAfter) && storage.FeedEvent(e, userID) && s.visible(ctx, e) {
+			events = append(events, cloneEvent(e))
+		}
+	}
+	return events, nil
+}
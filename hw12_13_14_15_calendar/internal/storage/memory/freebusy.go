@@ -0,0 +1,33 @@
+package memorystorage
+
+import (
+	"context"
+	"time"
+
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/storage"
+)
+
+func (s *Storage) GetFreeBusy(
+	_ context.Context,
+	ownerIDs []string,
+	from time.Time,
+	to time.Time,
+) ([]storage.FreeBusy, error) {
+	if !to.After(from) {
+		return nil, storage.ErrIncorrectPeriod
+	}
+	owners := make(map[string]bool, len(ownerIDs))
+	for _, ownerID := range ownerIDs {
+		owners[ownerID] = true
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var events []storage.Event
+	for _, e := range s.data {
+		if e.DeletedAt == nil && owners[e.OwnerID] {
+			events = append(events, e)
+		}
+	}
+	return storage.MergeBusy(ownerIDs, events, from, to), nil
+}
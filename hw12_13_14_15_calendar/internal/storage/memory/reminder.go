@@ -0,0 +1,74 @@
+package memorystorage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/storage"
+)
+
+func (s *Storage) GetReminders(
+	_ context.Context,
+	startTime time.Time,
+	endTime time.Time,
+) ([]storage.Reminder, error) {
+	reminders := make([]storage.Reminder, 0)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, e := range s.data {
+		if e.DeletedAt != nil || !e.HasReminder() {
+			continue
+		}
+		dueAt, ok := s.reminderState(e).DueAt()
+		if ok && !dueAt.Before(startTime) && dueAt.Before(endTime) {
+			reminders = append(reminders, storage.Reminder{Event: cloneEvent(e), RemindAt: dueAt})
+		}
+	}
+	return reminders, nil
+}
+
+func (s *Storage) AcknowledgeReminder(ctx context.Context, eventID string) error {
+	return s.setReminderState(ctx, eventID, true, time.Time{})
+}
+
+func (s *Storage) SnoozeReminder(ctx context.Context, eventID string, until time.Time) error {
+	return s.setReminderState(ctx, eventID, false, until)
+}
+
+// Sets state of current reminder of the event, state of previous reminder is replaced.
+func (s *Storage) setReminderState(
+	ctx context.Context,
+	eventID string,
+	acknowledged bool,
+	snoozedUntil time.Time,
+) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.data[eventID]
+	if !ok || e.DeletedAt != nil {
+		return fmt.Errorf("failed to change reminder of event with id %q: %w", eventID, storage.ErrNotFoundEvent)
+	}
+	if err := s.checkEventAccess(ctx, e, storage.AccessWrite); err != nil {
+		return fmt.Errorf("failed to change reminder: %w", err)
+	}
+	if !e.HasReminder() {
+		return fmt.Errorf("failed to change reminder of event with id %q: %w", eventID, storage.ErrNoReminder)
+	}
+	s.reminders[eventID] = storage.ReminderState{
+		EventID:      eventID,
+		RemindAt:     e.RemindAt(),
+		Acknowledged: acknowledged,
+		SnoozedUntil: snoozedUntil,
+	}
+	return nil
+}
+
+// Returns state of current reminder of the event, must be called under lock.
+func (s *Storage) reminderState(e storage.Event) storage.ReminderState {
+	state, ok := s.reminders[e.ID]
+	if !ok || !state.RemindAt.Equal(e.RemindAt()) {
+		return storage.ReminderState{EventID: e.ID, RemindAt: e.RemindAt()}
+	}
+	return state
+}
@@ -0,0 +1,96 @@
+package memorystorage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/storage"
+)
+
+func (s *Storage) CreateResource(_ context.Context, r *storage.Resource) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r.ID = s.nextID()
+	s.resources[r.ID] = *r
+	return nil
+}
+
+func (s *Storage) RemoveResource(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.resources[id]; !ok {
+		return fmt.Errorf("failed to remove resource with id %q: %w", id, storage.ErrNotFoundResource)
+	}
+	for eventID, e := range s.data {
+		ids := make([]string, 0, len(e.ResourceIDs))
+		for _, resourceID := range e.ResourceIDs {
+			if resourceID != id {
+				ids = append(ids, resourceID)
+			}
+		}
+		if len(ids) != len(e.ResourceIDs) {
+			e.ResourceIDs = ids
+			s.data[eventID] = e
+		}
+	}
+	delete(s.resources, id)
+	return nil
+}
+
+func (s *Storage) GetResources(_ context.Context, filter storage.ResourceFilter) ([]storage.Resource, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	resources := make([]storage.Resource, 0)
+	for _, r := range s.resources {
+		if filter.Match(r) {
+			resources = append(resources, r)
+		}
+	}
+	sort.Slice(resources, func(i, j int) bool {
+		if resources[i].Name != resources[j].Name {
+			return resources[i].Name < resources[j].Name
+		}
+		return resources[i].ID < resources[j].ID
+	})
+	return resources, nil
+}
+
+func (s *Storage) GetResourceSchedule(
+	_ context.Context,
+	id string,
+	from time.Time,
+	to time.Time,
+) ([]storage.Booking, error) {
+	if !to.After(from) {
+		return nil, storage.ErrIncorrectPeriod
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if _, ok := s.resources[id]; !ok {
+		return nil, fmt.Errorf("failed to get schedule of resource with id %q: %w", id, storage.ErrNotFoundResource)
+	}
+	events := make([]storage.Event, 0)
+	for _, e := range s.data {
+		events = append(events, e)
+	}
+	return storage.BookingsOf(id, events, from, to), nil
+}
+
+// Checks that resources of the event exist and are not booked by other events, must be called under lock.
+func (s *Storage) checkBookings(e storage.Event) error {
+	if len(e.ResourceIDs) == 0 {
+		return nil
+	}
+	for _, id := range e.ResourceIDs {
+		if _, ok := s.resources[id]; !ok {
+			return fmt.Errorf("resource with id %q: %w", id, storage.ErrNotFoundResource)
+		}
+	}
+	events := make([]storage.Event, 0)
+	for _, other := range s.data {
+		events = append(events, other)
+	}
+	return storage.CheckBookings(e, events)
+}
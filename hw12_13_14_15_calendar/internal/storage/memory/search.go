@@ -0,0 +1,192 @@
+package memorystorage
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/storage"
+)
+
+// Rank weights of words found in title and description.
+const (
+	titleWeight       = 1.0
+	descriptionWeight = 0.4
+)
+
+// Inverted index of event words, positions of description words go after title ones.
+// Changed events are reindexed on search to keep writes cheap.
+type searchIndex struct {
+	mu sync.Mutex
+	// IDs of changed events.
+	dirty map[string]bool
+	// Word -> event ID -> positions of the word.
+	postings map[string]map[string][]int
+	// Event ID -> indexed words, used to remove event from postings.
+	words map[string][]string
+	// Event ID -> number of title words.
+	titleLen map[string]int
+}
+
+func newSearchIndex() *searchIndex {
+	return &searchIndex{
+		dirty:    make(map[string]bool),
+		postings: make(map[string]map[string][]int),
+		words:    make(map[string][]string),
+		titleLen: make(map[string]int),
+	}
+}
+
+func (ix *searchIndex) invalidate(id string) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.dirty[id] = true
+}
+
+// Returns rank of events matching all terms, data is used to reindex changed events.
+func (ix *searchIndex) search(terms []storage.SearchTerm, data map[string]storage.Event) map[string]float64 {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	for id := range ix.dirty {
+		ix.remove(id)
+		if e, ok := data[id]; ok {
+			ix.add(e)
+		}
+		delete(ix.dirty, id)
+	}
+
+	var ranks map[string]float64
+	for _, term := range terms {
+		termRanks := make(map[string]float64)
+		for id, positions := range ix.match(term) {
+			if ranks != nil {
+				if _, ok := ranks[id]; !ok {
+					continue
+				}
+			}
+			for _, pos := range positions {
+				termRanks[id] += ix.weight(id, pos)
+			}
+			termRanks[id] += ranks[id]
+		}
+		ranks = termRanks
+	}
+	return ranks
+}
+
+func (ix *searchIndex) add(e storage.Event) {
+	words := storage.SearchWords(e.Title)
+	ix.titleLen[e.ID] = len(words)
+	words = append(words, storage.SearchWords(e.Description)...)
+	for pos, word := range words {
+		events, ok := ix.postings[word]
+		if !ok {
+			events = make(map[string][]int)
+			ix.postings[word] = events
+		}
+		if len(events[e.ID]) == 0 {
+			ix.words[e.ID] = append(ix.words[e.ID], word)
+		}
+		events[e.ID] = append(events[e.ID], pos)
+	}
+}
+
+func (ix *searchIndex) remove(id string) {
+	for _, word := range ix.words[id] {
+		delete(ix.postings[word], id)
+		if len(ix.postings[word]) == 0 {
+			delete(ix.postings, word)
+		}
+	}
+	delete(ix.words, id)
+	delete(ix.titleLen, id)
+}
+
+// Returns start positions of term in events.
+func (ix *searchIndex) match(term storage.SearchTerm) map[string][]int {
+	last := len(term.Words) - 1
+	matches := ix.positions(term.Words[0], term.Prefix && last == 0)
+	for i := 1; i <= last && len(matches) > 0; i++ {
+		next := ix.positions(term.Words[i], term.Prefix && i == last)
+		for id, starts := range matches {
+			found := make(map[int]bool, len(next[id]))
+			for _, pos := range next[id] {
+				found[pos] = true
+			}
+			kept := starts[:0:0]
+			for _, start := range starts {
+				if found[start+i] {
+					kept = append(kept, start)
+				}
+			}
+			if len(kept) == 0 {
+				delete(matches, id)
+				continue
+			}
+			matches[id] = kept
+		}
+	}
+	return matches
+}
+
+// Returns positions of word (or words with such prefix) in events.
+func (ix *searchIndex) positions(word string, prefix bool) map[string][]int {
+	positions := make(map[string][]int)
+	if !prefix {
+		for id, pos := range ix.postings[word] {
+			positions[id] = pos
+		}
+		return positions
+	}
+	for w, events := range ix.postings {
+		if !strings.HasPrefix(w, word) {
+			continue
+		}
+		for id, pos := range events {
+			positions[id] = append(positions[id], pos...)
+		}
+	}
+	return positions
+}
+
+func (ix *searchIndex) weight(id string, pos int) float64 {
+	if pos < ix.titleLen[id] {
+		return titleWeight
+	}
+	return descriptionWeight
+}
+
+func (s *Storage) SearchEvents(ctx context.Context, query storage.SearchQuery) ([]storage.Event, error) {
+	terms := storage.ParseSearchQuery(query.Text)
+	if len(terms) == 0 {
+		return nil, storage.ErrEmptySearchQuery
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	ranks := s.index.search(terms, s.data)
+	events := make([]storage.Event, 0, len(ranks))
+	for id := range ranks {
+		event := s.data[id]
+		if event.DeletedAt != nil ||
+			(query.OwnerID != "" && event.OwnerID != query.OwnerID) ||
+			(!query.From.IsZero() && event.StartTime.Before(query.From)) ||
+			(!query.To.IsZero() && !event.StartTime.Before(query.To)) ||
+			!s.visible(ctx, event) {
+			continue
+		}
+		events = append(events, cloneEvent(event))
+	}
+
+	sort.Slice(events, func(i, j int) bool {
+		if ranks[events[i].ID] != ranks[events[j].ID] {
+			return ranks[events[i].ID] > ranks[events[j].ID]
+		}
+		return events[i].StartTime.Before(events[j].StartTime)
+	})
+	if query.Limit > 0 && len(events) > query.Limit {
+		events = events[:query.Limit]
+	}
+	return events, nil
+}
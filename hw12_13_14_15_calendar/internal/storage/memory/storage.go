@@ -7,19 +7,48 @@ import (
 	"sync"
 	"time"
 
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/clock"
 	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/storage"
 	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/util"
 )
 
 type Storage struct {
-	mu           sync.RWMutex
-	data         map[string]storage.Event
+	mu        sync.RWMutex
+	data      map[string]storage.Event
+	history   map[string][]storage.EventChange
+	index     *searchIndex
+	calendars map[string]storage.Calendar
+	// Calendar ID -> user ID -> access.
+	grants map[string]map[string]storage.Access
+	// Event ID -> state of the event reminder.
+	reminders map[string]storage.ReminderState
+	// Event ID -> attachments ordered by creation.
+	attachments map[string][]storage.Attachment
+	// Blob keys of attachments removed with their events, see TakeOrphanBlobs.
+	orphanBlobs []string
+	resources   map[string]storage.Resource
+	// User ID -> hash of feed token.
+	feedTokens   map[string]string
 	idSeq        int
+	changeSeq    int64
 	firstWeekDay time.Weekday
+	clock        clock.Clock
 }
 
-func New() *Storage {
-	return &Storage{data: make(map[string]storage.Event), firstWeekDay: time.Monday}
+func New(clk clock.Clock) *Storage {
+	return &Storage{
+		data:         make(map[string]storage.Event),
+		history:      make(map[string][]storage.EventChange),
+		index:        newSearchIndex(),
+		calendars:    make(map[string]storage.Calendar),
+		grants:       make(map[string]map[string]storage.Access),
+		reminders:    make(map[string]storage.ReminderState),
+		attachments:  make(map[string][]storage.Attachment),
+		resources:    make(map[string]storage.Resource),
+		feedTokens:   make(map[string]string),
+		firstWeekDay: time.Monday,
+		clock:        clk,
+	}
 }
 
 func (s *Storage) Connect(_ context.Context) error {
@@ -30,119 +59,359 @@ func (s *Storage) Close(_ context.Context) error {
 	return nil
 }
 
-func (s *Storage) AddEvent(_ context.Context, e *storage.Event) error {
-	if !e.EndTime.After(e.StartTime) {
-		return fmt.Errorf("start time of the event must be in the future: %w", storage.ErrIncorrectEventTime)
-	}
-
-	if e.StartTime.Before(time.Now()) {
-		return storage.ErrIncorrectEventTime
-	}
+func (s *Storage) Ping(_ context.Context) error {
+	return nil
+}
 
+func (s *Storage) AddEvent(ctx context.Context, e *storage.Event) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	if _, ok := s.data[e.ID]; ok {
-		return fmt.Errorf("duplicate ID %q: %w", e.ID, storage.ErrDuplicateEventID)
-	}
-	if e.ID == "" {
-		e.ID = s.nextID()
-	}
-	s.data[e.ID] = *e
-	return nil
+	return s.addEvent(ctx, e)
 }
 
-func (s *Storage) UpdateEvent(_ context.Context, id string, e storage.Event) error {
-	if e.StartTime.Before(time.Now()) {
-		return fmt.Errorf("start time of the event must be in the future: %w", storage.ErrIncorrectEventTime)
-	}
-	if !e.EndTime.After(e.StartTime) {
-		return fmt.Errorf("event end time should be after of start time: %w", storage.ErrIncorrectEventTime)
-	}
+func (s *Storage) UpdateEvent(ctx context.Context, id string, e *storage.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.updateEvent(ctx, id, e)
+}
 
+func (s *Storage) RemoveEvent(ctx context.Context, id string, version int64) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	_, ok := s.data[id]
-	if !ok {
-		return fmt.Errorf("failed to update event with id %q: %w", id, storage.ErrNotFoundEvent)
-	}
-	e.ID = id
-	s.data[e.ID] = e
-	return nil
+	_, err := s.removeEvent(ctx, id, version)
+	return err
 }
 
-func (s *Storage) RemoveEvent(_ context.Context, id string) error {
+func (s *Storage) RestoreEvent(ctx context.Context, id string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	if _, ok := s.data[id]; !ok {
-		return fmt.Errorf("failed to remove event with id %q: %w", id, storage.ErrNotFoundEvent)
+	e, ok := s.data[id]
+	if !ok || e.DeletedAt == nil {
+		return fmt.Errorf("failed to restore event with id %q: %w", id, storage.ErrNotFoundEvent)
+	}
+	if err := s.checkEventAccess(ctx, e, storage.AccessWrite); err != nil {
+		return fmt.Errorf("failed to restore event: %w", err)
 	}
-	delete(s.data, id)
+	if err := s.checkBookings(e); err != nil {
+		return fmt.Errorf("failed to restore event with id %q: %w", id, err)
+	}
+	before := e
+	e.DeletedAt = nil
+	e.Version++
+	e.UpdatedAt = s.clock.Now()
+	s.data[id] = e
+	s.addChange(ctx, storage.OperationRestore, &before, &e)
 	return nil
 }
 
-func (s *Storage) GetEventsForDay(_ context.Context, date time.Time) ([]storage.Event, error) {
+func (s *Storage) AddEvents(
+	ctx context.Context,
+	events []storage.Event,
+	opts storage.BatchOptions,
+) ([]storage.BatchResult, error) {
+	return s.batch(len(events), opts, func(i int) (storage.Event, error) {
+		e := cloneEvent(events[i])
+		err := s.addEvent(ctx, &e)
+		return e, err
+	})
+}
+
+func (s *Storage) UpdateEvents(
+	ctx context.Context,
+	events []storage.Event,
+	opts storage.BatchOptions,
+) ([]storage.BatchResult, error) {
+	return s.batch(len(events), opts, func(i int) (storage.Event, error) {
+		e := cloneEvent(events[i])
+		err := s.updateEvent(ctx, e.ID, &e)
+		return e, err
+	})
+}
+
+func (s *Storage) RemoveEvents(
+	ctx context.Context,
+	refs []storage.EventRef,
+	opts storage.BatchOptions,
+) ([]storage.BatchResult, error) {
+	return s.batch(len(refs), opts, func(i int) (storage.Event, error) {
+		return s.removeEvent(ctx, refs[i].ID, refs[i].Version)
+	})
+}
+
+func (s *Storage) GetDeletedEvents(ctx context.Context, ownerID string) ([]storage.Event, error) {
+	events := make([]storage.Event, 0)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, event := range s.data {
+		if event.DeletedAt != nil && (ownerID == "" || event.OwnerID == ownerID) && s.visible(ctx, event) {
+			events = append(events, cloneEvent(event))
+		}
+	}
+	return events, nil
+}
+
+func (s *Storage) GetEventHistory(ctx context.Context, eventID string) ([]storage.EventChange, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	e, ok := s.data[eventID]
+	if !ok {
+		return nil, fmt.Errorf("failed to get history of event with id %q: %w", eventID, storage.ErrNotFoundEvent)
+	}
+	if !s.visible(ctx, e) {
+		return []storage.EventChange{}, nil
+	}
+	changes := make([]storage.EventChange, 0, len(s.history[eventID]))
+	for _, c := range s.history[eventID] {
+		changes = append(changes, cloneChange(c))
+	}
+	return changes, nil
+}
+
+func (s *Storage) GetEventsForDay(
+	ctx context.Context,
+	date time.Time,
+	filter storage.EventFilter,
+) ([]storage.Event, error) {
 	startTime := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
 	endTime := startTime.Add(24 * time.Hour)
-	return s.selectByRange(startTime, endTime)
+	return s.selectByRange(ctx, startTime, endTime, filter)
 }
 
-func (s *Storage) GetEventsForWeek(_ context.Context, startDate time.Time) ([]storage.Event, error) {
+func (s *Storage) GetEventsForWeek(
+	ctx context.Context,
+	startDate time.Time,
+	filter storage.EventFilter,
+) ([]storage.Event, error) {
 	startTime := util.TruncateToDay(startDate)
 	if startTime.Weekday() != s.firstWeekDay {
 		return nil, storage.ErrIncorrectStartDate
 	}
 	endTime := startTime.AddDate(0, 0, 7)
-	return s.selectByRange(startTime, endTime)
+	return s.selectByRange(ctx, startTime, endTime, filter)
 }
 
-func (s *Storage) GetEventsForMonth(_ context.Context, startDate time.Time) ([]storage.Event, error) {
+func (s *Storage) GetEventsForMonth(
+	ctx context.Context,
+	startDate time.Time,
+	filter storage.EventFilter,
+) ([]storage.Event, error) {
 	startTime := util.TruncateToDay(startDate)
 	if startTime.Day() != 1 {
 		return nil, storage.ErrIncorrectStartDate
 	}
 	endTime := startTime.AddDate(0, 1, 0)
-	return s.selectByRange(startTime, endTime)
+	return s.selectByRange(ctx, startTime, endTime, filter)
 }
 
-func (s *Storage) GetEventsByNotifier(
+func (s *Storage) PurgeDeleted(_ context.Context, deletedBefore time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for k, event := range s.data {
+		if event.DeletedAt != nil && event.DeletedAt.Before(deletedBefore) {
+			delete(s.data, k)
+			delete(s.reminders, k)
+			s.removeAttachments(k)
+			s.index.invalidate(k)
+		}
+	}
+	return nil
+}
+
+// Must be called under write lock.
+func (s *Storage) addEvent(ctx context.Context, e *storage.Event) error {
+	storage.NormalizeAllDay(e)
+	storage.NormalizeResources(e)
+
+	if _, ok := s.data[e.ID]; ok {
+		return fmt.Errorf("duplicate ID %q: %w", e.ID, storage.ErrDuplicateEventID)
+	}
+	if err := s.checkAccess(ctx, e.CalendarID, storage.AccessWrite); err != nil {
+		return fmt.Errorf("failed to add event: %w", err)
+	}
+	if err := s.checkBookings(*e); err != nil {
+		return fmt.Errorf("failed to add event: %w", err)
+	}
+	if e.ID == "" {
+		e.ID = s.nextID()
+	}
+	e.DeletedAt = nil
+	e.Version = 1
+	e.UpdatedAt = s.clock.Now()
+	s.data[e.ID] = cloneEvent(*e)
+	s.index.invalidate(e.ID)
+	s.addChange(ctx, storage.OperationCreate, nil, e)
+	return nil
+}
+
+// Must be called under write lock.
+func (s *Storage) updateEvent(ctx context.Context, id string, e *storage.Event) error {
+	storage.NormalizeAllDay(e)
+	storage.NormalizeResources(e)
+
+	stored, ok := s.data[id]
+	if !ok || stored.DeletedAt != nil {
+		return fmt.Errorf("failed to update event with id %q: %w", id, storage.ErrNotFoundEvent)
+	}
+	if err := s.checkEventAccess(ctx, stored, storage.AccessWrite); err != nil {
+		return fmt.Errorf("failed to update event: %w", err)
+	}
+	if e.CalendarID == "" {
+		e.CalendarID = stored.CalendarID
+	}
+	if err := s.checkAccess(ctx, e.CalendarID, storage.AccessWrite); err != nil {
+		return fmt.Errorf("failed to move event with id %q: %w", id, err)
+	}
+	if e.Version != 0 && e.Version != stored.Version {
+		return fmt.Errorf("failed to update event with id %q: %w", id, storage.ErrVersionConflict)
+	}
+	e.ID = id
+	if err := s.checkBookings(*e); err != nil {
+		return fmt.Errorf("failed to update event with id %q: %w", id, err)
+	}
+	e.DeletedAt = nil
+	e.Version = stored.Version + 1
+	e.UpdatedAt = s.clock.Now()
+	s.data[e.ID] = cloneEvent(*e)
+	s.index.invalidate(e.ID)
+	s.addChange(ctx, storage.OperationUpdate, &stored, e)
+	return nil
+}
+
+// Must be called under write lock.
+func (s *Storage) removeEvent(ctx context.Context, id string, version int64) (storage.Event, error) {
+	e, ok := s.data[id]
+	if !ok || e.DeletedAt != nil {
+		return storage.Event{}, fmt.Errorf("failed to remove event with id %q: %w", id, storage.ErrNotFoundEvent)
+	}
+	if err := s.checkEventAccess(ctx, e, storage.AccessWrite); err != nil {
+		return storage.Event{}, fmt.Errorf("failed to remove event: %w", err)
+	}
+	if version != 0 && version != e.Version {
+		return storage.Event{}, fmt.Errorf("failed to remove event with id %q: %w", id, storage.ErrVersionConflict)
+	}
+	before := e
+	deletedAt := s.clock.Now()
+	e.DeletedAt = &deletedAt
+	e.Version++
+	e.UpdatedAt = deletedAt
+	s.data[id] = e
+	s.addChange(ctx, storage.OperationDelete, &before, &e)
+	return cloneEvent(e), nil
+}
+
+// Select in range [startTime:endTime).
+func (s *Storage) selectByRange(
 	ctx context.Context,
 	startTime time.Time,
 	endTime time.Time,
+	filter storage.EventFilter,
 ) ([]storage.Event, error) {
 	events := make([]storage.Event, 0)
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 	for _, event := range s.data {
-		notifyTime := event.StartTime.Add(time.Hour * time.Duration(event.NotifyBefore))
-		if event.NotifyBefore > 0 && notifyTime.After(startTime) && notifyTime.Before(endTime) {
-			events = append(events, event)
+		if event.DeletedAt == nil && event.Overlaps(startTime, endTime) && filter.Match(event) && s.visible(ctx, event) {
+			events = append(events, cloneEvent(event))
 		}
 	}
 	return events, nil
 }
 
-func (s *Storage) RemoveAfter(ctx context.Context, time time.Time) error {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	for k, event := range s.data {
-		if event.StartTime.After(time) {
-			delete(s.data, k)
+// Calls fn for every item under write lock, state is restored if all-or-nothing batch has failed items.
+func (s *Storage) batch(
+	n int,
+	opts storage.BatchOptions,
+	fn func(i int) (storage.Event, error),
+) ([]storage.BatchResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var restore func()
+	if opts.AllOrNothing {
+		restore = s.snapshot()
+	}
+
+	results := make([]storage.BatchResult, n)
+	for i := range results {
+		e, err := fn(i)
+		if err != nil {
+			results[i].Err = err
+			continue
 		}
+		results[i].Event = e
 	}
-	return nil
+	if opts.AllOrNothing && storage.BatchFailed(results) {
+		restore()
+		storage.RollbackBatch(results)
+		return results, storage.ErrBatchRolledBack
+	}
+	return results, nil
 }
 
-// Select in range [startTime:endTime).
-func (s *Storage) selectByRange(startTime time.Time, endTime time.Time) ([]storage.Event, error) {
-	events := make([]storage.Event, 0)
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	for _, event := range s.data {
-		if (event.StartTime.Equal(startTime) || event.StartTime.After(startTime)) && event.StartTime.Before(endTime) {
-			events = append(events, event)
+// Returns function that restores current state, must be called under write lock.
+func (s *Storage) snapshot() func() {
+	data := make(map[string]storage.Event, len(s.data))
+	for k, v := range s.data {
+		data[k] = v
+	}
+	history := make(map[string][]storage.EventChange, len(s.history))
+	for k, v := range s.history {
+		history[k] = v
+	}
+	idSeq, changeSeq := s.idSeq, s.changeSeq
+	return func() {
+		s.data, s.history = data, history
+		s.index = newSearchIndex()
+		for id := range s.data {
+			s.index.invalidate(id)
 		}
+		s.idSeq, s.changeSeq = idSeq, changeSeq
 	}
-	return events, nil
+}
+
+// Must be called under write lock.
+func (s *Storage) addChange(ctx context.Context, op storage.Operation, before, after *storage.Event) {
+	s.changeSeq++
+	c := storage.EventChange{
+		ID:        s.changeSeq,
+		EventID:   after.ID,
+		Operation: op,
+		ActorID:   storage.Actor(ctx, *after),
+		ChangedAt: after.UpdatedAt,
+	}
+	if before != nil {
+		e := cloneEvent(*before)
+		c.Before = &e
+	}
+	e := cloneEvent(*after)
+	c.After = &e
+	s.history[c.EventID] = append(s.history[c.EventID], c)
+}
+
+func cloneChange(c storage.EventChange) storage.EventChange {
+	if c.Before != nil {
+		e := cloneEvent(*c.Before)
+		c.Before = &e
+	}
+	if c.After != nil {
+		e := cloneEvent(*c.After)
+		c.After = &e
+	}
+	return c
+}
+
+// Copies slices to not share them with callers.
+func cloneEvent(e storage.Event) storage.Event {
+	if e.Tags != nil {
+		e.Tags = append(make([]string, 0, len(e.Tags)), e.Tags...)
+	}
+	if e.ResourceIDs != nil {
+		e.ResourceIDs = append(make([]string, 0, len(e.ResourceIDs)), e.ResourceIDs...)
+	}
+	if e.DeletedAt != nil {
+		deletedAt := *e.DeletedAt
+		e.DeletedAt = &deletedAt
+	}
+	return e
 }
 
 func (s *Storage) nextID() string {
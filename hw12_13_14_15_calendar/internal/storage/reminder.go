@@ -0,0 +1,46 @@
+package storage
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrNoReminder = errors.New("event has no reminder")
+
+// Reminder is a notification about event due at RemindAt.
+type Reminder struct {
+	Event    Event
+	RemindAt time.Time
+}
+
+// ReminderState is a reaction of user on the event reminder, it is bound to reminder time
+// so changing event start or notification period makes a new reminder.
+type ReminderState struct {
+	EventID string
+	// Original time of the reminder.
+	RemindAt     time.Time
+	Acknowledged bool
+	// Zero if reminder is not snoozed.
+	SnoozedUntil time.Time
+}
+
+// HasReminder tells whether owner is notified about the event.
+func (e Event) HasReminder() bool {
+	return e.NotifyBefore > 0
+}
+
+// RemindAt returns time of the event reminder, NotifyBefore is a number of days before start.
+func (e Event) RemindAt() time.Time {
+	return e.StartTime.AddDate(0, 0, -int(e.NotifyBefore))
+}
+
+// DueAt returns time the reminder is due at, false is returned for acknowledged reminder.
+func (s ReminderState) DueAt() (time.Time, bool) {
+	switch {
+	case s.Acknowledged:
+		return time.Time{}, false
+	case !s.SnoozedUntil.IsZero():
+		return s.SnoozedUntil, true
+	}
+	return s.RemindAt, true
+}
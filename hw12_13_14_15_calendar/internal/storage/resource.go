@@ -0,0 +1,99 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+var (
+	ErrNotFoundResource = errors.New("resource not found")
+	ErrResourceBusy     = errors.New("resource is booked by another event")
+)
+
+type ResourceType string
+
+const (
+	ResourceRoom      ResourceType = "room"
+	ResourceEquipment ResourceType = "equipment"
+)
+
+// Resource is a meeting room or equipment booked by events, it is shared by all users.
+type Resource struct {
+	ID   string       `json:"id"`
+	Name string       `json:"name" validate:"required|maxlen:255"`
+	Type ResourceType `json:"type" validate:"in:room,equipment"`
+	// Number of people the room fits, zero if it does not matter.
+	Capacity int32 `json:"capacity" validate:"min:0"`
+}
+
+// ResourceFilter narrows resource list. Zero value matches all resources.
+type ResourceFilter struct {
+	Type        ResourceType
+	MinCapacity int32
+}
+
+func (f ResourceFilter) Match(r Resource) bool {
+	return (f.Type == "" || f.Type == r.Type) && r.Capacity >= f.MinCapacity
+}
+
+// Booking is a time of the resource taken by the event.
+type Booking struct {
+	EventID string
+	Interval
+}
+
+// NormalizeResources sorts resources of the event and removes duplicates.
+func NormalizeResources(e *Event) {
+	if len(e.ResourceIDs) == 0 {
+		e.ResourceIDs = nil
+		return
+	}
+	ids := append(make([]string, 0, len(e.ResourceIDs)), e.ResourceIDs...)
+	sort.Strings(ids)
+	unique := ids[:1]
+	for _, id := range ids[1:] {
+		if id != unique[len(unique)-1] {
+			unique = append(unique, id)
+		}
+	}
+	e.ResourceIDs = unique
+}
+
+// Conflicts tells whether events overlap, all-day event is compared with bounds of other event in its location.
+func (e Event) Conflicts(other Event) bool {
+	if other.AllDay {
+		return other.Overlaps(e.StartTime, e.EndTime)
+	}
+	return e.Overlaps(other.StartTime, other.EndTime)
+}
+
+// CheckBookings returns ErrResourceBusy if any resource of the event is booked by other active event
+// overlapping it. Event itself is skipped in others.
+func CheckBookings(e Event, others []Event) error {
+	for _, o := range others {
+		if o.ID == e.ID || o.DeletedAt != nil || !e.Conflicts(o) {
+			continue
+		}
+		for _, id := range e.ResourceIDs {
+			if hasTag(o.ResourceIDs, id) {
+				return fmt.Errorf("resource %q is booked by event %q: %w", id, o.ID, ErrResourceBusy)
+			}
+		}
+	}
+	return nil
+}
+
+// BookingsOf returns bookings of the resource by active events overlapping [from:to) ordered by start.
+func BookingsOf(resourceID string, events []Event, from time.Time, to time.Time) []Booking {
+	bookings := make([]Booking, 0)
+	for _, e := range events {
+		if e.DeletedAt != nil || !hasTag(e.ResourceIDs, resourceID) || !e.Overlaps(from, to) {
+			continue
+		}
+		bookings = append(bookings, Booking{EventID: e.ID, Interval: Interval{Start: e.StartTime, End: e.EndTime}})
+	}
+	sort.SliceStable(bookings, func(i, j int) bool { return bookings[i].Start.Before(bookings[j].Start) })
+	return bookings
+}
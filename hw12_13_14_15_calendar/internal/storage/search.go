@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"strings"
+	"time"
+	"unicode"
+)
+
+// SearchQuery is a full-text search request.
+// Text consists of words that all must be found, "quoted phrases" match words going one after another,
+// word ending with * matches words with such prefix.
+type SearchQuery struct {
+	Text    string
+	OwnerID string
+	// Events starting in [From:To), zero time means no bound.
+	From time.Time
+	To   time.Time
+	// Zero means no limit.
+	Limit int
+}
+
+// SearchTerm is a word or phrase of search query.
+type SearchTerm struct {
+	Words []string
+	// Last word is a prefix.
+	Prefix bool
+}
+
+// ParseSearchQuery splits query text into terms, words are normalized with SearchWords.
+func ParseSearchQuery(text string) []SearchTerm {
+	var terms []SearchTerm
+	for text = strings.TrimSpace(text); text != ""; text = strings.TrimSpace(text) {
+		var chunk string
+		if text[0] == '"' {
+			end := strings.IndexByte(text[1:], '"')
+			if end < 0 {
+				chunk, text = text[1:], ""
+			} else {
+				chunk, text = text[1:end+1], text[end+2:]
+			}
+		} else {
+			end := strings.IndexFunc(text, unicode.IsSpace)
+			if end < 0 {
+				end = len(text)
+			}
+			chunk, text = text[:end], text[end:]
+		}
+
+		words := SearchWords(chunk)
+		if len(words) == 0 {
+			continue
+		}
+		terms = append(terms, SearchTerm{Words: words, Prefix: strings.HasSuffix(strings.TrimSpace(chunk), "*")})
+	}
+	return terms
+}
+
+// SearchWords splits text into lower case words of letters and digits.
+func SearchWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
@@ -0,0 +1,136 @@
+package sqlstorage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/storage"
+)
+
+const attachmentColumns = "id, event_id AS eventId, name, content_type AS contentType, size, url, " +
+	"blob_key AS blobKey, created_at AS createdAt"
+
+func (s *Storage) AddAttachment(ctx context.Context, a *storage.Attachment) error {
+	return s.withTx(ctx, func(tx *sqlx.Tx) error {
+		if err := checkAttachmentAccess(ctx, tx, a.EventID, storage.AccessWrite); err != nil {
+			return fmt.Errorf("failed to add attachment: %w", err)
+		}
+		a.CreatedAt = s.clock.Now().UTC()
+		return tx.GetContext(
+			ctx,
+			&a.ID,
+			"INSERT INTO attachments(event_id, name, content_type, size, url, blob_key, created_at) "+
+				"VALUES($1, $2, $3, $4, $5, $6, $7) RETURNING id",
+			a.EventID,
+			a.Name,
+			a.ContentType,
+			a.Size,
+			a.URL,
+			a.BlobKey,
+			a.CreatedAt,
+		)
+	})
+}
+
+func (s *Storage) CheckAttachmentWrite(ctx context.Context, eventID string) error {
+	if err := checkAttachmentAccess(ctx, s.db, eventID, storage.AccessWrite); err != nil {
+		return fmt.Errorf("failed to add attachment: %w", err)
+	}
+	return nil
+}
+
+func (s *Storage) GetAttachments(ctx context.Context, eventID string) ([]storage.Attachment, error) {
+	if err := checkAttachmentAccess(ctx, s.db, eventID, storage.AccessRead); err != nil {
+		return nil, fmt.Errorf("failed to get attachments: %w", err)
+	}
+	attachments := make([]storage.Attachment, 0)
+	err := s.db.SelectContext(
+		ctx,
+		&attachments,
+		"SELECT "+attachmentColumns+" FROM attachments WHERE event_id=$1 ORDER BY created_at, id",
+		eventID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return attachments, nil
+}
+
+func (s *Storage) GetAttachment(ctx context.Context, id string) (storage.Attachment, error) {
+	a, err := getAttachment(ctx, s.db, id)
+	if err != nil {
+		return storage.Attachment{}, err
+	}
+	if err := checkAttachmentAccess(ctx, s.db, a.EventID, storage.AccessRead); err != nil {
+		return storage.Attachment{}, fmt.Errorf("failed to get attachment: %w", err)
+	}
+	return a, nil
+}
+
+func (s *Storage) RemoveAttachment(ctx context.Context, id string) error {
+	return s.withTx(ctx, func(tx *sqlx.Tx) error {
+		a, err := getAttachment(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if err := checkAttachmentAccess(ctx, tx, a.EventID, storage.AccessWrite); err != nil {
+			return fmt.Errorf("failed to remove attachment: %w", err)
+		}
+		_, err = tx.ExecContext(ctx, "DELETE FROM attachments WHERE id=$1", id)
+		return err
+	})
+}
+
+func (s *Storage) TakeOrphanBlobs(ctx context.Context, limit int) ([]string, error) {
+	keys := make([]string, 0)
+	err := s.db.SelectContext(
+		ctx,
+		&keys,
+		"DELETE FROM orphan_blobs WHERE blob_key IN "+
+			"(SELECT blob_key FROM orphan_blobs ORDER BY removed_at LIMIT $1 FOR UPDATE SKIP LOCKED) RETURNING blob_key",
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// Keeps content keys of attachments of events selected by the query with $2 argument before the events are removed.
+func (s *Storage) keepOrphanBlobs(ctx context.Context, tx *sqlx.Tx, eventsQuery string, arg interface{}) error {
+	_, err := tx.ExecContext(
+		ctx,
+		"INSERT INTO orphan_blobs(blob_key, removed_at) SELECT blob_key, $1 FROM attachments "+
+			"WHERE blob_key <> '' AND event_id IN ("+eventsQuery+") ON CONFLICT DO NOTHING",
+		s.clock.Now().UTC(),
+		arg,
+	)
+	return err
+}
+
+func getAttachment(ctx context.Context, q sqlx.QueryerContext, id string) (storage.Attachment, error) {
+	var a storage.Attachment
+	err := sqlx.GetContext(ctx, q, &a, "SELECT "+attachmentColumns+" FROM attachments WHERE id=$1", id)
+	if isNoRows(err) {
+		return storage.Attachment{}, fmt.Errorf("attachment with id %q: %w", id, storage.ErrNotFoundAttachment)
+	}
+	return a, err
+}
+
+// Checks access to active event of the attachment.
+func checkAttachmentAccess(
+	ctx context.Context,
+	q sqlx.QueryerContext,
+	eventID string,
+	required storage.Access,
+) error {
+	e, err := getEvent(ctx, q, "SELECT "+eventColumns+" FROM Events WHERE id=$1 AND deleted_at IS NULL", eventID)
+	if isNoRows(err) {
+		return fmt.Errorf("event with id %q: %w", eventID, storage.ErrNotFoundEvent)
+	}
+	if err != nil {
+		return err
+	}
+	return checkEventAccess(ctx, q, e, required)
+}
@@ -0,0 +1,492 @@
+package sqlstorage
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/storage"
+)
+
+// Rows per multi-row statement, keeps number of parameters below postgres limit.
+const batchChunkSize = 500
+
+func (s *Storage) AddEvents(
+	ctx context.Context,
+	events []storage.Event,
+	opts storage.BatchOptions,
+) ([]storage.BatchResult, error) {
+	events = normalizeEvents(events)
+	results := make([]storage.BatchResult, len(events))
+	ids := make([]string, len(events))
+	seen := make(map[string]bool, len(events))
+	for i, e := range events {
+		if e.ID == "" {
+			continue
+		}
+		id, ok := parseUUID(e.ID)
+		if !ok {
+			results[i].Err = fmt.Errorf("incorrect ID %q: %w", e.ID, storage.ErrIncorrectEventID)
+			continue
+		}
+		if seen[id] {
+			results[i].Err = fmt.Errorf("duplicate ID %q in batch: %w", e.ID, storage.ErrDuplicateEventID)
+			continue
+		}
+		seen[id] = true
+		ids[i] = id
+	}
+
+	return s.withBatchTx(ctx, results, opts, func(tx *sqlx.Tx) error {
+		if err := resolveIDs(ctx, tx, results, ids); err != nil {
+			return err
+		}
+		if err := checkCalendarsAccess(ctx, tx, results, events); err != nil {
+			return err
+		}
+		if err := checkBatchBookings(ctx, tx, results, events, ids); err != nil {
+			return err
+		}
+
+		now := s.clock.Now().UTC()
+		var added []storage.Event
+		err := inChunks(pending(results), func(idx []int) error {
+			query := strings.Builder{}
+			query.WriteString("INSERT INTO Events(id, title, start_timestamp, end_timestamp, description, " +
+				"notify_before, owner_id, category, tags, color, calendar_id, transparency, all_day, resource_ids, version, " +
+				"updated_at) VALUES ")
+			args := make([]interface{}, 0, len(idx)*14+1)
+			args = append(args, now)
+			for n, i := range idx {
+				if n > 0 {
+					query.WriteString(", ")
+				}
+				e := events[i]
+				query.WriteString(placeholders(
+					len(args)+1, 14, "uuid", "", "", "", "", "", "", "", "", "", "uuid", "", "", "uuid[]"))
+				query.WriteString(", 1, $1)")
+				args = append(args, ids[i], e.Title, e.StartTime.UTC(), e.EndTime.UTC(), e.Description,
+					e.NotifyBefore, e.OwnerID, e.Category, tagsValue(e.Tags), e.Color, nullString(e.CalendarID),
+					string(e.Transparency), e.AllDay, tagsValue(e.ResourceIDs))
+			}
+			// Events added concurrently after IDs check are skipped and reported as duplicates.
+			query.WriteString(" ON CONFLICT (id) DO NOTHING RETURNING " + eventColumns)
+
+			stored, err := selectTx(ctx, tx, query.String(), args...)
+			if err != nil {
+				return err
+			}
+			byID := eventsByID(stored)
+			for _, i := range idx {
+				e, ok := byID[ids[i]]
+				if !ok {
+					results[i].Err = fmt.Errorf("duplicate ID %q: %w", ids[i], storage.ErrDuplicateEventID)
+					continue
+				}
+				results[i].Event = e
+				added = append(added, e)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		return addChanges(ctx, tx, storage.OperationCreate, make([]*storage.Event, len(added)), added)
+	})
+}
+
+func (s *Storage) UpdateEvents(
+	ctx context.Context,
+	events []storage.Event,
+	opts storage.BatchOptions,
+) ([]storage.BatchResult, error) {
+	events = normalizeEvents(events)
+	results := make([]storage.BatchResult, len(events))
+	ids := checkBatchIDs(results, len(events), func(i int) string { return events[i].ID })
+
+	return s.withBatchTx(ctx, results, opts, func(tx *sqlx.Tx) error {
+		before, err := lockEvents(ctx, tx, results, ids, func(i int) int64 { return events[i].Version })
+		if err != nil {
+			return err
+		}
+		// Events keep their calendars if other one is not set.
+		for _, i := range pending(results) {
+			if events[i].CalendarID == "" {
+				events[i].CalendarID = before[ids[i]].CalendarID
+			}
+		}
+		if err := checkCalendarsAccess(ctx, tx, results, events); err != nil {
+			return err
+		}
+		if err := checkBatchBookings(ctx, tx, results, events, ids); err != nil {
+			return err
+		}
+
+		now := s.clock.Now().UTC()
+		var befores []*storage.Event
+		var afters []storage.Event
+		err = inChunks(pending(results), func(idx []int) error {
+			query := strings.Builder{}
+			query.WriteString("UPDATE Events SET title=v.new_title, start_timestamp=v.new_start, " +
+				"end_timestamp=v.new_end, description=v.new_description, notify_before=v.new_notify_before, " +
+				"category=v.new_category, tags=v.new_tags, color=v.new_color, calendar_id=v.new_calendar_id, " +
+				"transparency=v.new_transparency, all_day=v.new_all_day, resource_ids=v.new_resource_ids, " +
+				"version=version+1, updated_at=$1 FROM (VALUES ")
+			args := make([]interface{}, 0, len(idx)*13+1)
+			args = append(args, now)
+			for n, i := range idx {
+				if n > 0 {
+					query.WriteString(", ")
+				}
+				e := events[i]
+				query.WriteString(placeholders(
+					len(args)+1, 13, "uuid", "varchar", "timestamp", "timestamp", "varchar", "int8", "varchar", "text[]",
+					"varchar", "uuid", "varchar", "bool", "uuid[]"))
+				query.WriteString(")")
+				args = append(args, ids[i], e.Title, e.StartTime.UTC(), e.EndTime.UTC(), e.Description,
+					e.NotifyBefore, e.Category, tagsValue(e.Tags), e.Color, nullString(e.CalendarID), string(e.Transparency),
+					e.AllDay, tagsValue(e.ResourceIDs))
+			}
+			query.WriteString(") AS v(new_id, new_title, new_start, new_end, new_description, new_notify_before, " +
+				"new_category, new_tags, new_color, new_calendar_id, new_transparency, new_all_day, new_resource_ids) " +
+				"WHERE id=v.new_id RETURNING " + eventColumns)
+
+			stored, err := selectTx(ctx, tx, query.String(), args...)
+			if err != nil {
+				return err
+			}
+			byID := eventsByID(stored)
+			for _, i := range idx {
+				b, after := before[ids[i]], byID[ids[i]]
+				results[i].Event = after
+				befores = append(befores, &b)
+				afters = append(afters, after)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		return addChanges(ctx, tx, storage.OperationUpdate, befores, afters)
+	})
+}
+
+func (s *Storage) RemoveEvents(
+	ctx context.Context,
+	refs []storage.EventRef,
+	opts storage.BatchOptions,
+) ([]storage.BatchResult, error) {
+	results := make([]storage.BatchResult, len(refs))
+	ids := checkBatchIDs(results, len(refs), func(i int) string { return refs[i].ID })
+
+	return s.withBatchTx(ctx, results, opts, func(tx *sqlx.Tx) error {
+		before, err := lockEvents(ctx, tx, results, ids, func(i int) int64 { return refs[i].Version })
+		if err != nil {
+			return err
+		}
+
+		now := s.clock.Now().UTC()
+		var befores []*storage.Event
+		var afters []storage.Event
+		err = inChunks(pending(results), func(idx []int) error {
+			chunkIDs := make([]string, 0, len(idx))
+			for _, i := range idx {
+				chunkIDs = append(chunkIDs, ids[i])
+			}
+			stored, err := selectTx(
+				ctx,
+				tx,
+				"UPDATE Events SET deleted_at=$2, version=version+1, updated_at=$2 WHERE id = ANY($1::uuid[]) "+
+					"RETURNING "+eventColumns,
+				pq.StringArray(chunkIDs),
+				now,
+			)
+			if err != nil {
+				return err
+			}
+			byID := eventsByID(stored)
+			for _, i := range idx {
+				b, after := before[ids[i]], byID[ids[i]]
+				results[i].Event = after
+				befores = append(befores, &b)
+				afters = append(afters, after)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		return addChanges(ctx, tx, storage.OperationDelete, befores, afters)
+	})
+}
+
+// Runs fn in transaction if batch has items to save, all-or-nothing batch is rolled back on any failed item.
+func (s *Storage) withBatchTx(
+	ctx context.Context,
+	results []storage.BatchResult,
+	opts storage.BatchOptions,
+	fn func(tx *sqlx.Tx) error,
+) ([]storage.BatchResult, error) {
+	failed := func() bool { return opts.AllOrNothing && storage.BatchFailed(results) }
+	if failed() || len(pending(results)) == 0 {
+		return batchResults(results, opts)
+	}
+
+	err := s.withTx(ctx, func(tx *sqlx.Tx) error {
+		if err := fn(tx); err != nil {
+			return err
+		}
+		if failed() {
+			return storage.ErrBatchRolledBack
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, storage.ErrBatchRolledBack) {
+		return nil, err
+	}
+	return batchResults(results, opts)
+}
+
+func batchResults(
+	results []storage.BatchResult,
+	opts storage.BatchOptions,
+) ([]storage.BatchResult, error) {
+	if opts.AllOrNothing && storage.BatchFailed(results) {
+		storage.RollbackBatch(results)
+		return results, storage.ErrBatchRolledBack
+	}
+	return results, nil
+}
+
+// Checks existence of given IDs and generates new ones for empty IDs.
+func resolveIDs(ctx context.Context, tx *sqlx.Tx, results []storage.BatchResult, ids []string) error {
+	var explicit []string
+	generate := 0
+	for _, i := range pending(results) {
+		if ids[i] == "" {
+			generate++
+			continue
+		}
+		explicit = append(explicit, ids[i])
+	}
+
+	var existing []string
+	err := tx.SelectContext(
+		ctx,
+		&existing,
+		"SELECT id FROM Events WHERE id = ANY($1::uuid[])",
+		pq.StringArray(explicit),
+	)
+	if err != nil {
+		return err
+	}
+	exists := make(map[string]bool, len(existing))
+	for _, id := range existing {
+		exists[id] = true
+	}
+
+	var generated []string
+	err = tx.SelectContext(ctx, &generated, "SELECT uuid_generate_v4() FROM generate_series(1, $1)", generate)
+	if err != nil {
+		return err
+	}
+	for _, i := range pending(results) {
+		switch {
+		case ids[i] == "":
+			ids[i], generated = generated[0], generated[1:]
+		case exists[ids[i]]:
+			results[i].Err = fmt.Errorf("duplicate ID %q: %w", ids[i], storage.ErrDuplicateEventID)
+		}
+	}
+	return nil
+}
+
+// Checks write access to calendars events are added or moved to.
+func checkCalendarsAccess(
+	ctx context.Context,
+	tx *sqlx.Tx,
+	results []storage.BatchResult,
+	events []storage.Event,
+) error {
+	idx := pending(results)
+	calendarIDs := make([]string, 0, len(idx))
+	for _, i := range idx {
+		calendarIDs = append(calendarIDs, events[i].CalendarID)
+	}
+	accesses, err := calendarAccesses(ctx, tx, calendarIDs)
+	if err != nil {
+		return err
+	}
+	for _, i := range idx {
+		calendarID := events[i].CalendarID
+		results[i].Err = storage.CheckAccess(calendarID, accessOf(accesses, calendarID), storage.AccessWrite)
+	}
+	return nil
+}
+
+// Returns normalized IDs, malformed IDs are reported as not found.
+func checkBatchIDs(results []storage.BatchResult, n int, idOf func(i int) string) []string {
+	ids := make([]string, n)
+	seen := make(map[string]bool, n)
+	for i := range ids {
+		id, ok := parseUUID(idOf(i))
+		if !ok {
+			results[i].Err = fmt.Errorf("event with id %q: %w", idOf(i), storage.ErrNotFoundEvent)
+			continue
+		}
+		if seen[id] {
+			results[i].Err = fmt.Errorf("duplicate ID %q in batch: %w", idOf(i), storage.ErrDuplicateEventID)
+			continue
+		}
+		seen[id] = true
+		ids[i] = id
+	}
+	return ids
+}
+
+// Selects active events for update and checks write access and versions (if not zero).
+func lockEvents(
+	ctx context.Context,
+	tx *sqlx.Tx,
+	results []storage.BatchResult,
+	ids []string,
+	versionOf func(i int) int64,
+) (map[string]storage.Event, error) {
+	idx := pending(results)
+	lockIDs := make([]string, 0, len(idx))
+	for _, i := range idx {
+		lockIDs = append(lockIDs, ids[i])
+	}
+	stored, err := selectTx(
+		ctx,
+		tx,
+		"SELECT "+eventColumns+" FROM Events WHERE id = ANY($1::uuid[]) AND deleted_at IS NULL FOR UPDATE",
+		pq.StringArray(lockIDs),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	calendarIDs := make([]string, 0, len(stored))
+	for _, e := range stored {
+		calendarIDs = append(calendarIDs, e.CalendarID)
+	}
+	accesses, err := calendarAccesses(ctx, tx, calendarIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := eventsByID(stored)
+	for _, i := range idx {
+		e, ok := byID[ids[i]]
+		if !ok {
+			results[i].Err = fmt.Errorf("event with id %q: %w", ids[i], storage.ErrNotFoundEvent)
+			continue
+		}
+		if err := storage.CheckEventAccess(e, accessOf(accesses, e.CalendarID), storage.AccessWrite); err != nil {
+			results[i].Err = err
+			continue
+		}
+		if versionOf(i) != 0 && versionOf(i) != e.Version {
+			results[i].Err = fmt.Errorf("event with id %q: %w", ids[i], storage.ErrVersionConflict)
+		}
+	}
+	return byID, nil
+}
+
+// Returns copy of events with normalized all-day bounds and resources.
+func normalizeEvents(events []storage.Event) []storage.Event {
+	normalized := make([]storage.Event, len(events))
+	for i, e := range events {
+		storage.NormalizeAllDay(&e)
+		storage.NormalizeResources(&e)
+		normalized[i] = e
+	}
+	return normalized
+}
+
+func selectTx(ctx context.Context, tx *sqlx.Tx, query string, args ...interface{}) ([]storage.Event, error) {
+	var rows []event
+	if err := tx.SelectContext(ctx, &rows, query, args...); err != nil {
+		return nil, err
+	}
+	events := make([]storage.Event, 0, len(rows))
+	for _, row := range rows {
+		events = append(events, row.toEvent())
+	}
+	return events, nil
+}
+
+func eventsByID(events []storage.Event) map[string]storage.Event {
+	byID := make(map[string]storage.Event, len(events))
+	for _, e := range events {
+		byID[e.ID] = e
+	}
+	return byID
+}
+
+func nullString(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// Returns indexes of items without errors.
+func pending(results []storage.BatchResult) []int {
+	idx := make([]int, 0, len(results))
+	for i, r := range results {
+		if r.Err == nil {
+			idx = append(idx, i)
+		}
+	}
+	return idx
+}
+
+func inChunks(idx []int, fn func(idx []int) error) error {
+	for len(idx) > 0 {
+		n := batchChunkSize
+		if n > len(idx) {
+			n = len(idx)
+		}
+		if err := fn(idx[:n]); err != nil {
+			return err
+		}
+		idx = idx[n:]
+	}
+	return nil
+}
+
+// Returns "($first::cast, $next, ..." for count of parameters, closing bracket is added by caller.
+func placeholders(first int, count int, casts ...string) string {
+	b := strings.Builder{}
+	b.WriteString("(")
+	for i := 0; i < count; i++ {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString("$" + strconv.Itoa(first+i))
+		if i < len(casts) && casts[i] != "" {
+			b.WriteString("::" + casts[i])
+		}
+	}
+	return b.String()
+}
+
+// Returns UUID in canonical form, accepts forms supported by postgres.
+func parseUUID(id string) (string, bool) {
+	if strings.HasPrefix(id, "{") && strings.HasSuffix(id, "}") {
+		id = id[1 : len(id)-1]
+	}
+	raw, err := hex.DecodeString(strings.ReplaceAll(id, "-", ""))
+	if err != nil || len(raw) != 16 {
+		return "", false
+	}
+	h := hex.EncodeToString(raw)
+	return h[:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:], true
+}
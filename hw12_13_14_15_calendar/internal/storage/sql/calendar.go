@@ -0,0 +1,239 @@
+package sqlstorage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/storage"
+)
+
+const calendarColumns = "id, name, owner_id AS ownerId, time_zone AS timeZone"
+
+func (s *Storage) CreateCalendar(ctx context.Context, c *storage.Calendar) error {
+	err := s.db.GetContext(
+		ctx,
+		&c.ID,
+		"INSERT INTO calendars(name, owner_id, time_zone) VALUES($1, $2, $3) RETURNING id",
+		c.Name,
+		c.OwnerID,
+		c.TimeZone,
+	)
+	if err != nil {
+		return err
+	}
+	c.Access = storage.AccessAdmin
+	return nil
+}
+
+func (s *Storage) UpdateCalendar(ctx context.Context, c *storage.Calendar) error {
+	return s.withTx(ctx, func(tx *sqlx.Tx) error {
+		access, err := calendarAccess(ctx, tx, c.ID)
+		if err != nil {
+			return err
+		}
+		if err := storage.CheckCalendarAccess(c.ID, access, storage.AccessAdmin); err != nil {
+			return err
+		}
+		err = tx.GetContext(
+			ctx,
+			c,
+			"UPDATE calendars SET name=$2, time_zone=$3 WHERE id=$1 RETURNING "+calendarColumns,
+			c.ID,
+			c.Name,
+			c.TimeZone,
+		)
+		if err != nil {
+			return err
+		}
+		c.Access = access
+		return nil
+	})
+}
+
+func (s *Storage) RemoveCalendar(ctx context.Context, id string) error {
+	return s.withTx(ctx, func(tx *sqlx.Tx) error {
+		if err := checkCalendarAccess(ctx, tx, id); err != nil {
+			return err
+		}
+		// Events of the calendar and their attachments are removed by foreign key.
+		if err := s.keepOrphanBlobs(ctx, tx, "SELECT id FROM Events WHERE calendar_id=$2", id); err != nil {
+			return err
+		}
+		res, err := tx.ExecContext(
+			ctx,
+			"DELETE FROM calendars WHERE id=$1 AND owner_id=$2",
+			id,
+			storage.ActorFromContext(ctx),
+		)
+		if err != nil {
+			return err
+		}
+		if n, err := res.RowsAffected(); err != nil || n == 0 {
+			return fmt.Errorf("calendar with id %q can be removed by owner only: %w", id, storage.ErrAccessDenied)
+		}
+		return nil
+	})
+}
+
+func (s *Storage) GetCalendars(ctx context.Context) ([]storage.Calendar, error) {
+	calendars := make([]storage.Calendar, 0)
+	err := s.db.SelectContext(
+		ctx,
+		&calendars,
+		"SELECT c.id, c.name, c.owner_id AS ownerId, c.time_zone AS timeZone, "+
+			"CASE WHEN c.owner_id = $1 THEN 'admin' ELSE g.access END AS access "+
+			"FROM calendars c LEFT JOIN calendar_grants g ON g.calendar_id = c.id AND g.user_id = $1 "+
+			"WHERE c.owner_id = $1 OR g.user_id IS NOT NULL ORDER BY c.name, c.id",
+		storage.ActorFromContext(ctx),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return calendars, nil
+}
+
+func (s *Storage) ShareCalendar(ctx context.Context, g storage.Grant) error {
+	if !g.Access.Valid() {
+		return fmt.Errorf("access %q: %w", g.Access, storage.ErrIncorrectAccess)
+	}
+	return s.withTx(ctx, func(tx *sqlx.Tx) error {
+		if err := checkCalendarAccess(ctx, tx, g.CalendarID); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(
+			ctx,
+			"INSERT INTO calendar_grants(calendar_id, user_id, access) VALUES($1, $2, $3) "+
+				"ON CONFLICT (calendar_id, user_id) DO UPDATE SET access = EXCLUDED.access",
+			g.CalendarID,
+			g.UserID,
+			string(g.Access),
+		)
+		return err
+	})
+}
+
+func (s *Storage) RevokeCalendarAccess(ctx context.Context, calendarID string, userID string) error {
+	return s.withTx(ctx, func(tx *sqlx.Tx) error {
+		if err := checkCalendarAccess(ctx, tx, calendarID); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(
+			ctx,
+			"DELETE FROM calendar_grants WHERE calendar_id=$1 AND user_id=$2",
+			calendarID,
+			userID,
+		)
+		return err
+	})
+}
+
+func (s *Storage) GetCalendarGrants(ctx context.Context, calendarID string) ([]storage.Grant, error) {
+	if err := checkCalendarAccess(ctx, s.db, calendarID); err != nil {
+		return nil, err
+	}
+	grants := make([]storage.Grant, 0)
+	err := s.db.SelectContext(
+		ctx,
+		&grants,
+		"SELECT calendar_id AS calendarId, user_id AS userId, access FROM calendar_grants "+
+			"WHERE calendar_id=$1 ORDER BY user_id",
+		calendarID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return grants, nil
+}
+
+// Condition on events of calendars accessible for user passed as parameter argN.
+func accessibleCalendars(argN int) string {
+	return fmt.Sprintf(
+		"(calendar_id IS NULL OR calendar_id IN (SELECT id FROM calendars WHERE owner_id = $%[1]d "+
+			"UNION SELECT calendar_id FROM calendar_grants WHERE user_id = $%[1]d))",
+		argN,
+	)
+}
+
+// Returns access of user from context to calendars by IDs in canonical form, see accessOf.
+func calendarAccesses(
+	ctx context.Context,
+	q sqlx.QueryerContext,
+	calendarIDs []string,
+) (map[string]storage.Access, error) {
+	ids := make([]string, 0, len(calendarIDs))
+	for _, calendarID := range calendarIDs {
+		// Malformed IDs are left out, so calendars are not found.
+		if id, ok := parseUUID(calendarID); ok {
+			ids = append(ids, id)
+		}
+	}
+	accesses := make(map[string]storage.Access, len(ids))
+	if len(ids) == 0 {
+		return accesses, nil
+	}
+
+	var rows []struct {
+		ID     string
+		Access string
+	}
+	err := sqlx.SelectContext(
+		ctx,
+		q,
+		&rows,
+		"SELECT c.id, CASE WHEN c.owner_id = $2 THEN 'admin' ELSE COALESCE(g.access, '') END AS access "+
+			"FROM calendars c LEFT JOIN calendar_grants g ON g.calendar_id = c.id AND g.user_id = $2 "+
+			"WHERE c.id = ANY($1::uuid[])",
+		pq.StringArray(ids),
+		storage.ActorFromContext(ctx),
+	)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		accesses[row.ID] = storage.Access(row.Access)
+	}
+	return accesses, nil
+}
+
+func accessOf(accesses map[string]storage.Access, calendarID string) storage.Access {
+	id, _ := parseUUID(calendarID)
+	return accesses[id]
+}
+
+func calendarAccess(ctx context.Context, q sqlx.QueryerContext, calendarID string) (storage.Access, error) {
+	if calendarID == "" {
+		return storage.AccessNone, nil
+	}
+	accesses, err := calendarAccesses(ctx, q, []string{calendarID})
+	if err != nil {
+		return storage.AccessNone, err
+	}
+	return accessOf(accesses, calendarID), nil
+}
+
+func checkAccess(ctx context.Context, q sqlx.QueryerContext, calendarID string, required storage.Access) error {
+	access, err := calendarAccess(ctx, q, calendarID)
+	if err != nil {
+		return err
+	}
+	return storage.CheckAccess(calendarID, access, required)
+}
+
+// Checks admin access required to manage the calendar.
+func checkCalendarAccess(ctx context.Context, q sqlx.QueryerContext, calendarID string) error {
+	access, err := calendarAccess(ctx, q, calendarID)
+	if err != nil {
+		return err
+	}
+	return storage.CheckCalendarAccess(calendarID, access, storage.AccessAdmin)
+}
+
+func checkEventAccess(ctx context.Context, q sqlx.QueryerContext, e storage.Event, required storage.Access) error {
+	access, err := calendarAccess(ctx, q, e.CalendarID)
+	if err != nil {
+		return err
+	}
+	return storage.CheckEventAccess(e, access, required)
+}
@@ -0,0 +1,55 @@
+package sqlstorage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/storage"
+)
+
+func (s *Storage) SetFeedToken(ctx context.Context, userID string, tokenHash string) error {
+	_, err := s.db.ExecContext(
+		ctx,
+		"INSERT INTO feed_tokens(user_id, token_hash, created_at) VALUES($1, $2, $3) "+
+			"ON CONFLICT (user_id) DO UPDATE SET token_hash=EXCLUDED.token_hash, created_at=EXCLUDED.created_at",
+		userID,
+		tokenHash,
+		s.clock.Now().UTC(),
+	)
+	return err
+}
+
+func (s *Storage) RemoveFeedToken(ctx context.Context, userID string) error {
+	res, err := s.db.ExecContext(ctx, "DELETE FROM feed_tokens WHERE user_id=$1", userID)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return fmt.Errorf("failed to remove feed token of user %q: %w", userID, storage.ErrNotFoundFeedToken)
+	}
+	return nil
+}
+
+func (s *Storage) GetFeedUser(ctx context.Context, tokenHash string) (string, error) {
+	var userID string
+	err := s.db.GetContext(ctx, &userID, "SELECT user_id FROM feed_tokens WHERE token_hash=$1", tokenHash)
+	if isNoRows(err) {
+		return "", storage.ErrNotFoundFeedToken
+	}
+	return userID, err
+}
+
+func (s *Storage) GetFeedEvents(ctx context.Context, endAfter time.Time) ([]storage.Event, error) {
+	return s.selectEvents(
+		ctx,
+		"SELECT "+eventColumns+" FROM Events WHERE end_timestamp > $1 "+
+			"AND (calendar_id IS NOT NULL OR owner_id = $2) AND "+accessibleCalendars(2),
+		endAfter.UTC(),
+		storage.ActorFromContext(ctx),
+	)
+}
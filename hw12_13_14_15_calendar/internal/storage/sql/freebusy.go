@@ -0,0 +1,36 @@
+package sqlstorage
+
+import (
+	"context"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/storage"
+)
+
+func (s *Storage) GetFreeBusy(
+	ctx context.Context,
+	ownerIDs []string,
+	from time.Time,
+	to time.Time,
+) ([]storage.FreeBusy, error) {
+	if !to.After(from) {
+		return nil, storage.ErrIncorrectPeriod
+	}
+	events, err := s.selectEvents(
+		ctx,
+		"SELECT owner_id AS ownerId, start_timestamp AS startTime, end_timestamp AS endTime, all_day AS allDay "+
+			"FROM Events WHERE deleted_at IS NULL AND transparency <> $4 AND owner_id = ANY($1) "+
+			"AND "+overlapsRange(2, 3, 5, 6),
+		pq.StringArray(ownerIDs),
+		from.UTC(),
+		to.UTC(),
+		string(storage.TransparencyFree),
+		storage.Floating(from),
+		storage.Floating(to),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return storage.MergeBusy(ownerIDs, events, from, to), nil
+}
@@ -0,0 +1,141 @@
+package sqlstorage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/storage"
+)
+
+// Row of event_history table, event states are stored as JSON.
+type eventChange struct {
+	ID          int64
+	EventID     string
+	Operation   string
+	ActorID     string
+	BeforeState []byte
+	AfterState  []byte
+	ChangedAt   time.Time
+}
+
+func (s *Storage) GetEventHistory(ctx context.Context, eventID string) ([]storage.EventChange, error) {
+	e, err := getEvent(ctx, s.db, "SELECT "+eventColumns+" FROM Events WHERE id=$1", eventID)
+	if isNoRows(err) {
+		return nil, fmt.Errorf("failed to get history of event with id %q: %w", eventID, storage.ErrNotFoundEvent)
+	}
+	if err != nil {
+		return nil, err
+	}
+	access, err := calendarAccess(ctx, s.db, e.CalendarID)
+	if err != nil {
+		return nil, err
+	}
+	if storage.CheckEventAccess(e, access, storage.AccessRead) != nil {
+		return []storage.EventChange{}, nil
+	}
+
+	var rows []eventChange
+	err = s.db.SelectContext(
+		ctx,
+		&rows,
+		"SELECT id, event_id AS eventId, operation, actor_id AS actorId, before_state AS beforeState, "+
+			"after_state AS afterState, changed_at AS changedAt FROM event_history WHERE event_id=$1 ORDER BY id",
+		eventID,
+	)
+	if isNoRows(err) {
+		return []storage.EventChange{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make([]storage.EventChange, 0, len(rows))
+	for _, row := range rows {
+		c := storage.EventChange{
+			ID:        row.ID,
+			EventID:   row.EventID,
+			Operation: storage.Operation(row.Operation),
+			ActorID:   row.ActorID,
+			ChangedAt: row.ChangedAt,
+		}
+		if c.Before, err = fromSnapshot(row.BeforeState); err != nil {
+			return nil, fmt.Errorf("failed to parse change %d: %w", row.ID, err)
+		}
+		if c.After, err = fromSnapshot(row.AfterState); err != nil {
+			return nil, fmt.Errorf("failed to parse change %d: %w", row.ID, err)
+		}
+		changes = append(changes, c)
+	}
+	return changes, nil
+}
+
+func addChange(ctx context.Context, tx *sqlx.Tx, op storage.Operation, before, after *storage.Event) error {
+	return addChanges(ctx, tx, op, []*storage.Event{before}, []storage.Event{*after})
+}
+
+// Saves changes with multi-row insert, befores and afters are matched by index.
+func addChanges(
+	ctx context.Context,
+	tx *sqlx.Tx,
+	op storage.Operation,
+	befores []*storage.Event,
+	afters []storage.Event,
+) error {
+	idx := make([]int, len(afters))
+	for i := range idx {
+		idx[i] = i
+	}
+	return inChunks(idx, func(idx []int) error {
+		query := strings.Builder{}
+		query.WriteString("INSERT INTO event_history(event_id, operation, actor_id, before_state, after_state, " +
+			"changed_at) VALUES ")
+		args := make([]interface{}, 0, len(idx)*6)
+		for n, i := range idx {
+			beforeState, err := toSnapshot(befores[i])
+			if err != nil {
+				return err
+			}
+			afterState, err := toSnapshot(&afters[i])
+			if err != nil {
+				return err
+			}
+			if n > 0 {
+				query.WriteString(", ")
+			}
+			query.WriteString(placeholders(len(args)+1, 6) + ")")
+			args = append(args, afters[i].ID, string(op), storage.Actor(ctx, afters[i]), beforeState, afterState,
+				afters[i].UpdatedAt)
+		}
+		if _, err := tx.ExecContext(ctx, query.String(), args...); err != nil {
+			return fmt.Errorf("failed to save event change: %w", err)
+		}
+		return nil
+	})
+}
+
+// Returns JSON as string to pass it into jsonb column.
+func toSnapshot(e *storage.Event) (interface{}, error) {
+	if e == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal event: %w", err)
+	}
+	return string(data), nil
+}
+
+func fromSnapshot(data []byte) (*storage.Event, error) {
+	if data == nil {
+		return nil, nil
+	}
+	e := &storage.Event{}
+	if err := json.Unmarshal(data, e); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
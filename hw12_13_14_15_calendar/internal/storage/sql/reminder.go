@@ -0,0 +1,90 @@
+package sqlstorage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/storage"
+)
+
+// Time of current reminder of the event, see storage.Event.RemindAt.
+const remindAtExpr = "(start_timestamp - interval '1' day * notify_before)"
+
+type reminderRow struct {
+	event
+	RemindAt time.Time
+}
+
+func (s *Storage) GetReminders(
+	ctx context.Context,
+	startTime time.Time,
+	endTime time.Time,
+) ([]storage.Reminder, error) {
+	var rows []reminderRow
+	// State of previous reminders (with other time) is ignored.
+	err := s.db.SelectContext(
+		ctx,
+		&rows,
+		"SELECT "+eventColumns+", COALESCE(r.snoozed_until, "+remindAtExpr+") AS remindAt "+
+			"FROM Events LEFT JOIN reminders r ON r.event_id = id AND r.remind_at = "+remindAtExpr+" "+
+			"WHERE deleted_at IS NULL AND notify_before > 0 AND NOT COALESCE(r.acknowledged, false) "+
+			"AND COALESCE(r.snoozed_until, "+remindAtExpr+") >= $1 "+
+			"AND COALESCE(r.snoozed_until, "+remindAtExpr+") < $2",
+		startTime.UTC(),
+		endTime.UTC(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	reminders := make([]storage.Reminder, 0, len(rows))
+	for _, row := range rows {
+		reminders = append(reminders, storage.Reminder{Event: row.toEvent(), RemindAt: row.RemindAt})
+	}
+	return reminders, nil
+}
+
+func (s *Storage) AcknowledgeReminder(ctx context.Context, eventID string) error {
+	return s.setReminderState(ctx, eventID, true, time.Time{})
+}
+
+func (s *Storage) SnoozeReminder(ctx context.Context, eventID string, until time.Time) error {
+	return s.setReminderState(ctx, eventID, false, until)
+}
+
+// Sets state of current reminder of the event, state of previous reminder is replaced.
+func (s *Storage) setReminderState(
+	ctx context.Context,
+	eventID string,
+	acknowledged bool,
+	snoozedUntil time.Time,
+) error {
+	return s.withTx(ctx, func(tx *sqlx.Tx) error {
+		e, err := lockEvent(ctx, tx, eventID, false)
+		if isNoRows(err) {
+			return fmt.Errorf("failed to change reminder of event with id %q: %w", eventID, storage.ErrNotFoundEvent)
+		}
+		if err != nil {
+			return err
+		}
+		if err := checkEventAccess(ctx, tx, e, storage.AccessWrite); err != nil {
+			return fmt.Errorf("failed to change reminder: %w", err)
+		}
+		if !e.HasReminder() {
+			return fmt.Errorf("failed to change reminder of event with id %q: %w", eventID, storage.ErrNoReminder)
+		}
+
+		_, err = tx.ExecContext(
+			ctx,
+			"INSERT INTO reminders(event_id, remind_at, acknowledged, snoozed_until) VALUES($1, $2, $3, $4) "+
+				"ON CONFLICT (event_id) DO UPDATE SET remind_at = EXCLUDED.remind_at, "+
+				"acknowledged = EXCLUDED.acknowledged, snoozed_until = EXCLUDED.snoozed_until",
+			e.ID,
+			e.RemindAt().UTC(),
+			acknowledged,
+			nullTime(snoozedUntil),
+		)
+		return err
+	})
+}
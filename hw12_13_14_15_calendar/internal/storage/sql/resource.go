@@ -0,0 +1,227 @@
+package sqlstorage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/storage"
+)
+
+func (s *Storage) CreateResource(ctx context.Context, r *storage.Resource) error {
+	return s.db.GetContext(
+		ctx,
+		&r.ID,
+		"INSERT INTO resources(name, type, capacity) VALUES($1, $2, $3) RETURNING id",
+		r.Name,
+		string(r.Type),
+		r.Capacity,
+	)
+}
+
+func (s *Storage) RemoveResource(ctx context.Context, id string) error {
+	return s.withTx(ctx, func(tx *sqlx.Tx) error {
+		existing, err := lockResources(ctx, tx, []string{id})
+		if err != nil {
+			return err
+		}
+		canonical, ok := parseUUID(id)
+		if !ok || !existing[canonical] {
+			return fmt.Errorf("failed to remove resource with id %q: %w", id, storage.ErrNotFoundResource)
+		}
+		_, err = tx.ExecContext(
+			ctx,
+			"UPDATE Events SET resource_ids=array_remove(resource_ids, $1::uuid) WHERE resource_ids @> ARRAY[$1::uuid]",
+			canonical,
+		)
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, "DELETE FROM resources WHERE id=$1", canonical)
+		return err
+	})
+}
+
+func (s *Storage) GetResources(ctx context.Context, filter storage.ResourceFilter) ([]storage.Resource, error) {
+	resources := make([]storage.Resource, 0)
+	err := s.db.SelectContext(
+		ctx,
+		&resources,
+		"SELECT id, name, type, capacity FROM resources WHERE ($1 = '' OR type = $1) AND capacity >= $2 "+
+			"ORDER BY name, id",
+		string(filter.Type),
+		filter.MinCapacity,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return resources, nil
+}
+
+func (s *Storage) GetResourceSchedule(
+	ctx context.Context,
+	id string,
+	from time.Time,
+	to time.Time,
+) ([]storage.Booking, error) {
+	if !to.After(from) {
+		return nil, storage.ErrIncorrectPeriod
+	}
+	canonical, ok := parseUUID(id)
+	var exists bool
+	if ok {
+		err := s.db.GetContext(ctx, &exists, "SELECT EXISTS(SELECT 1 FROM resources WHERE id=$1)", canonical)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if !exists {
+		return nil, fmt.Errorf("failed to get schedule of resource with id %q: %w", id, storage.ErrNotFoundResource)
+	}
+	events, err := s.selectEvents(
+		ctx,
+		"SELECT "+eventColumns+" FROM Events WHERE deleted_at IS NULL AND resource_ids @> ARRAY[$1::uuid] AND "+
+			overlapsRange(2, 3, 4, 5),
+		canonical,
+		from.UTC(),
+		to.UTC(),
+		storage.Floating(from),
+		storage.Floating(to),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return storage.BookingsOf(canonical, events, from, to), nil
+}
+
+// Locks resources of the event and checks that they are not booked by other events,
+// IDs of resources are replaced with canonical ones.
+func checkBookings(ctx context.Context, tx *sqlx.Tx, e *storage.Event) error {
+	if len(e.ResourceIDs) == 0 {
+		return nil
+	}
+	existing, err := lockResources(ctx, tx, e.ResourceIDs)
+	if err != nil {
+		return err
+	}
+	if err := canonicalResources(e, existing); err != nil {
+		return err
+	}
+	others, err := bookedEvents(ctx, tx, *e, []string{e.ID})
+	if err != nil {
+		return err
+	}
+	return storage.CheckBookings(*e, others)
+}
+
+// Checks resources of batch events as if events were saved one by one: event can not book resource
+// booked by stored events or by events of the batch accepted before it.
+func checkBatchBookings(
+	ctx context.Context,
+	tx *sqlx.Tx,
+	results []storage.BatchResult,
+	events []storage.Event,
+	ids []string,
+) error {
+	var resourceIDs []string
+	for _, i := range pending(results) {
+		resourceIDs = append(resourceIDs, events[i].ResourceIDs...)
+	}
+	if len(resourceIDs) == 0 {
+		return nil
+	}
+	existing, err := lockResources(ctx, tx, resourceIDs)
+	if err != nil {
+		return err
+	}
+
+	var accepted []storage.Event
+	var acceptedIDs []string
+	for _, i := range pending(results) {
+		e := events[i]
+		e.ID = ids[i]
+		if len(e.ResourceIDs) == 0 {
+			continue
+		}
+		if err := canonicalResources(&e, existing); err != nil {
+			results[i].Err = err
+			continue
+		}
+		others, err := bookedEvents(ctx, tx, e, append(acceptedIDs, e.ID))
+		if err != nil {
+			return err
+		}
+		if err := storage.CheckBookings(e, append(others, accepted...)); err != nil {
+			results[i].Err = err
+			continue
+		}
+		events[i].ResourceIDs = e.ResourceIDs
+		accepted = append(accepted, e)
+		acceptedIDs = append(acceptedIDs, e.ID)
+	}
+	return nil
+}
+
+// Selects existing resources for update, returns set of their canonical IDs. Malformed IDs are skipped.
+func lockResources(ctx context.Context, tx *sqlx.Tx, ids []string) (map[string]bool, error) {
+	lockIDs := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if canonical, ok := parseUUID(id); ok {
+			lockIDs = append(lockIDs, canonical)
+		}
+	}
+	var locked []string
+	err := tx.SelectContext(
+		ctx,
+		&locked,
+		"SELECT id FROM resources WHERE id = ANY($1::uuid[]) ORDER BY id FOR UPDATE",
+		pq.StringArray(lockIDs),
+	)
+	if err != nil {
+		return nil, err
+	}
+	existing := make(map[string]bool, len(locked))
+	for _, id := range locked {
+		existing[id] = true
+	}
+	return existing, nil
+}
+
+// Replaces resources of the event with canonical IDs, fails if any of them does not exist.
+func canonicalResources(e *storage.Event, existing map[string]bool) error {
+	ids := make([]string, 0, len(e.ResourceIDs))
+	for _, id := range e.ResourceIDs {
+		canonical, ok := parseUUID(id)
+		if !ok || !existing[canonical] {
+			return fmt.Errorf("resource with id %q: %w", id, storage.ErrNotFoundResource)
+		}
+		ids = append(ids, canonical)
+	}
+	e.ResourceIDs = ids
+	storage.NormalizeResources(e)
+	return nil
+}
+
+// Selects active events booking any resource of the event in its time, excluded events are skipped.
+func bookedEvents(ctx context.Context, tx *sqlx.Tx, e storage.Event, excluded []string) ([]storage.Event, error) {
+	excludedIDs := make([]string, 0, len(excluded))
+	for _, id := range excluded {
+		if canonical, ok := parseUUID(id); ok {
+			excludedIDs = append(excludedIDs, canonical)
+		}
+	}
+	return selectTx(
+		ctx,
+		tx,
+		"SELECT "+eventColumns+" FROM Events WHERE deleted_at IS NULL AND resource_ids && $1::uuid[] "+
+			"AND NOT id = ANY($2::uuid[]) AND "+overlapsRange(3, 4, 5, 6),
+		pq.StringArray(e.ResourceIDs),
+		pq.StringArray(excludedIDs),
+		e.StartTime.UTC(),
+		e.EndTime.UTC(),
+		storage.Floating(e.StartTime),
+		storage.Floating(e.EndTime),
+	)
+}
@@ -0,0 +1,55 @@
+package sqlstorage
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/storage"
+)
+
+func (s *Storage) SearchEvents(ctx context.Context, query storage.SearchQuery) ([]storage.Event, error) {
+	terms := storage.ParseSearchQuery(query.Text)
+	if len(terms) == 0 {
+		return nil, storage.ErrEmptySearchQuery
+	}
+
+	var limit interface{}
+	if query.Limit > 0 {
+		limit = query.Limit
+	}
+	return s.selectEvents(
+		ctx,
+		"SELECT "+eventColumns+" FROM Events, to_tsquery('simple', $1) AS query "+
+			"WHERE deleted_at IS NULL AND search_vector @@ query AND ($2 = '' OR owner_id = $2) "+
+			"AND ($3::timestamp IS NULL OR start_timestamp >= $3) "+
+			"AND ($4::timestamp IS NULL OR start_timestamp < $4) AND "+accessibleCalendars(6)+
+			" ORDER BY ts_rank(search_vector, query) DESC, start_timestamp LIMIT $5",
+		toTSQuery(terms),
+		query.OwnerID,
+		nullTime(query.From),
+		nullTime(query.To),
+		limit,
+		storage.ActorFromContext(ctx),
+	)
+}
+
+// Words contain only letters and digits, so they are safe to use in tsquery.
+func toTSQuery(terms []storage.SearchTerm) string {
+	parts := make([]string, 0, len(terms))
+	for _, term := range terms {
+		phrase := strings.Join(term.Words, " <-> ")
+		if term.Prefix {
+			phrase += ":*"
+		}
+		parts = append(parts, "("+phrase+")")
+	}
+	return strings.Join(parts, " & ")
+}
+
+func nullTime(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+	return t.UTC()
+}
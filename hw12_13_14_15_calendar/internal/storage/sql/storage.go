@@ -2,12 +2,15 @@ package sqlstorage
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
 	"time"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/lib/pq"
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/clock"
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/config"
 	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/storage"
 	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/util"
 	log "github.com/sirupsen/logrus"
@@ -15,7 +18,18 @@ import (
 
 var ErrConnectionFailed = errors.New("failed to connect")
 
-const dbErrUniqueViolation = "23505"
+const (
+	dbErrUniqueViolation = "23505"
+	// Returned for malformed UUID.
+	dbErrInvalidTextRepresentation = "22P02"
+)
+
+const (
+	eventColumns = "id, title, start_timestamp AS startTime, end_timestamp AS endTime, description, " +
+		"notify_before AS notifyBefore, owner_id AS ownerId, category, tags, color, all_day AS allDay, transparency, " +
+		"resource_ids AS resourceIds, deleted_at AS deletedAt, version, updated_at AS updatedAt, " +
+		"COALESCE(calendar_id::text, '') AS calendarId"
+)
 
 type Config struct {
 	Host     string
@@ -25,35 +39,56 @@ type Config struct {
 	Password string
 }
 
+func (c Config) Validate(p *config.Problems, prefix string) {
+	p.Required(prefix+".host", c.Host)
+	p.Port(prefix+".port", c.Port)
+	p.Required(prefix+".database", c.Database)
+	p.Required(prefix+".username", c.Username)
+}
+
+// DSN returns connection string of the database.
+func (c Config) DSN() string {
+	return fmt.Sprintf(
+		"sslmode=disable host=%s port=%d dbname=%s user=%s password=%s",
+		c.Host, c.Port, c.Database, c.Username, c.Password)
+}
+
+// Row of events table, tags and resources have to be scanned as postgres arrays.
+type event struct {
+	storage.Event
+	Tags        pq.StringArray
+	ResourceIDs pq.StringArray
+}
+
+func (r event) toEvent() storage.Event {
+	e := r.Event
+	e.Tags, e.ResourceIDs = nil, nil
+	if len(r.Tags) > 0 {
+		e.Tags = r.Tags
+	}
+	if len(r.ResourceIDs) > 0 {
+		e.ResourceIDs = r.ResourceIDs
+	}
+	return e
+}
+
 type Storage struct {
-	host         string
-	port         int
-	database     string
-	username     string
-	password     string
+	dsn          string
 	db           *sqlx.DB
 	firstWeekDay time.Weekday
+	clock        clock.Clock
 }
 
-func New(config Config) *Storage {
+func New(config Config, clk clock.Clock) *Storage {
 	return &Storage{
-		host:         config.Host,
-		port:         config.Port,
-		database:     config.Database,
-		username:     config.Username,
-		password:     config.Password,
+		dsn:          config.DSN(),
 		firstWeekDay: time.Monday,
+		clock:        clk,
 	}
 }
 
 func (s *Storage) Connect(ctx context.Context) error {
-	db, err := sqlx.ConnectContext(
-		ctx,
-		"postgres",
-		fmt.Sprintf(
-			"sslmode=disable host=%s port=%d dbname=%s user=%s password=%s",
-			s.host, s.port, s.database, s.username, s.password),
-	)
+	db, err := sqlx.ConnectContext(ctx, "postgres", s.dsn)
 	if err != nil {
 		log.Errorf("failed to connect: %v", err)
 		return ErrConnectionFailed
@@ -69,137 +104,318 @@ func (s *Storage) Close(ctx context.Context) error {
 	return nil
 }
 
-func (s *Storage) AddEvent(ctx context.Context, e *storage.Event) error {
-	if e.StartTime.Before(time.Now()) {
-		return fmt.Errorf("start time of the event must be in the future: %w", storage.ErrIncorrectEventTime)
-	}
-	if !e.EndTime.After(e.StartTime) {
-		return fmt.Errorf("event end time should be after of start time: %w", storage.ErrIncorrectEventTime)
+func (s *Storage) Ping(ctx context.Context) error {
+	if err := s.db.PingContext(ctx); err != nil {
+		return fmt.Errorf("failed to ping database: %w", err)
 	}
+	return nil
+}
 
-	var err error
-	switch e.ID {
-	case "":
-		err = s.db.GetContext(
-			ctx,
-			&e.ID,
-			"INSERT INTO Events(title, start_timestamp, end_timestamp, description, notify_before, owner_id) "+
-				"VALUES($1, $2, $3, $4, $5, $6) RETURNING id",
-			e.Title, e.StartTime.UTC(), e.EndTime.UTC(), e.Description, e.NotifyBefore, e.OwnerID)
-	default:
-		_, err = s.db.ExecContext(
-			ctx,
-			"INSERT INTO Events(id, title, start_timestamp, end_timestamp, description, notify_before, owner_id) "+
-				"VALUES($1, $2, $3, $4, $5, $6, $7)",
-			e.ID, e.Title, e.StartTime.UTC(), e.EndTime.UTC(), e.Description, e.NotifyBefore, e.OwnerID)
-	}
-	var pqErr *pq.Error
-	if errors.As(err, &pqErr) && pqErr.Code == dbErrUniqueViolation {
-		return fmt.Errorf("duplicate ID %q: %w", e.ID, storage.ErrDuplicateEventID)
-	}
+func (s *Storage) AddEvent(ctx context.Context, e *storage.Event) error {
+	storage.NormalizeAllDay(e)
+	storage.NormalizeResources(e)
 
-	return err
+	return s.withTx(ctx, func(tx *sqlx.Tx) error {
+		if err := checkAccess(ctx, tx, e.CalendarID, storage.AccessWrite); err != nil {
+			return fmt.Errorf("failed to add event: %w", err)
+		}
+		if err := checkBookings(ctx, tx, e); err != nil {
+			return fmt.Errorf("failed to add event: %w", err)
+		}
+		stored, err := getEvent(
+			ctx,
+			tx,
+			"INSERT INTO Events(id, title, start_timestamp, end_timestamp, description, notify_before, owner_id, "+
+				"category, tags, color, version, updated_at, calendar_id, transparency, all_day, resource_ids) "+
+				"VALUES(COALESCE(NULLIF($1, '')::uuid, uuid_generate_v4()), $2, $3, $4, $5, $6, $7, $8, $9, $10, 1, $11, "+
+				"NULLIF($12, '')::uuid, $13, $14, $15::uuid[]) RETURNING "+eventColumns,
+			e.ID, e.Title, e.StartTime.UTC(), e.EndTime.UTC(), e.Description, e.NotifyBefore, e.OwnerID,
+			e.Category, tagsValue(e.Tags), e.Color, s.clock.Now().UTC(), e.CalendarID, string(e.Transparency), e.AllDay,
+			tagsValue(e.ResourceIDs))
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == dbErrUniqueViolation {
+			return fmt.Errorf("duplicate ID %q: %w", e.ID, storage.ErrDuplicateEventID)
+		}
+		if err != nil {
+			return err
+		}
+		e.ID, e.Version, e.UpdatedAt, e.DeletedAt = stored.ID, stored.Version, stored.UpdatedAt, nil
+		return addChange(ctx, tx, storage.OperationCreate, nil, &stored)
+	})
 }
 
-func (s *Storage) UpdateEvent(ctx context.Context, id string, e storage.Event) error {
-	if e.StartTime.Before(time.Now()) {
-		return fmt.Errorf("start time of the event must be in the future: %w", storage.ErrIncorrectEventTime)
-	}
-	if !e.EndTime.After(e.StartTime) {
-		return fmt.Errorf("event end time should be after of start time: %w", storage.ErrIncorrectEventTime)
-	}
+func (s *Storage) UpdateEvent(ctx context.Context, id string, e *storage.Event) error {
+	storage.NormalizeAllDay(e)
+	storage.NormalizeResources(e)
 
-	var found bool
-	err := s.db.GetContext(
-		ctx,
-		&found,
-		"UPDATE Events SET title=$2, start_timestamp=$3, end_timestamp=$4, description=$5, notify_before=$6 "+
-			"WHERE id=$1 RETURNING TRUE",
-		id,
-		e.Title,
-		e.StartTime,
-		e.EndTime,
-		e.Description,
-		e.NotifyBefore,
-	)
+	return s.withTx(ctx, func(tx *sqlx.Tx) error {
+		before, err := lockEvent(ctx, tx, id, false)
+		if isNoRows(err) {
+			return fmt.Errorf("failed to update event with id %q: %w", id, storage.ErrNotFoundEvent)
+		}
+		if err != nil {
+			return err
+		}
+		if err := checkEventAccess(ctx, tx, before, storage.AccessWrite); err != nil {
+			return fmt.Errorf("failed to update event: %w", err)
+		}
+		if e.CalendarID == "" {
+			e.CalendarID = before.CalendarID
+		}
+		if err := checkAccess(ctx, tx, e.CalendarID, storage.AccessWrite); err != nil {
+			return fmt.Errorf("failed to move event with id %q: %w", id, err)
+		}
+		if e.Version != 0 && e.Version != before.Version {
+			return fmt.Errorf("failed to update event with id %q: %w", id, storage.ErrVersionConflict)
+		}
+		e.ID = before.ID
+		if err := checkBookings(ctx, tx, e); err != nil {
+			return fmt.Errorf("failed to update event with id %q: %w", id, err)
+		}
 
-	if !found {
-		return fmt.Errorf("failed to update event with id %q: %w", e.ID, storage.ErrNotFoundEvent)
-	}
-	return err
+		after, err := getEvent(
+			ctx,
+			tx,
+			"UPDATE Events SET title=$2, start_timestamp=$3, end_timestamp=$4, description=$5, notify_before=$6, "+
+				"category=$7, tags=$8, color=$9, version=version+1, updated_at=$10, calendar_id=NULLIF($11, '')::uuid, "+
+				"transparency=$12, all_day=$13, resource_ids=$14::uuid[] WHERE id=$1 RETURNING "+eventColumns,
+			id,
+			e.Title,
+			e.StartTime.UTC(),
+			e.EndTime.UTC(),
+			e.Description,
+			e.NotifyBefore,
+			e.Category,
+			tagsValue(e.Tags),
+			e.Color,
+			s.clock.Now().UTC(),
+			e.CalendarID,
+			string(e.Transparency),
+			e.AllDay,
+			tagsValue(e.ResourceIDs),
+		)
+		if err != nil {
+			return err
+		}
+		e.ID, e.Version, e.UpdatedAt, e.DeletedAt = id, after.Version, after.UpdatedAt, nil
+		return addChange(ctx, tx, storage.OperationUpdate, &before, &after)
+	})
 }
 
-func (s *Storage) RemoveEvent(ctx context.Context, id string) error {
-	var found bool
-	err := s.db.GetContext(ctx, &found, "DELETE FROM Events WHERE id=$1 RETURNING TRUE", id)
+func (s *Storage) RemoveEvent(ctx context.Context, id string, version int64) error {
+	return s.withTx(ctx, func(tx *sqlx.Tx) error {
+		before, err := lockEvent(ctx, tx, id, false)
+		if isNoRows(err) {
+			return fmt.Errorf("failed to remove event with id %q: %w", id, storage.ErrNotFoundEvent)
+		}
+		if err != nil {
+			return err
+		}
+		if err := checkEventAccess(ctx, tx, before, storage.AccessWrite); err != nil {
+			return fmt.Errorf("failed to remove event: %w", err)
+		}
+		if version != 0 && version != before.Version {
+			return fmt.Errorf("failed to remove event with id %q: %w", id, storage.ErrVersionConflict)
+		}
 
-	if !found {
-		return fmt.Errorf("failed to remove event with id %q: %w", id, storage.ErrNotFoundEvent)
-	}
-	return err
+		after, err := getEvent(
+			ctx,
+			tx,
+			"UPDATE Events SET deleted_at=$2, version=version+1, updated_at=$2 WHERE id=$1 RETURNING "+eventColumns,
+			id,
+			s.clock.Now().UTC(),
+		)
+		if err != nil {
+			return err
+		}
+		return addChange(ctx, tx, storage.OperationDelete, &before, &after)
+	})
+}
+
+func (s *Storage) RestoreEvent(ctx context.Context, id string) error {
+	return s.withTx(ctx, func(tx *sqlx.Tx) error {
+		before, err := lockEvent(ctx, tx, id, true)
+		if isNoRows(err) {
+			return fmt.Errorf("failed to restore event with id %q: %w", id, storage.ErrNotFoundEvent)
+		}
+		if err != nil {
+			return err
+		}
+		if err := checkEventAccess(ctx, tx, before, storage.AccessWrite); err != nil {
+			return fmt.Errorf("failed to restore event: %w", err)
+		}
+		if err := checkBookings(ctx, tx, &before); err != nil {
+			return fmt.Errorf("failed to restore event with id %q: %w", id, err)
+		}
+
+		after, err := getEvent(
+			ctx,
+			tx,
+			"UPDATE Events SET deleted_at=NULL, version=version+1, updated_at=$2 WHERE id=$1 RETURNING "+eventColumns,
+			id,
+			s.clock.Now().UTC(),
+		)
+		if err != nil {
+			return err
+		}
+		return addChange(ctx, tx, storage.OperationRestore, &before, &after)
+	})
 }
 
-func (s *Storage) GetEventsForDay(ctx context.Context, date time.Time) ([]storage.Event, error) {
+func (s *Storage) GetDeletedEvents(ctx context.Context, ownerID string) ([]storage.Event, error) {
+	return s.selectEvents(
+		ctx,
+		"SELECT "+eventColumns+
+			" FROM Events WHERE deleted_at IS NOT NULL AND ($1 = '' OR owner_id = $1) AND "+accessibleCalendars(2)+
+			" ORDER BY deleted_at DESC",
+		ownerID,
+		storage.ActorFromContext(ctx),
+	)
+}
+
+func (s *Storage) GetEventsForDay(
+	ctx context.Context,
+	date time.Time,
+	filter storage.EventFilter,
+) ([]storage.Event, error) {
 	startTime := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
 	endTime := startTime.Add(24 * time.Hour)
-	return s.selectByRange(ctx, startTime, endTime)
+	return s.selectByRange(ctx, startTime, endTime, filter)
 }
 
-func (s *Storage) GetEventsForWeek(ctx context.Context, startDate time.Time) ([]storage.Event, error) {
+func (s *Storage) GetEventsForWeek(
+	ctx context.Context,
+	startDate time.Time,
+	filter storage.EventFilter,
+) ([]storage.Event, error) {
 	startTime := util.TruncateToDay(startDate)
 	if startTime.Weekday() != s.firstWeekDay {
 		return nil, storage.ErrIncorrectStartDate
 	}
 	endTime := startTime.AddDate(0, 0, 7)
-	return s.selectByRange(ctx, startTime, endTime)
+	return s.selectByRange(ctx, startTime, endTime, filter)
 }
 
-func (s *Storage) GetEventsForMonth(ctx context.Context, startDate time.Time) ([]storage.Event, error) {
+func (s *Storage) GetEventsForMonth(
+	ctx context.Context,
+	startDate time.Time,
+	filter storage.EventFilter,
+) ([]storage.Event, error) {
 	startTime := util.TruncateToDay(startDate)
 	if startTime.Day() != 1 {
 		return nil, storage.ErrIncorrectStartDate
 	}
 	endTime := startTime.AddDate(0, 1, 0)
-	return s.selectByRange(ctx, startTime, endTime)
+	return s.selectByRange(ctx, startTime, endTime, filter)
 }
 
-func (s *Storage) GetEventsByNotifier(
+func (s *Storage) PurgeDeleted(ctx context.Context, deletedBefore time.Time) error {
+	return s.withTx(ctx, func(tx *sqlx.Tx) error {
+		// Attachments of the events are removed by foreign key.
+		err := s.keepOrphanBlobs(ctx, tx, "SELECT id FROM Events WHERE deleted_at < $2", deletedBefore.UTC())
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, "DELETE FROM Events WHERE deleted_at < $1", deletedBefore.UTC())
+		return err
+	})
+}
+
+// Select events overlapping range [startTime:endTime), see storage.Event.Overlaps.
+func (s *Storage) selectByRange(
 	ctx context.Context,
 	startTime time.Time,
 	endTime time.Time,
+	filter storage.EventFilter,
 ) ([]storage.Event, error) {
-	var events []storage.Event
-	err := s.db.SelectContext(
+	return s.selectEvents(
 		ctx,
-		&events,
-		"SELECT id, title, start_timestamp AS startTime, end_timestamp AS endTime, description, "+
-			"notify_before AS notifyBefore, owner_id AS ownerId "+
-			"FROM Events WHERE notify_before > 0 AND (start_timestamp - (interval '1' day * notify_before))>=$1 "+
-			"AND (start_timestamp - (interval '1' day * notify_before))<=$2",
-		startTime,
-		endTime,
+		"SELECT "+eventColumns+
+			" FROM Events WHERE deleted_at IS NULL "+
+			"AND "+overlapsRange(1, 2, 6, 7)+
+			" AND ($3 = '' OR category = $3) AND ($4::text[] IS NULL OR tags @> $4) AND "+accessibleCalendars(5),
+		startTime.UTC(),
+		endTime.UTC(),
+		filter.Category,
+		tagsArray(filter.Tags),
+		storage.ActorFromContext(ctx),
+		storage.Floating(startTime),
+		storage.Floating(endTime),
 	)
+}
+
+// Returns condition of storage.Event.Overlaps by numbers of parameters with bounds of range,
+// floating bounds are compared with all-day events.
+func overlapsRange(start, end, floatStart, floatEnd int) string {
+	return fmt.Sprintf("(NOT all_day AND start_timestamp<$%d AND end_timestamp>$%d "+
+		"OR all_day AND start_timestamp<$%d AND end_timestamp>$%d)", end, start, floatEnd, floatStart)
+}
 
-	return events, err
+func isNoRows(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == dbErrInvalidTextRepresentation {
+		return true
+	}
+	return errors.Is(err, sql.ErrNoRows)
+}
+
+func (s *Storage) selectEvents(ctx context.Context, query string, args ...interface{}) ([]storage.Event, error) {
+	var rows []event
+	if err := s.db.SelectContext(ctx, &rows, query, args...); err != nil {
+		return nil, err
+	}
+	events := make([]storage.Event, 0, len(rows))
+	for _, row := range rows {
+		events = append(events, row.toEvent())
+	}
+	return events, nil
+}
+
+func (s *Storage) withTx(ctx context.Context, fn func(tx *sqlx.Tx) error) error {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	if err := fn(tx); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			log.Errorf("failed to rollback transaction: %v", rbErr)
+		}
+		return err
+	}
+	return tx.Commit()
 }
 
-func (s *Storage) RemoveAfter(ctx context.Context, time time.Time) error {
-	_, err := s.db.Exec("DELETE FROM Events WHERE start_timestamp < $1", time)
-	return err
+func getEvent(ctx context.Context, q sqlx.QueryerContext, query string, args ...interface{}) (storage.Event, error) {
+	var row event
+	if err := sqlx.GetContext(ctx, q, &row, query, args...); err != nil {
+		return storage.Event{}, err
+	}
+	return row.toEvent(), nil
 }
 
-// Select in range [startTime:endTime).
-func (s *Storage) selectByRange(ctx context.Context, startTime time.Time, endTime time.Time) ([]storage.Event, error) {
-	var events []storage.Event
-	err := s.db.SelectContext(
+// Selects event for update in the transaction, deleted flag chooses event from trash or active one.
+func lockEvent(ctx context.Context, tx *sqlx.Tx, id string, deleted bool) (storage.Event, error) {
+	return getEvent(
 		ctx,
-		&events,
-		"SELECT id, title, start_timestamp AS startTime, end_timestamp AS endTime, description, "+
-			"notify_before AS notifyBefore, owner_id AS ownerId "+
-			"FROM Events WHERE start_timestamp>=$1 AND end_timestamp<$2",
-		startTime,
-		endTime,
+		tx,
+		"SELECT "+eventColumns+" FROM Events WHERE id=$1 AND (deleted_at IS NOT NULL)=$2 FOR UPDATE",
+		id,
+		deleted,
 	)
+}
 
-	return events, err
+// Empty array for nil, columns of arrays are not nullable.
+func tagsValue(tags []string) pq.StringArray {
+	if tags == nil {
+		return pq.StringArray{}
+	}
+	return tags
+}
+
+// NULL for empty tags to skip tags filter.
+func tagsArray(tags []string) interface{} {
+	if len(tags) == 0 {
+		return nil
+	}
+	return pq.StringArray(tags)
 }
@@ -1,3 +1,4 @@
+//go:build sql
 // +build sql
 
 package sqlstorage_test
@@ -6,11 +7,13 @@ import (
 	"context"
 	"fmt"
 	"github.com/jmoiron/sqlx"
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/clock"
 	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/storage"
 	sqlstorage "github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/storage/sql"
 	"github.com/stretchr/testify/require"
 	"log"
 	"os"
+	"sort"
 	"strconv"
 	"testing"
 	"time"
@@ -61,7 +64,7 @@ func TestStorage(t *testing.T) {
 		require.NoError(t, s.AddEvent(context.Background(), &e))
 		require.NotEmpty(t, e.ID)
 
-		events, err := s.GetEventsForDay(context.Background(), initDate)
+		events, err := s.GetEventsForDay(context.Background(), initDate, storage.EventFilter{})
 		require.NoError(t, err)
 		require.Equal(t, 1, len(events))
 		compareEvents(t, e, events[0])
@@ -88,9 +91,9 @@ func TestStorage(t *testing.T) {
 		e.Description = "updated description"
 		e.NotifyBefore = 100
 
-		require.NoError(t, s.UpdateEvent(context.Background(), e.ID, e))
+		require.NoError(t, s.UpdateEvent(context.Background(), e.ID, &e))
 
-		events, err := s.GetEventsForWeek(context.Background(), initDate)
+		events, err := s.GetEventsForWeek(context.Background(), initDate, storage.EventFilter{})
 		require.NoError(t, err)
 		require.Equal(t, 1, len(events))
 		compareEvents(t, e, events[0])
@@ -111,13 +114,732 @@ func TestStorage(t *testing.T) {
 		s := createStorage(t)
 		require.NoError(t, s.AddEvent(context.Background(), &e))
 
-		require.NoError(t, s.RemoveEvent(context.Background(), e.ID))
+		require.NoError(t, s.RemoveEvent(context.Background(), e.ID, 0))
 
-		events, err := s.GetEventsForWeek(context.Background(), initDate)
+		events, err := s.GetEventsForWeek(context.Background(), initDate, storage.EventFilter{})
 		require.NoError(t, err)
 		require.Equal(t, 0, len(events))
 	})
 
+	t.Run("restore event", func(t *testing.T) {
+		initDate := time.Date(2300, 01, 01, 0, 0, 0, 0, time.UTC)
+		e := storage.Event{
+			Title:     "test",
+			StartTime: initDate.Add(1 * time.Hour),
+			EndTime:   initDate.Add(2 * time.Hour),
+			OwnerID:   "testId",
+		}
+
+		s := createStorage(t)
+		require.NoError(t, s.AddEvent(context.Background(), &e))
+		require.NoError(t, s.RemoveEvent(context.Background(), e.ID, 0))
+
+		deleted, err := s.GetDeletedEvents(context.Background(), "testId")
+		require.NoError(t, err)
+		require.Equal(t, 1, len(deleted))
+		require.NotNil(t, deleted[0].DeletedAt)
+		require.Equal(t, e.ID, deleted[0].ID)
+		require.Equal(t, e.Version+1, deleted[0].Version)
+
+		require.NoError(t, s.RestoreEvent(context.Background(), e.ID))
+
+		deleted, err = s.GetDeletedEvents(context.Background(), "")
+		require.NoError(t, err)
+		require.Equal(t, 0, len(deleted))
+
+		events, err := s.GetEventsForWeek(context.Background(), initDate, storage.EventFilter{})
+		require.NoError(t, err)
+		require.Equal(t, 1, len(events))
+		e.Version += 2
+		compareEvents(t, e, events[0])
+	})
+
+	t.Run("purge deleted events", func(t *testing.T) {
+		initDate := time.Date(2300, 01, 01, 0, 0, 0, 0, time.UTC)
+		s := createStorage(t)
+
+		ids := make([]string, 0, 2)
+		for i := 0; i < 2; i++ {
+			e := storage.Event{
+				Title:     "test",
+				StartTime: initDate.Add(time.Duration(i) * time.Hour),
+				EndTime:   initDate.Add(time.Duration(i+1) * time.Hour),
+				OwnerID:   "testId",
+			}
+			require.NoError(t, s.AddEvent(context.Background(), &e))
+			ids = append(ids, e.ID)
+		}
+		require.NoError(t, s.RemoveEvent(context.Background(), ids[0], 0))
+
+		require.NoError(t, s.PurgeDeleted(context.Background(), time.Now().Add(-time.Hour)))
+		deleted, err := s.GetDeletedEvents(context.Background(), "")
+		require.NoError(t, err)
+		require.Equal(t, 1, len(deleted))
+
+		require.NoError(t, s.PurgeDeleted(context.Background(), time.Now().Add(time.Hour)))
+		deleted, err = s.GetDeletedEvents(context.Background(), "")
+		require.NoError(t, err)
+		require.Equal(t, 0, len(deleted))
+		require.ErrorIs(t, s.RestoreEvent(context.Background(), ids[0]), storage.ErrNotFoundEvent)
+
+		events, err := s.GetEventsForWeek(context.Background(), initDate, storage.EventFilter{})
+		require.NoError(t, err)
+		require.Equal(t, 1, len(events))
+		require.Equal(t, ids[1], events[0].ID)
+	})
+
+	t.Run("event history", func(t *testing.T) {
+		initDate := time.Date(2300, 01, 01, 0, 0, 0, 0, time.UTC)
+		e := storage.Event{
+			Title:     "test",
+			StartTime: initDate.Add(1 * time.Hour),
+			EndTime:   initDate.Add(2 * time.Hour),
+			OwnerID:   "testId",
+		}
+
+		s := createStorage(t)
+		require.NoError(t, s.AddEvent(context.Background(), &e))
+		e.Title = "updated title"
+		require.NoError(t, s.UpdateEvent(storage.ContextWithActor(context.Background(), "editor"), e.ID, &e))
+		require.NoError(t, s.RemoveEvent(context.Background(), e.ID, 0))
+		require.NoError(t, s.RestoreEvent(storage.ContextWithActor(context.Background(), "admin"), e.ID))
+
+		changes, err := s.GetEventHistory(context.Background(), e.ID)
+		require.NoError(t, err)
+		require.Equal(t, 4, len(changes))
+
+		expected := []struct {
+			operation storage.Operation
+			actorID   string
+		}{
+			{operation: storage.OperationCreate, actorID: "testId"},
+			{operation: storage.OperationUpdate, actorID: "editor"},
+			{operation: storage.OperationDelete, actorID: "testId"},
+			{operation: storage.OperationRestore, actorID: "admin"},
+		}
+		for i, c := range changes {
+			require.Equal(t, e.ID, c.EventID)
+			require.Equal(t, expected[i].operation, c.Operation)
+			require.Equal(t, expected[i].actorID, c.ActorID)
+			require.NotNil(t, c.After)
+			require.False(t, c.ChangedAt.IsZero())
+		}
+		require.Nil(t, changes[0].Before)
+		require.Equal(t, "test", changes[1].Before.Title)
+		require.Equal(t, "updated title", changes[1].After.Title)
+		require.Nil(t, changes[2].Before.DeletedAt)
+		require.NotNil(t, changes[2].After.DeletedAt)
+		require.Equal(t, int64(4), changes[3].After.Version)
+
+		_, err = s.GetEventHistory(context.Background(), "___not_exists___")
+		require.ErrorIs(t, err, storage.ErrNotFoundEvent)
+	})
+
+	t.Run("history after purge by another user", func(t *testing.T) {
+		initDate := time.Date(2300, 01, 01, 0, 0, 0, 0, time.UTC)
+		erin := storage.ContextWithActor(context.Background(), "erin")
+		frank := storage.ContextWithActor(context.Background(), "frank")
+		s := createStorage(t)
+		c := storage.Calendar{Name: "Private", OwnerID: "erin", TimeZone: "UTC"}
+		require.NoError(t, s.CreateCalendar(erin, &c))
+		purged := storage.Event{
+			Title: "Secret", StartTime: initDate, EndTime: initDate.Add(time.Hour), OwnerID: "erin", CalendarID: c.ID,
+		}
+		require.NoError(t, s.AddEvent(erin, &purged))
+		removed := storage.Event{
+			Title: "Secret too", StartTime: initDate, EndTime: initDate.Add(time.Hour), OwnerID: "erin", CalendarID: c.ID,
+		}
+		require.NoError(t, s.AddEvent(erin, &removed))
+		changes, err := s.GetEventHistory(frank, purged.ID)
+		require.NoError(t, err)
+		require.Empty(t, changes)
+
+		require.NoError(t, s.RemoveEvent(erin, purged.ID, 0))
+		require.NoError(t, s.PurgeDeleted(erin, initDate.AddDate(1000, 0, 0)))
+		_, err = s.GetEventHistory(frank, purged.ID)
+		require.ErrorIs(t, err, storage.ErrNotFoundEvent)
+
+		require.NoError(t, s.RemoveCalendar(erin, c.ID))
+		_, err = s.GetEventHistory(frank, removed.ID)
+		require.ErrorIs(t, err, storage.ErrNotFoundEvent)
+	})
+
+	t.Run("batch", func(t *testing.T) {
+		initDate := time.Date(2300, 01, 01, 0, 0, 0, 0, time.UTC)
+		events := make([]storage.Event, 3)
+		for i := range events {
+			events[i] = storage.Event{
+				Title:     "test",
+				StartTime: initDate.Add(time.Duration(i+1) * time.Hour),
+				EndTime:   initDate.Add(time.Duration(i+2) * time.Hour),
+				OwnerID:   "testId",
+			}
+		}
+
+		s := createStorage(t)
+		results, err := s.AddEvents(context.Background(), events, storage.BatchOptions{})
+		require.NoError(t, err)
+		require.Equal(t, 3, len(results))
+		ids := make([]string, 0, len(results))
+		for _, r := range results {
+			require.NoError(t, r.Err)
+			require.NotEmpty(t, r.Event.ID)
+			require.Equal(t, int64(1), r.Event.Version)
+			ids = append(ids, r.Event.ID)
+		}
+
+		updates := []storage.Event{results[0].Event, results[1].Event}
+		updates[0].Title = "updated"
+		updates[1].Version = 100
+		results, err = s.UpdateEvents(context.Background(), updates, storage.BatchOptions{})
+		require.NoError(t, err)
+		require.NoError(t, results[0].Err)
+		require.Equal(t, int64(2), results[0].Event.Version)
+		require.ErrorIs(t, results[1].Err, storage.ErrVersionConflict)
+
+		results, err = s.RemoveEvents(
+			context.Background(),
+			[]storage.EventRef{{ID: ids[1]}, {ID: "___not_exists___"}},
+			storage.BatchOptions{AllOrNothing: true},
+		)
+		require.ErrorIs(t, err, storage.ErrBatchRolledBack)
+		require.ErrorIs(t, results[0].Err, storage.ErrBatchRolledBack)
+		require.ErrorIs(t, results[1].Err, storage.ErrNotFoundEvent)
+
+		results, err = s.RemoveEvents(
+			context.Background(),
+			[]storage.EventRef{{ID: ids[1]}, {ID: ids[2], Version: 1}},
+			storage.BatchOptions{AllOrNothing: true},
+		)
+		require.NoError(t, err)
+		require.NotNil(t, results[0].Event.DeletedAt)
+		require.NotNil(t, results[1].Event.DeletedAt)
+
+		stored, err := s.GetEventsForDay(context.Background(), initDate, storage.EventFilter{})
+		require.NoError(t, err)
+		require.Equal(t, 1, len(stored))
+		require.Equal(t, "updated", stored[0].Title)
+
+		changes, err := s.GetEventHistory(context.Background(), ids[1])
+		require.NoError(t, err)
+		require.Equal(t, 2, len(changes))
+		require.Equal(t, storage.OperationDelete, changes[1].Operation)
+	})
+
+	t.Run("search", func(t *testing.T) {
+		initDate := time.Date(2300, 01, 01, 0, 0, 0, 0, time.UTC)
+		events := []storage.Event{
+			{Title: "Budget meeting", Description: "Discuss budget for next year", OwnerID: "owner1"},
+			{Title: "Team lunch", Description: "Meeting about budget is after lunch", OwnerID: "owner1"},
+			{Title: "Budget review", Description: "", OwnerID: "owner2"},
+			{Title: "Retro", Description: "Sprint retrospective", OwnerID: "owner1"},
+		}
+		s := createStorage(t)
+		for i := range events {
+			events[i].StartTime = initDate.AddDate(0, 0, i)
+			events[i].EndTime = events[i].StartTime.Add(time.Hour)
+			require.NoError(t, s.AddEvent(context.Background(), &events[i]))
+		}
+
+		found, err := s.SearchEvents(context.Background(), storage.SearchQuery{Text: "budget"})
+		require.NoError(t, err)
+		require.Equal(t, 3, len(found))
+		require.Equal(t, events[0].ID, found[0].ID)
+		require.Equal(t, events[1].ID, found[2].ID)
+
+		found, err = s.SearchEvents(context.Background(), storage.SearchQuery{Text: `"meeting about budget"`})
+		require.NoError(t, err)
+		require.Equal(t, 1, len(found))
+		require.Equal(t, events[1].ID, found[0].ID)
+
+		found, err = s.SearchEvents(context.Background(), storage.SearchQuery{Text: "retro*"})
+		require.NoError(t, err)
+		require.Equal(t, 1, len(found))
+		require.Equal(t, events[3].ID, found[0].ID)
+
+		found, err = s.SearchEvents(
+			context.Background(),
+			storage.SearchQuery{Text: "budg*", OwnerID: "owner1", From: initDate.AddDate(0, 0, 1), Limit: 10},
+		)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(found))
+		require.Equal(t, events[1].ID, found[0].ID)
+
+		require.NoError(t, s.RemoveEvent(context.Background(), events[0].ID, 0))
+		found, err = s.SearchEvents(context.Background(), storage.SearchQuery{Text: "budget", Limit: 1})
+		require.NoError(t, err)
+		require.Equal(t, 1, len(found))
+		require.Equal(t, events[2].ID, found[0].ID)
+
+		_, err = s.SearchEvents(context.Background(), storage.SearchQuery{Text: " \"*\" "})
+		require.ErrorIs(t, err, storage.ErrEmptySearchQuery)
+	})
+
+	t.Run("all-day and multi-day events", func(t *testing.T) {
+		initDate := time.Date(2300, 01, 01, 0, 0, 0, 0, time.UTC)
+		s := createStorage(t)
+
+		conference := storage.Event{
+			Title:     "Conference",
+			StartTime: initDate.Add(15 * time.Hour),
+			EndTime:   initDate.AddDate(0, 0, 2).Add(9 * time.Hour),
+			AllDay:    true,
+		}
+		require.NoError(t, s.AddEvent(context.Background(), &conference))
+		require.True(t, initDate.Equal(conference.StartTime))
+		require.True(t, initDate.AddDate(0, 0, 3).Equal(conference.EndTime))
+
+		holiday := storage.Event{Title: "Holiday", StartTime: initDate.AddDate(0, 0, 3), AllDay: true}
+		require.NoError(t, s.AddEvent(context.Background(), &holiday))
+		require.True(t, initDate.AddDate(0, 0, 4).Equal(holiday.EndTime))
+
+		night := storage.Event{
+			Title:     "Night shift",
+			StartTime: initDate.AddDate(0, 0, 1).Add(22 * time.Hour),
+			EndTime:   initDate.AddDate(0, 0, 2).Add(6 * time.Hour),
+		}
+		require.NoError(t, s.AddEvent(context.Background(), &night))
+
+		titles := func(date time.Time) []string {
+			events, err := s.GetEventsForDay(context.Background(), date, storage.EventFilter{})
+			require.NoError(t, err)
+			titles := make([]string, 0, len(events))
+			for _, e := range events {
+				titles = append(titles, e.Title)
+			}
+			sort.Strings(titles)
+			return titles
+		}
+		require.Equal(t, []string{"Conference"}, titles(initDate))
+		require.Equal(t, []string{"Conference", "Night shift"}, titles(initDate.AddDate(0, 0, 1)))
+		require.Equal(t, []string{"Conference", "Night shift"}, titles(initDate.AddDate(0, 0, 2)))
+		require.Equal(t, []string{"Holiday"}, titles(initDate.AddDate(0, 0, 3)))
+
+		// All-day events keep their dates in other time zones.
+		zone := time.FixedZone("UTC+5", 5*60*60)
+		require.Equal(
+			t,
+			[]string{"Holiday"},
+			titles(time.Date(2300, 01, 04, 0, 0, 0, 0, zone)),
+		)
+
+	})
+
+	t.Run("free busy", func(t *testing.T) {
+		initDate := time.Date(2300, 01, 01, 10, 0, 0, 0, time.UTC)
+		events := []storage.Event{
+			{OwnerID: "alice", StartTime: initDate, EndTime: initDate.Add(time.Hour)},
+			{OwnerID: "alice", StartTime: initDate.Add(30 * time.Minute), EndTime: initDate.Add(2 * time.Hour)},
+			{OwnerID: "alice", StartTime: initDate.Add(2 * time.Hour), EndTime: initDate.Add(3 * time.Hour)},
+			{
+				OwnerID: "alice", StartTime: initDate.Add(4 * time.Hour), EndTime: initDate.Add(5 * time.Hour),
+				Transparency: storage.TransparencyFree,
+			},
+			{OwnerID: "alice", StartTime: initDate.Add(6 * time.Hour), EndTime: initDate.Add(7 * time.Hour)},
+			{OwnerID: "bob", StartTime: initDate.Add(-time.Hour), EndTime: initDate.Add(time.Hour)},
+			{OwnerID: "carol", StartTime: initDate, EndTime: initDate.Add(time.Hour)},
+		}
+		s := createStorage(t)
+		for i := range events {
+			events[i].Title = "busy"
+			require.NoError(t, s.AddEvent(context.Background(), &events[i]))
+		}
+		require.NoError(t, s.RemoveEvent(context.Background(), events[4].ID, 0))
+
+		freeBusy, err := s.GetFreeBusy(
+			context.Background(), []string{"bob", "alice", "dave"}, initDate, initDate.Add(8*time.Hour),
+		)
+		require.NoError(t, err)
+		require.Equal(t, 3, len(freeBusy))
+		require.Equal(t, "bob", freeBusy[0].OwnerID)
+		require.Equal(t, 1, len(freeBusy[0].Busy))
+		require.True(t, initDate.Equal(freeBusy[0].Busy[0].Start))
+		require.True(t, initDate.Add(time.Hour).Equal(freeBusy[0].Busy[0].End))
+		require.Equal(t, "alice", freeBusy[1].OwnerID)
+		require.Equal(t, 1, len(freeBusy[1].Busy))
+		require.True(t, initDate.Equal(freeBusy[1].Busy[0].Start))
+		require.True(t, initDate.Add(3*time.Hour).Equal(freeBusy[1].Busy[0].End))
+		require.Equal(t, storage.FreeBusy{OwnerID: "dave", Busy: []storage.Interval{}}, freeBusy[2])
+
+		_, err = s.GetFreeBusy(context.Background(), []string{"alice"}, initDate, initDate)
+		require.ErrorIs(t, err, storage.ErrIncorrectPeriod)
+
+		// All-day event is busy for its dates in location of the range.
+		holiday := storage.Event{
+			Title: "Holiday", OwnerID: "erin", StartTime: time.Date(2300, 01, 02, 0, 0, 0, 0, time.UTC), AllDay: true,
+		}
+		require.NoError(t, s.AddEvent(context.Background(), &holiday))
+		zone := time.FixedZone("UTC+10", 10*60*60)
+		from := time.Date(2300, 01, 02, 0, 0, 0, 0, zone)
+		freeBusy, err = s.GetFreeBusy(context.Background(), []string{"erin"}, from, from.AddDate(0, 0, 2))
+		require.NoError(t, err)
+		require.Equal(t, 1, len(freeBusy[0].Busy))
+		require.True(t, from.Equal(freeBusy[0].Busy[0].Start))
+		require.True(t, from.AddDate(0, 0, 1).Equal(freeBusy[0].Busy[0].End))
+	})
+
+	t.Run("calendars", func(t *testing.T) {
+		initDate := time.Date(2300, 01, 01, 0, 0, 0, 0, time.UTC)
+		alice := storage.ContextWithActor(context.Background(), "alice")
+		bob := storage.ContextWithActor(context.Background(), "bob")
+		s := createStorage(t)
+
+		c := storage.Calendar{Name: "Work", OwnerID: "alice", TimeZone: "UTC"}
+		require.NoError(t, s.CreateCalendar(alice, &c))
+		require.NotEmpty(t, c.ID)
+		require.Equal(t, storage.AccessAdmin, c.Access)
+
+		e := storage.Event{
+			Title: "Planning", StartTime: initDate, EndTime: initDate.Add(time.Hour), OwnerID: "alice", CalendarID: c.ID,
+		}
+		require.NoError(t, s.AddEvent(alice, &e))
+		require.ErrorIs(t, s.AddEvent(bob, &storage.Event{
+			StartTime: initDate, EndTime: initDate.Add(time.Hour), CalendarID: c.ID,
+		}), storage.ErrNotFoundCalendar)
+
+		events, err := s.GetEventsForDay(bob, initDate, storage.EventFilter{})
+		require.NoError(t, err)
+		require.Equal(t, 0, len(events))
+		require.ErrorIs(t, s.UpdateEvent(bob, e.ID, &e), storage.ErrNotFoundEvent)
+
+		grant := storage.Grant{CalendarID: c.ID, UserID: "bob", Access: "owner"}
+		require.ErrorIs(t, s.ShareCalendar(alice, grant), storage.ErrIncorrectAccess)
+		grant.Access = storage.AccessNone
+		require.ErrorIs(t, s.ShareCalendar(alice, grant), storage.ErrIncorrectAccess)
+		grant.Access = storage.AccessRead
+		require.NoError(t, s.ShareCalendar(alice, grant))
+		events, err = s.GetEventsForDay(bob, initDate, storage.EventFilter{})
+		require.NoError(t, err)
+		require.Equal(t, 1, len(events))
+		upd := events[0]
+		upd.Title = "Planning by bob"
+		require.ErrorIs(t, s.UpdateEvent(bob, e.ID, &upd), storage.ErrAccessDenied)
+		require.ErrorIs(t, s.AcknowledgeReminder(bob, e.ID), storage.ErrAccessDenied)
+		require.ErrorIs(t, s.SnoozeReminder(bob, e.ID, initDate), storage.ErrAccessDenied)
+		_, err = s.GetCalendarGrants(bob, c.ID)
+		require.ErrorIs(t, err, storage.ErrAccessDenied)
+
+		grant.Access = storage.AccessWrite
+		require.NoError(t, s.ShareCalendar(alice, grant))
+		require.NoError(t, s.UpdateEvent(bob, e.ID, &upd))
+		require.ErrorIs(t, s.AcknowledgeReminder(bob, e.ID), storage.ErrNoReminder)
+		calendars, err := s.GetCalendars(bob)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(calendars))
+		require.Equal(t, storage.AccessWrite, calendars[0].Access)
+		grants, err := s.GetCalendarGrants(alice, c.ID)
+		require.NoError(t, err)
+		require.Equal(t, []storage.Grant{grant}, grants)
+		require.ErrorIs(t, s.RemoveCalendar(bob, c.ID), storage.ErrAccessDenied)
+
+		require.NoError(t, s.RevokeCalendarAccess(alice, c.ID, "bob"))
+		calendars, err = s.GetCalendars(bob)
+		require.NoError(t, err)
+		require.Equal(t, 0, len(calendars))
+
+		c.Name = "Office"
+		require.NoError(t, s.UpdateCalendar(alice, &c))
+		require.Equal(t, "Office", c.Name)
+		require.ErrorIs(t, s.UpdateCalendar(bob, &c), storage.ErrNotFoundCalendar)
+
+		require.NoError(t, s.RemoveCalendar(alice, c.ID))
+		events, err = s.GetEventsForDay(alice, initDate, storage.EventFilter{})
+		require.NoError(t, err)
+		require.Equal(t, 0, len(events))
+		require.ErrorIs(t, s.UpdateCalendar(alice, &c), storage.ErrNotFoundCalendar)
+	})
+
+	t.Run("reminders", func(t *testing.T) {
+		initDate := time.Date(2300, 01, 01, 10, 0, 0, 0, time.UTC)
+		remindAt := initDate.AddDate(0, 0, -1)
+		e := storage.Event{
+			Title: "Reminded", StartTime: initDate, EndTime: initDate.Add(time.Hour), NotifyBefore: 1,
+		}
+		noReminder := storage.Event{Title: "Not reminded", StartTime: initDate, EndTime: initDate.Add(time.Hour)}
+		s := createStorage(t)
+		require.NoError(t, s.AddEvent(context.Background(), &e))
+		require.NoError(t, s.AddEvent(context.Background(), &noReminder))
+
+		reminders, err := s.GetReminders(context.Background(), remindAt, remindAt.Add(time.Minute))
+		require.NoError(t, err)
+		require.Equal(t, 1, len(reminders))
+		require.Equal(t, e.ID, reminders[0].Event.ID)
+		require.True(t, remindAt.Equal(reminders[0].RemindAt))
+
+		snoozedUntil := remindAt.Add(10 * time.Minute)
+		require.NoError(t, s.SnoozeReminder(context.Background(), e.ID, snoozedUntil))
+		reminders, err = s.GetReminders(context.Background(), remindAt, remindAt.Add(time.Minute))
+		require.NoError(t, err)
+		require.Equal(t, 0, len(reminders))
+		reminders, err = s.GetReminders(context.Background(), snoozedUntil, snoozedUntil.Add(time.Minute))
+		require.NoError(t, err)
+		require.Equal(t, 1, len(reminders))
+		require.True(t, snoozedUntil.Equal(reminders[0].RemindAt))
+
+		require.NoError(t, s.AcknowledgeReminder(context.Background(), e.ID))
+		reminders, err = s.GetReminders(context.Background(), remindAt, snoozedUntil.Add(time.Minute))
+		require.NoError(t, err)
+		require.Equal(t, 0, len(reminders))
+
+		e.StartTime = initDate.Add(time.Hour)
+		e.EndTime = initDate.Add(2 * time.Hour)
+		require.NoError(t, s.UpdateEvent(context.Background(), e.ID, &e))
+		reminders, err = s.GetReminders(context.Background(), remindAt, remindAt.Add(2*time.Hour))
+		require.NoError(t, err)
+		require.Equal(t, 1, len(reminders))
+		require.True(t, remindAt.Add(time.Hour).Equal(reminders[0].RemindAt))
+
+		require.ErrorIs(t, s.AcknowledgeReminder(context.Background(), noReminder.ID), storage.ErrNoReminder)
+		require.ErrorIs(t, s.SnoozeReminder(context.Background(), "unknown", snoozedUntil), storage.ErrNotFoundEvent)
+	})
+
+	t.Run("writer drops calendar", func(t *testing.T) {
+		initDate := time.Date(2300, 01, 01, 0, 0, 0, 0, time.UTC)
+		gina := storage.ContextWithActor(context.Background(), "gina")
+		hank := storage.ContextWithActor(context.Background(), "hank")
+		ivan := storage.ContextWithActor(context.Background(), "ivan")
+		s := createStorage(t)
+		c := storage.Calendar{Name: "Private", OwnerID: "gina", TimeZone: "UTC"}
+		require.NoError(t, s.CreateCalendar(gina, &c))
+		other := storage.Calendar{Name: "Other", OwnerID: "ivan", TimeZone: "UTC"}
+		require.NoError(t, s.CreateCalendar(ivan, &other))
+		require.NoError(t, s.ShareCalendar(gina, storage.Grant{
+			CalendarID: c.ID, UserID: "hank", Access: storage.AccessWrite,
+		}))
+		e := storage.Event{
+			Title: "Secret", StartTime: initDate, EndTime: initDate.Add(time.Hour), OwnerID: "gina", CalendarID: c.ID,
+		}
+		require.NoError(t, s.AddEvent(gina, &e))
+
+		// Event without calendar in update stays in its calendar and is not visible for everyone.
+		upd := storage.Event{Title: "Renamed", StartTime: e.StartTime, EndTime: e.EndTime, OwnerID: "gina"}
+		require.NoError(t, s.UpdateEvent(hank, e.ID, &upd))
+		require.Equal(t, c.ID, upd.CalendarID)
+		batch := storage.Event{ID: e.ID, Title: "Renamed twice", StartTime: e.StartTime, EndTime: e.EndTime}
+		results, err := s.UpdateEvents(hank, []storage.Event{batch}, storage.BatchOptions{})
+		require.NoError(t, err)
+		require.NoError(t, results[0].Err)
+		require.Equal(t, c.ID, results[0].Event.CalendarID)
+		events, err := s.GetEventsForDay(context.Background(), initDate, storage.EventFilter{})
+		require.NoError(t, err)
+		require.Empty(t, events)
+
+		// Moving requires write access to both calendars.
+		upd.CalendarID, upd.Version = other.ID, 0
+		require.ErrorIs(t, s.UpdateEvent(hank, e.ID, &upd), storage.ErrNotFoundCalendar)
+		require.NoError(t, s.ShareCalendar(ivan, storage.Grant{
+			CalendarID: other.ID, UserID: "hank", Access: storage.AccessRead,
+		}))
+		require.ErrorIs(t, s.UpdateEvent(hank, e.ID, &upd), storage.ErrAccessDenied)
+		upd.CalendarID = c.ID
+		require.ErrorIs(t, s.UpdateEvent(ivan, e.ID, &upd), storage.ErrNotFoundEvent)
+		require.NoError(t, s.ShareCalendar(ivan, storage.Grant{
+			CalendarID: other.ID, UserID: "hank", Access: storage.AccessWrite,
+		}))
+		upd.CalendarID = other.ID
+		require.NoError(t, s.UpdateEvent(hank, e.ID, &upd))
+		events, err = s.GetEventsForDay(ivan, initDate, storage.EventFilter{})
+		require.NoError(t, err)
+		require.Equal(t, 1, len(events))
+	})
+
+	t.Run("attachments", func(t *testing.T) {
+		initDate := time.Date(2300, 01, 01, 10, 0, 0, 0, time.UTC)
+		alice := storage.ContextWithActor(context.Background(), "alice")
+		bob := storage.ContextWithActor(context.Background(), "bob")
+		s := createStorage(t)
+		c := storage.Calendar{Name: "Work", OwnerID: "alice", TimeZone: "UTC"}
+		require.NoError(t, s.CreateCalendar(alice, &c))
+		e := storage.Event{
+			Title: "Planning", StartTime: initDate, EndTime: initDate.Add(time.Hour), OwnerID: "alice", CalendarID: c.ID,
+		}
+		require.NoError(t, s.AddEvent(alice, &e))
+
+		agenda := storage.Attachment{EventID: e.ID, Name: "agenda.txt", Size: 6, BlobKey: "key"}
+		require.NoError(t, s.AddAttachment(alice, &agenda))
+		require.NotEmpty(t, agenda.ID)
+		link := storage.Attachment{EventID: e.ID, Name: "Notes", URL: "https://example.com/notes"}
+		require.NoError(t, s.AddAttachment(alice, &link))
+		require.ErrorIs(t, s.AddAttachment(bob, &storage.Attachment{EventID: e.ID, Name: "x"}), storage.ErrNotFoundEvent)
+		require.ErrorIs(t, s.CheckAttachmentWrite(bob, e.ID), storage.ErrNotFoundEvent)
+		require.NoError(t, s.CheckAttachmentWrite(alice, e.ID))
+		require.ErrorIs(t, s.AddAttachment(alice, &storage.Attachment{EventID: "unknown"}), storage.ErrNotFoundEvent)
+
+		attachments, err := s.GetAttachments(alice, e.ID)
+		require.NoError(t, err)
+		require.Equal(t, 2, len(attachments))
+		require.Equal(t, agenda.ID, attachments[0].ID)
+		require.Equal(t, "key", attachments[0].BlobKey)
+		require.Equal(t, link.URL, attachments[1].URL)
+		_, err = s.GetAttachment(bob, agenda.ID)
+		require.ErrorIs(t, err, storage.ErrNotFoundEvent)
+
+		require.NoError(t, s.ShareCalendar(alice, storage.Grant{
+			CalendarID: c.ID, UserID: "bob", Access: storage.AccessRead,
+		}))
+		a, err := s.GetAttachment(bob, agenda.ID)
+		require.NoError(t, err)
+		require.Equal(t, agenda.Name, a.Name)
+		require.ErrorIs(t, s.RemoveAttachment(bob, agenda.ID), storage.ErrAccessDenied)
+		require.ErrorIs(t, s.CheckAttachmentWrite(bob, e.ID), storage.ErrAccessDenied)
+
+		require.NoError(t, s.RemoveAttachment(alice, agenda.ID))
+		_, err = s.GetAttachment(alice, agenda.ID)
+		require.ErrorIs(t, err, storage.ErrNotFoundAttachment)
+		require.ErrorIs(t, s.RemoveAttachment(alice, agenda.ID), storage.ErrNotFoundAttachment)
+
+		minutes := storage.Attachment{EventID: e.ID, Name: "minutes.txt", Size: 7, BlobKey: "minutes"}
+		require.NoError(t, s.AddAttachment(alice, &minutes))
+		require.NoError(t, s.RemoveEvent(alice, e.ID, 0))
+		_, err = s.GetAttachments(alice, e.ID)
+		require.ErrorIs(t, err, storage.ErrNotFoundEvent)
+		keys, err := s.TakeOrphanBlobs(alice, 10)
+		require.NoError(t, err)
+		require.Empty(t, keys)
+		require.NoError(t, s.PurgeDeleted(alice, initDate.AddDate(1000, 0, 0)))
+		keys, err = s.TakeOrphanBlobs(alice, 10)
+		require.NoError(t, err)
+		require.Equal(t, []string{"minutes"}, keys)
+
+		review := storage.Event{
+			Title: "Review", StartTime: initDate, EndTime: initDate.Add(time.Hour), OwnerID: "alice", CalendarID: c.ID,
+		}
+		require.NoError(t, s.AddEvent(alice, &review))
+		slides := storage.Attachment{EventID: review.ID, Name: "slides.pdf", Size: 9, BlobKey: "slides"}
+		require.NoError(t, s.AddAttachment(alice, &slides))
+		require.NoError(t, s.AddAttachment(alice, &storage.Attachment{EventID: review.ID, Name: "Doc", URL: "https://x.io"}))
+		require.NoError(t, s.RemoveCalendar(alice, c.ID))
+		keys, err = s.TakeOrphanBlobs(alice, 10)
+		require.NoError(t, err)
+		require.Equal(t, []string{"slides"}, keys)
+		keys, err = s.TakeOrphanBlobs(alice, 10)
+		require.NoError(t, err)
+		require.Empty(t, keys)
+	})
+
+	t.Run("resources", func(t *testing.T) {
+		initDate := time.Date(2300, 01, 01, 10, 0, 0, 0, time.UTC)
+		ctx := context.Background()
+		s := createStorage(t)
+		room := storage.Resource{Name: "Blue room", Type: storage.ResourceRoom, Capacity: 8}
+		require.NoError(t, s.CreateResource(ctx, &room))
+		projector := storage.Resource{Name: "Projector", Type: storage.ResourceEquipment}
+		require.NoError(t, s.CreateResource(ctx, &projector))
+		resources, err := s.GetResources(ctx, storage.ResourceFilter{Type: storage.ResourceRoom, MinCapacity: 5})
+		require.NoError(t, err)
+		require.Equal(t, []storage.Resource{room}, resources)
+
+		meeting := storage.Event{
+			Title: "Meeting", StartTime: initDate, EndTime: initDate.Add(time.Hour),
+			ResourceIDs: []string{room.ID, projector.ID, room.ID},
+		}
+		require.NoError(t, s.AddEvent(ctx, &meeting))
+		require.Equal(t, 2, len(meeting.ResourceIDs))
+		overlapping := storage.Event{
+			Title: "Overlapping", StartTime: initDate.Add(30 * time.Minute), EndTime: initDate.Add(2 * time.Hour),
+			ResourceIDs: []string{room.ID},
+		}
+		require.ErrorIs(t, s.AddEvent(ctx, &overlapping), storage.ErrResourceBusy)
+		allDay := storage.Event{Title: "Offsite", StartTime: initDate, AllDay: true, ResourceIDs: []string{projector.ID}}
+		require.ErrorIs(t, s.AddEvent(ctx, &allDay), storage.ErrResourceBusy)
+		unknown := storage.Event{
+			Title: "Unknown", StartTime: initDate, EndTime: initDate.Add(time.Hour), ResourceIDs: []string{"unknown"},
+		}
+		require.ErrorIs(t, s.AddEvent(ctx, &unknown), storage.ErrNotFoundResource)
+
+		next := storage.Event{
+			Title: "Next", StartTime: initDate.Add(time.Hour), EndTime: initDate.Add(2 * time.Hour),
+			ResourceIDs: []string{room.ID},
+		}
+		require.NoError(t, s.AddEvent(ctx, &next))
+		next.StartTime = initDate.Add(30 * time.Minute)
+		require.ErrorIs(t, s.UpdateEvent(ctx, next.ID, &next), storage.ErrResourceBusy)
+		meeting.EndTime = initDate.Add(30 * time.Minute)
+		require.NoError(t, s.UpdateEvent(ctx, meeting.ID, &meeting))
+
+		schedule, err := s.GetResourceSchedule(ctx, room.ID, initDate, initDate.Add(24*time.Hour))
+		require.NoError(t, err)
+		require.Equal(t, 2, len(schedule))
+		require.Equal(t, meeting.ID, schedule[0].EventID)
+		require.Equal(t, next.ID, schedule[1].EventID)
+		require.True(t, initDate.Add(time.Hour).Equal(schedule[1].Start))
+		_, err = s.GetResourceSchedule(ctx, "unknown", initDate, initDate.Add(time.Hour))
+		require.ErrorIs(t, err, storage.ErrNotFoundResource)
+
+		later := initDate.Add(3 * time.Hour)
+		results, err := s.AddEvents(ctx, []storage.Event{
+			{Title: "A", StartTime: later, EndTime: later.Add(time.Hour), ResourceIDs: []string{room.ID}},
+			{Title: "B", StartTime: later, EndTime: later.Add(time.Hour), ResourceIDs: []string{room.ID}},
+		}, storage.BatchOptions{})
+		require.NoError(t, err)
+		require.NoError(t, results[0].Err)
+		require.ErrorIs(t, results[1].Err, storage.ErrResourceBusy)
+
+		require.NoError(t, s.RemoveEvent(ctx, next.ID, 0))
+		overlapping.EndTime = initDate.Add(90 * time.Minute)
+		overlapping.StartTime = initDate.Add(time.Hour)
+		require.NoError(t, s.AddEvent(ctx, &overlapping))
+		require.ErrorIs(t, s.RestoreEvent(ctx, next.ID), storage.ErrResourceBusy)
+
+		require.NoError(t, s.RemoveResource(ctx, room.ID))
+		require.ErrorIs(t, s.RemoveResource(ctx, room.ID), storage.ErrNotFoundResource)
+		require.NoError(t, s.RestoreEvent(ctx, next.ID))
+		events, err := s.GetEventsForDay(ctx, initDate, storage.EventFilter{})
+		require.NoError(t, err)
+		for _, e := range events {
+			require.NotContains(t, e.ResourceIDs, room.ID)
+		}
+	})
+
+	t.Run("feed", func(t *testing.T) {
+		initDate := time.Date(2300, 01, 01, 10, 0, 0, 0, time.UTC)
+		dave := storage.ContextWithActor(context.Background(), "dave")
+		s := createStorage(t)
+		_, err := s.GetFeedUser(dave, "hash")
+		require.ErrorIs(t, err, storage.ErrNotFoundFeedToken)
+		require.NoError(t, s.SetFeedToken(dave, "dave", "hash"))
+		require.NoError(t, s.SetFeedToken(dave, "dave", "new-hash"))
+		_, err = s.GetFeedUser(dave, "hash")
+		require.ErrorIs(t, err, storage.ErrNotFoundFeedToken)
+		userID, err := s.GetFeedUser(dave, "new-hash")
+		require.NoError(t, err)
+		require.Equal(t, "dave", userID)
+
+		c := storage.Calendar{Name: "Team", OwnerID: "bob", TimeZone: "UTC"}
+		bob := storage.ContextWithActor(context.Background(), "bob")
+		require.NoError(t, s.CreateCalendar(bob, &c))
+		require.NoError(t, s.ShareCalendar(bob, storage.Grant{
+			CalendarID: c.ID, UserID: "dave", Access: storage.AccessRead,
+		}))
+		own := storage.Event{Title: "Own", StartTime: initDate, EndTime: initDate.Add(time.Hour), OwnerID: "dave"}
+		require.NoError(t, s.AddEvent(dave, &own))
+		shared := storage.Event{
+			Title: "Shared", StartTime: initDate, EndTime: initDate.Add(time.Hour), OwnerID: "bob", CalendarID: c.ID,
+		}
+		require.NoError(t, s.AddEvent(bob, &shared))
+		foreign := storage.Event{Title: "Foreign", StartTime: initDate, EndTime: initDate.Add(time.Hour), OwnerID: "bob"}
+		require.NoError(t, s.AddEvent(bob, &foreign))
+		removed := storage.Event{Title: "Removed", StartTime: initDate, EndTime: initDate.Add(time.Hour), OwnerID: "dave"}
+		require.NoError(t, s.AddEvent(dave, &removed))
+		require.NoError(t, s.RemoveEvent(dave, removed.ID, 0))
+
+		events, err := s.GetFeedEvents(dave, initDate)
+		require.NoError(t, err)
+		titles := make([]string, 0, len(events))
+		for _, e := range events {
+			titles = append(titles, e.Title)
+		}
+		require.ElementsMatch(t, []string{"Own", "Shared", "Removed"}, titles)
+		events, err = s.GetFeedEvents(dave, initDate.Add(time.Hour))
+		require.NoError(t, err)
+		require.Equal(t, 0, len(events))
+
+		require.NoError(t, s.RemoveFeedToken(dave, "dave"))
+		require.ErrorIs(t, s.RemoveFeedToken(dave, "dave"), storage.ErrNotFoundFeedToken)
+		_, err = s.GetFeedUser(dave, "new-hash")
+		require.ErrorIs(t, err, storage.ErrNotFoundFeedToken)
+	})
+
 	t.Run("list", func(t *testing.T) {
 		initDate := time.Date(2300, 01, 01, 0, 0, 0, 0, time.UTC)
 		e := storage.Event{
@@ -139,22 +861,66 @@ func TestStorage(t *testing.T) {
 			e.EndTime = e.EndTime.AddDate(0, 0, 1)
 		}
 
-		list, err := s.GetEventsForDay(context.Background(), initDate)
+		list, err := s.GetEventsForDay(context.Background(), initDate, storage.EventFilter{})
 		require.NoError(t, err)
 		require.Equal(t, len(list), 1)
 
-		list, err = s.GetEventsForWeek(context.Background(), initDate)
+		list, err = s.GetEventsForWeek(context.Background(), initDate, storage.EventFilter{})
 		require.NoError(t, err)
 		require.Equal(t, len(list), 7)
 
-		list, err = s.GetEventsForMonth(context.Background(), initDate)
+		list, err = s.GetEventsForMonth(context.Background(), initDate, storage.EventFilter{})
 		require.NoError(t, err)
 		require.Equal(t, len(list), 31)
 
-		list, err = s.GetEventsForMonth(context.Background(), initDate.AddDate(0, 1, 0))
+		list, err = s.GetEventsForMonth(context.Background(), initDate.AddDate(0, 1, 0), storage.EventFilter{})
 		require.NoError(t, err)
 		require.Equal(t, len(list), 28)
 	})
+	t.Run("list with filter", func(t *testing.T) {
+		initDate := time.Date(2300, 01, 01, 0, 0, 0, 0, time.UTC)
+		s := createStorage(t)
+
+		labels := []struct {
+			category string
+			tags     []string
+		}{
+			{category: "meeting", tags: []string{"work", "weekly"}},
+			{category: "meeting", tags: []string{"work"}},
+			{category: "vacation", tags: []string{"family"}},
+			{category: "", tags: nil},
+		}
+		for i, l := range labels {
+			e := storage.Event{
+				Title:     "test",
+				StartTime: initDate.Add(time.Duration(i) * time.Hour),
+				EndTime:   initDate.Add(time.Duration(i+1) * time.Hour),
+				OwnerID:   "testId",
+				Category:  l.category,
+				Tags:      l.tags,
+				Color:     "#00ff00",
+			}
+			require.NoError(t, s.AddEvent(context.Background(), &e))
+		}
+
+		tests := []struct {
+			filter   storage.EventFilter
+			expected int
+		}{
+			{filter: storage.EventFilter{}, expected: 4},
+			{filter: storage.EventFilter{Category: "meeting"}, expected: 2},
+			{filter: storage.EventFilter{Category: "vacation"}, expected: 1},
+			{filter: storage.EventFilter{Tags: []string{"work"}}, expected: 2},
+			{filter: storage.EventFilter{Tags: []string{"work", "weekly"}}, expected: 1},
+			{filter: storage.EventFilter{Category: "vacation", Tags: []string{"work"}}, expected: 0},
+			{filter: storage.EventFilter{Category: "unknown"}, expected: 0},
+		}
+		for _, tt := range tests {
+			list, err := s.GetEventsForDay(context.Background(), initDate, tt.filter)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, len(list), "filter %+v", tt.filter)
+		}
+	})
 }
 
 func TestStorageNegativeCases(t *testing.T) {
@@ -180,46 +946,58 @@ func TestStorageNegativeCases(t *testing.T) {
 		e := storage.Event{ID: "___not_exists___", StartTime: initDate, EndTime: initDate.Add(time.Hour)}
 		s := createStorage(t)
 
-		require.ErrorIs(t, s.UpdateEvent(context.Background(), e.ID, e), storage.ErrNotFoundEvent)
+		require.ErrorIs(t, s.UpdateEvent(context.Background(), e.ID, &e), storage.ErrNotFoundEvent)
 	})
 
 	t.Run("delete not exist event event", func(t *testing.T) {
 		e := storage.Event{ID: "___not_exists___"}
 		s := createStorage(t)
 
-		require.ErrorIs(t, s.RemoveEvent(context.Background(), e.ID), storage.ErrNotFoundEvent)
+		require.ErrorIs(t, s.RemoveEvent(context.Background(), e.ID, 0), storage.ErrNotFoundEvent)
 	})
 
-	t.Run("old event time for insert", func(t *testing.T) {
-		initDate := time.Now().Add(-1 * time.Minute)
-		e := storage.Event{StartTime: initDate.Add(time.Hour), EndTime: initDate}
+	t.Run("restore not deleted event", func(t *testing.T) {
+		initDate := time.Date(2300, 01, 01, 0, 0, 0, 0, time.UTC)
+		e := storage.Event{Title: "test", StartTime: initDate, EndTime: initDate.Add(time.Hour), OwnerID: "testId"}
 		s := createStorage(t)
+		require.NoError(t, s.AddEvent(context.Background(), &e))
 
-		require.ErrorIs(t, s.AddEvent(context.Background(), &e), storage.ErrIncorrectEventTime)
+		require.ErrorIs(t, s.RestoreEvent(context.Background(), e.ID), storage.ErrNotFoundEvent)
 	})
 
-	t.Run("old event time for update", func(t *testing.T) {
-		initDate := time.Now().Add(-1 * time.Minute)
-		e := storage.Event{StartTime: initDate.Add(time.Hour), EndTime: initDate}
+	t.Run("update deleted event", func(t *testing.T) {
+		initDate := time.Date(2300, 01, 01, 0, 0, 0, 0, time.UTC)
+		e := storage.Event{Title: "test", StartTime: initDate, EndTime: initDate.Add(time.Hour), OwnerID: "testId"}
 		s := createStorage(t)
+		require.NoError(t, s.AddEvent(context.Background(), &e))
+		require.NoError(t, s.RemoveEvent(context.Background(), e.ID, 0))
 
-		require.ErrorIs(t, s.UpdateEvent(context.Background(), e.ID, e), storage.ErrIncorrectEventTime)
+		require.ErrorIs(t, s.UpdateEvent(context.Background(), e.ID, &e), storage.ErrNotFoundEvent)
+		require.ErrorIs(t, s.RemoveEvent(context.Background(), e.ID, 0), storage.ErrNotFoundEvent)
 	})
 
-	t.Run("incorrect event time for insert", func(t *testing.T) {
+	t.Run("update event with stale version", func(t *testing.T) {
 		initDate := time.Date(2300, 01, 01, 0, 0, 0, 0, time.UTC)
-		e := storage.Event{StartTime: initDate.Add(time.Hour), EndTime: initDate}
+		e := storage.Event{Title: "test", StartTime: initDate, EndTime: initDate.Add(time.Hour), OwnerID: "testId"}
 		s := createStorage(t)
+		require.NoError(t, s.AddEvent(context.Background(), &e))
+		require.Equal(t, int64(1), e.Version)
 
-		require.ErrorIs(t, s.AddEvent(context.Background(), &e), storage.ErrIncorrectEventTime)
-	})
+		first, second := e, e
+		first.Title = "first"
+		require.NoError(t, s.UpdateEvent(context.Background(), e.ID, &first))
+		require.Equal(t, int64(2), first.Version)
 
-	t.Run("incorrect event time for insert", func(t *testing.T) {
-		initDate := time.Date(2300, 01, 01, 0, 0, 0, 0, time.UTC)
-		e := storage.Event{StartTime: initDate.Add(time.Hour), EndTime: initDate}
-		s := createStorage(t)
+		second.Title = "second"
+		require.ErrorIs(t, s.UpdateEvent(context.Background(), e.ID, &second), storage.ErrVersionConflict)
+		require.ErrorIs(t, s.RemoveEvent(context.Background(), e.ID, e.Version), storage.ErrVersionConflict)
+
+		events, err := s.GetEventsForDay(context.Background(), initDate, storage.EventFilter{})
+		require.NoError(t, err)
+		require.Equal(t, 1, len(events))
+		require.Equal(t, "first", events[0].Title)
 
-		require.ErrorIs(t, s.UpdateEvent(context.Background(), e.ID, e), storage.ErrIncorrectEventTime)
+		require.NoError(t, s.RemoveEvent(context.Background(), e.ID, first.Version))
 	})
 }
 
@@ -230,42 +1008,42 @@ func TestStorageValidateStarDates(t *testing.T) {
 	}{
 		{
 			testFunc: func(s *sqlstorage.Storage) error {
-				_, err := s.GetEventsForWeek(context.Background(), time.Date(2021, 12, 06, 0, 0, 0, 0, time.UTC))
+				_, err := s.GetEventsForWeek(context.Background(), time.Date(2021, 12, 06, 0, 0, 0, 0, time.UTC), storage.EventFilter{})
 				return err
 			},
 			expectedErr: nil,
 		},
 		{
 			testFunc: func(s *sqlstorage.Storage) error {
-				_, err := s.GetEventsForWeek(context.Background(), time.Date(2300, 01, 8, 0, 0, 0, 0, time.UTC))
+				_, err := s.GetEventsForWeek(context.Background(), time.Date(2300, 01, 8, 0, 0, 0, 0, time.UTC), storage.EventFilter{})
 				return err
 			},
 			expectedErr: nil,
 		},
 		{
 			testFunc: func(s *sqlstorage.Storage) error {
-				_, err := s.GetEventsForWeek(context.Background(), time.Date(2300, 01, 29, 0, 0, 0, 0, time.UTC))
+				_, err := s.GetEventsForWeek(context.Background(), time.Date(2300, 01, 29, 0, 0, 0, 0, time.UTC), storage.EventFilter{})
 				return err
 			},
 			expectedErr: nil,
 		},
 		{
 			testFunc: func(s *sqlstorage.Storage) error {
-				_, err := s.GetEventsForMonth(context.Background(), time.Date(2300, 1, 1, 0, 0, 0, 0, time.UTC))
+				_, err := s.GetEventsForMonth(context.Background(), time.Date(2300, 1, 1, 0, 0, 0, 0, time.UTC), storage.EventFilter{})
 				return err
 			},
 			expectedErr: nil,
 		},
 		{
 			testFunc: func(s *sqlstorage.Storage) error {
-				_, err := s.GetEventsForWeek(context.Background(), time.Date(2300, 01, 02, 0, 0, 0, 0, time.UTC))
+				_, err := s.GetEventsForWeek(context.Background(), time.Date(2300, 01, 02, 0, 0, 0, 0, time.UTC), storage.EventFilter{})
 				return err
 			},
 			expectedErr: storage.ErrIncorrectStartDate,
 		},
 		{
 			testFunc: func(s *sqlstorage.Storage) error {
-				_, err := s.GetEventsForMonth(context.Background(), time.Date(2300, 01, 02, 0, 0, 0, 0, time.UTC))
+				_, err := s.GetEventsForMonth(context.Background(), time.Date(2300, 01, 02, 0, 0, 0, 0, time.UTC), storage.EventFilter{})
 				return err
 			},
 			expectedErr: storage.ErrIncorrectStartDate,
@@ -294,7 +1072,7 @@ func cleanupDb() error {
 	}
 	defer db.Close()
 
-	_, err = db.Exec("TRUNCATE TABLE Events")
+	_, err = db.Exec("TRUNCATE TABLE Events, event_history, calendar_grants, calendars, reminders, attachments, resources, feed_tokens, orphan_blobs")
 	if err != nil {
 		return err
 	}
@@ -305,14 +1083,16 @@ func compareEvents(t *testing.T, expected storage.Event, actual storage.Event) {
 	t.Helper()
 	require.True(t, expected.StartTime.Equal(actual.StartTime), "start time is not equals %q != %q", expected.StartTime, actual.StartTime)
 	require.True(t, expected.StartTime.Equal(actual.StartTime), "start time is not equals %q != %q", expected.StartTime, actual.StartTime)
+	require.False(t, actual.UpdatedAt.IsZero(), "update time is not set")
 	expected.StartTime = actual.StartTime
 	expected.EndTime = actual.EndTime
+	expected.UpdatedAt = actual.UpdatedAt
 	require.Equal(t, expected, actual)
 }
 
 func createStorage(t *testing.T) *sqlstorage.Storage {
 	t.Helper()
-	s := sqlstorage.New(sqlstorage.Config{Host: host, Port: port, Database: database, Username: username, Password: password})
+	s := sqlstorage.New(sqlstorage.Config{Host: host, Port: port, Database: database, Username: username, Password: password}, clock.Real)
 	ctx, _ := context.WithTimeout(context.Background(), 5*time.Second)
 	require.NoError(t, s.Connect(ctx))
 	t.Cleanup(func() {
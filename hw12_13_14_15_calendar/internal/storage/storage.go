@@ -8,20 +8,100 @@ import (
 
 var (
 	ErrDuplicateEventID   = errors.New("event with same ID exists")
+	ErrIncorrectEventID   = errors.New("incorrect event ID")
 	ErrNotFoundEvent      = errors.New("event not found")
 	ErrIncorrectStartDate = errors.New("date should be a first day of requested period")
 	ErrIncorrectEventTime = errors.New("incorrect event time")
+	ErrVersionConflict    = errors.New("event was changed by someone else")
+	ErrEmptySearchQuery   = errors.New("search query has no words")
+	ErrIncorrectPeriod    = errors.New("end of period should be after start")
 )
 
 type Storage interface {
 	Connect(ctx context.Context) error
 	Close(ctx context.Context) error
+	// Ping checks that storage is reachable.
+	Ping(ctx context.Context) error
 	AddEvent(ctx context.Context, e *Event) error
-	UpdateEvent(ctx context.Context, id string, e Event) error
-	RemoveEvent(ctx context.Context, id string) error
-	GetEventsForDay(ctx context.Context, date time.Time) ([]Event, error)
-	GetEventsForWeek(ctx context.Context, startDate time.Time) ([]Event, error)
-	GetEventsForMonth(ctx context.Context, startDate time.Time) ([]Event, error)
-	GetEventsByNotifier(ctx context.Context, startTime time.Time, endTime time.Time) ([]Event, error)
-	RemoveAfter(ctx context.Context, time time.Time) error
+	// UpdateEvent checks e.Version against stored one (if not zero) and sets new version to e.
+	// Event keeps its calendar if e.CalendarID is empty, moved event requires write access to both calendars.
+	UpdateEvent(ctx context.Context, id string, e *Event) error
+	// RemoveEvent moves event to trash, version is checked if not zero.
+	RemoveEvent(ctx context.Context, id string, version int64) error
+	RestoreEvent(ctx context.Context, id string) error
+	// AddEvents, UpdateEvents and RemoveEvents process items in one transaction and return result per item,
+	// ErrBatchRolledBack is returned if all-or-nothing batch has failed items.
+	AddEvents(ctx context.Context, events []Event, opts BatchOptions) ([]BatchResult, error)
+	// UpdateEvents takes ID of updated event from Event.ID.
+	UpdateEvents(ctx context.Context, events []Event, opts BatchOptions) ([]BatchResult, error)
+	RemoveEvents(ctx context.Context, refs []EventRef, opts BatchOptions) ([]BatchResult, error)
+	// GetDeletedEvents returns events from trash, empty ownerID means events of all owners.
+	GetDeletedEvents(ctx context.Context, ownerID string) ([]Event, error)
+	GetEventsForDay(ctx context.Context, date time.Time, filter EventFilter) ([]Event, error)
+	GetEventsForWeek(ctx context.Context, startDate time.Time, filter EventFilter) ([]Event, error)
+	GetEventsForMonth(ctx context.Context, startDate time.Time, filter EventFilter) ([]Event, error)
+	// GetReminders returns reminders of active events due in [startTime:endTime),
+	// acknowledged reminders are skipped and snoozed ones are due at snooze time.
+	GetReminders(ctx context.Context, startTime time.Time, endTime time.Time) ([]Reminder, error)
+	// AcknowledgeReminder dismisses current reminder of the event, write access to its calendar is required.
+	AcknowledgeReminder(ctx context.Context, eventID string) error
+	// SnoozeReminder moves current reminder of the event to the time, write access to its calendar is required.
+	SnoozeReminder(ctx context.Context, eventID string, until time.Time) error
+	// SearchEvents returns active events matching title or description, most relevant go first.
+	SearchEvents(ctx context.Context, query SearchQuery) ([]Event, error)
+	// GetFreeBusy returns busy time of owners in [from:to), see MergeBusy.
+	// Access to calendars is not checked as event details are not returned.
+	GetFreeBusy(ctx context.Context, ownerIDs []string, from time.Time, to time.Time) ([]FreeBusy, error)
+	// GetEventHistory returns changes of the event ordered from oldest, changes of invisible event are not returned.
+	// History of purged or removed with calendar event is kept but not returned as access can not be checked.
+	GetEventHistory(ctx context.Context, eventID string) ([]EventChange, error)
+	// PurgeDeleted permanently removes events moved to trash before the time.
+	PurgeDeleted(ctx context.Context, deletedBefore time.Time) error
+
+	// Calendar methods check access of user from context (see ContextWithActor).
+	// Events of calendars are read and changed by methods above with the same check.
+	CreateCalendar(ctx context.Context, c *Calendar) error
+	UpdateCalendar(ctx context.Context, c *Calendar) error
+	// RemoveCalendar removes calendar with its events, only owner can remove calendar.
+	RemoveCalendar(ctx context.Context, id string) error
+	// GetCalendars returns calendars accessible for user with user access level.
+	GetCalendars(ctx context.Context) ([]Calendar, error)
+	// ShareCalendar adds or changes user grant.
+	ShareCalendar(ctx context.Context, g Grant) error
+	RevokeCalendarAccess(ctx context.Context, calendarID string, userID string) error
+	GetCalendarGrants(ctx context.Context, calendarID string) ([]Grant, error)
+
+	// Attachment methods check access to the event: read for getting and write for changes,
+	// attachments of events in trash are not accessible.
+	AddAttachment(ctx context.Context, a *Attachment) error
+	// CheckAttachmentWrite returns the same access error as AddAttachment would,
+	// so content is not saved for the event user can not change.
+	CheckAttachmentWrite(ctx context.Context, eventID string) error
+	// GetAttachments returns attachments of the event ordered by creation.
+	GetAttachments(ctx context.Context, eventID string) ([]Attachment, error)
+	GetAttachment(ctx context.Context, id string) (Attachment, error)
+	RemoveAttachment(ctx context.Context, id string) error
+	// TakeOrphanBlobs returns up to limit keys of content of attachments removed with their events
+	// by PurgeDeleted or RemoveCalendar and forgets them, so the content can be deleted from blob store.
+	TakeOrphanBlobs(ctx context.Context, limit int) ([]string, error)
+
+	// Resources are shared by all users, AddEvent, UpdateEvent, RestoreEvent and batches
+	// return ErrResourceBusy if resource of the event is booked by other overlapping event.
+	CreateResource(ctx context.Context, r *Resource) error
+	// RemoveResource removes the resource and releases its bookings.
+	RemoveResource(ctx context.Context, id string) error
+	// GetResources returns resources ordered by name.
+	GetResources(ctx context.Context, filter ResourceFilter) ([]Resource, error)
+	// GetResourceSchedule returns bookings of the resource in [from:to), see BookingsOf.
+	GetResourceSchedule(ctx context.Context, id string, from time.Time, to time.Time) ([]Booking, error)
+
+	// Feed tokens are kept as hashes, one token per user.
+	// SetFeedToken replaces token of the user, so the previous one stops working.
+	SetFeedToken(ctx context.Context, userID string, tokenHash string) error
+	RemoveFeedToken(ctx context.Context, userID string) error
+	// GetFeedUser returns user of the token or ErrNotFoundFeedToken.
+	GetFeedUser(ctx context.Context, tokenHash string) (string, error)
+	// GetFeedEvents returns events of user from context ending after the time, see FeedEvent.
+	// Events in trash are returned too, so the feed can mark them cancelled.
+	GetFeedEvents(ctx context.Context, endAfter time.Time) ([]Event, error)
 }
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/clock"
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/config"
 	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/storage"
 	memorystorage "github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/storage/memory"
 	sqlstorage "github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/storage/sql"
@@ -15,12 +17,19 @@ type Config struct {
 	Database    sqlstorage.Config
 }
 
-func NewStorage(config Config) (storage.Storage, error) {
+func (c Config) Validate(p *config.Problems, prefix string) {
+	p.OneOf(prefix+".storageType", c.StorageType, "memory", "sql")
+	if c.StorageType == "sql" {
+		c.Database.Validate(p, prefix+".database")
+	}
+}
+
+func NewStorage(config Config, clk clock.Clock) (storage.Storage, error) {
 	switch config.StorageType {
 	case "memory":
-		return memorystorage.New(), nil
+		return memorystorage.New(clk), nil
 	case "sql":
-		s := sqlstorage.New(config.Database)
+		s := sqlstorage.New(config.Database, clk)
 		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 		defer cancel()
 		err := s.Connect(ctx)
@@ -0,0 +1,192 @@
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	tagNameValidate = "validate"
+	tagValueIn      = "in"
+	tagValueMax     = "max"
+	tagValueMin     = "min"
+	tagValueMaxLen  = "maxlen"
+	tagValueReq     = "required"
+	tagValueRegexp  = "regexp"
+)
+
+var (
+	ErrRequired         = errors.New("value is required")
+	ErrTooLong          = errors.New("value is too long")
+	ErrNotMatchRegexp   = errors.New("value does not match pattern")
+	ErrNotFoundInList   = errors.New("value is not allowed")
+	ErrIncorrectNumeric = errors.New("incorrect numeric value")
+	ErrIncorrectTag     = errors.New("incorrect tag")
+	ErrIncorrectStruct  = errors.New("incorrect struct")
+)
+
+// ValidationError is a violation of the field rule, Field is a json name of the field if it is set.
+type ValidationError struct {
+	Field string
+	Err   error
+}
+
+type ValidationErrors []ValidationError
+
+func (v ValidationErrors) Error() string {
+	b := strings.Builder{}
+	for i, e := range v {
+		if i > 0 {
+			b.WriteString("; ")
+		}
+		b.WriteString(e.Field + ": " + e.Err.Error())
+	}
+	return b.String()
+}
+
+type rule struct {
+	name  string
+	value string
+}
+
+// Validate checks fields of the struct by rules of `validate` tag separated by '|':
+// required, maxlen:N (in runes), min:N, max:N, in:a,b and regexp:R.
+// Rules of slice field are applied to every element.
+// Returns ValidationErrors if the struct is invalid and other error if tags are incorrect.
+func Validate(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if v == nil || rv.Kind() != reflect.Struct {
+		return ErrIncorrectStruct
+	}
+	t := rv.Type()
+
+	var validationErrors ValidationErrors
+	for i := 0; i < rv.NumField(); i++ {
+		tag, ok := t.Field(i).Tag.Lookup(tagNameValidate)
+		if !ok || tag == "" {
+			continue
+		}
+		rules, err := parseRules(tag)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", t.Field(i).Name, err)
+		}
+		name := fieldName(t.Field(i))
+		field := rv.Field(i)
+		values := []reflect.Value{field}
+		if field.Kind() == reflect.Slice || field.Kind() == reflect.Array {
+			values = values[:0]
+			for j := 0; j < field.Len(); j++ {
+				values = append(values, field.Index(j))
+			}
+		}
+		for _, value := range values {
+			for _, r := range rules {
+				violation, err := check(value, r)
+				if err != nil {
+					return fmt.Errorf("field %s: %w", t.Field(i).Name, err)
+				}
+				if violation != nil {
+					validationErrors = append(validationErrors, ValidationError{Field: name, Err: violation})
+				}
+			}
+		}
+	}
+
+	if len(validationErrors) == 0 {
+		return nil
+	}
+	return validationErrors
+}
+
+func fieldName(f reflect.StructField) string {
+	name := strings.Split(f.Tag.Get("json"), ",")[0]
+	if name == "" || name == "-" {
+		return f.Name
+	}
+	return name
+}
+
+func parseRules(tag string) ([]rule, error) {
+	var rules []rule
+	for _, s := range strings.Split(tag, "|") {
+		parts := strings.SplitN(s, ":", 2)
+		r := rule{name: parts[0]}
+		if len(parts) == 2 {
+			r.value = parts[1]
+		}
+		if (r.name == tagValueReq) != (len(parts) == 1) {
+			return nil, fmt.Errorf("%w: %q", ErrIncorrectTag, s)
+		}
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+// Returns violation of the rule or error if the rule can not be applied to the value.
+func check(value reflect.Value, r rule) (violation error, err error) {
+	kind := value.Kind()
+	if kind == reflect.String {
+		return checkString(value.String(), r)
+	}
+	if kind >= reflect.Int && kind <= reflect.Int64 {
+		return checkInt(value.Int(), r)
+	}
+	return nil, fmt.Errorf("%w: %s is not supported for %s", ErrIncorrectTag, r.name, kind)
+}
+
+func checkString(s string, r rule) (violation error, err error) {
+	switch r.name {
+	case tagValueReq:
+		if strings.TrimSpace(s) == "" {
+			return ErrRequired, nil
+		}
+	case tagValueMaxLen:
+		n, err := strconv.Atoi(r.value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrIncorrectTag, err)
+		}
+		if utf8.RuneCountInString(s) > n {
+			return fmt.Errorf("%w: max length is %d", ErrTooLong, n), nil
+		}
+	case tagValueIn:
+		for _, allowed := range strings.Split(r.value, ",") {
+			if s == allowed {
+				return nil, nil
+			}
+		}
+		return fmt.Errorf("%w: should be one of %s", ErrNotFoundInList, r.value), nil
+	case tagValueRegexp:
+		re, err := regexp.Compile(r.value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrIncorrectTag, err)
+		}
+		if !re.MatchString(s) {
+			return fmt.Errorf("%w %s", ErrNotMatchRegexp, r.value), nil
+		}
+	default:
+		return nil, fmt.Errorf("%w: %s is not supported for string", ErrIncorrectTag, r.name)
+	}
+	return nil, nil
+}
+
+func checkInt(v int64, r rule) (violation error, err error) {
+	if r.name != tagValueMin && r.name != tagValueMax {
+		return nil, fmt.Errorf("%w: %s is not supported for int", ErrIncorrectTag, r.name)
+	}
+	limit, err := strconv.ParseInt(r.value, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrIncorrectTag, err)
+	}
+	if r.name == tagValueMin && v < limit {
+		return fmt.Errorf("%w: should be at least %d", ErrIncorrectNumeric, limit), nil
+	}
+	if r.name == tagValueMax && v > limit {
+		return fmt.Errorf("%w: should be at most %d", ErrIncorrectNumeric, limit), nil
+	}
+	return nil, nil
+}
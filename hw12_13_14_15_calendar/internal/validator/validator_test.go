@@ -0,0 +1,83 @@
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type (
+	User struct {
+		Name   string   `json:"name" validate:"required|maxlen:5"`
+		Age    int      `validate:"min:18|max:50"`
+		Role   string   `json:"role,omitempty" validate:"in:admin,stuff"`
+		Phones []string `json:"phones" validate:"regexp:^\\d{3}$"`
+		Note   string
+	}
+
+	BadTag struct {
+		Name string `validate:"maxlen:x"`
+	}
+
+	UnsupportedRule struct {
+		Flag bool `validate:"required"`
+	}
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		in          interface{}
+		expectedErr error
+	}{
+		{
+			in: User{Name: "Ann", Age: 20, Role: "admin", Phones: []string{"123"}},
+		},
+		{
+			in: User{Name: "Андрей", Age: 51, Role: "user", Phones: []string{"123", "12a"}},
+			expectedErr: ValidationErrors{
+				{Field: "name", Err: ErrTooLong},
+				{Field: "Age", Err: ErrIncorrectNumeric},
+				{Field: "role", Err: ErrNotFoundInList},
+				{Field: "phones", Err: ErrNotMatchRegexp},
+			},
+		},
+		{
+			in: User{Name: " ", Age: 17, Role: "stuff"},
+			expectedErr: ValidationErrors{
+				{Field: "name", Err: ErrRequired},
+				{Field: "Age", Err: ErrIncorrectNumeric},
+			},
+		},
+		{in: BadTag{}, expectedErr: ErrIncorrectTag},
+		{in: UnsupportedRule{}, expectedErr: ErrIncorrectTag},
+		{in: "string", expectedErr: ErrIncorrectStruct},
+		{in: nil, expectedErr: ErrIncorrectStruct},
+	}
+
+	for i, tt := range tests {
+		tt := tt
+		t.Run(fmt.Sprintf("case %d", i), func(t *testing.T) {
+			t.Parallel()
+
+			err := Validate(tt.in)
+			if tt.expectedErr == nil {
+				require.NoError(t, err)
+				return
+			}
+			var expected ValidationErrors
+			if !errors.As(tt.expectedErr, &expected) {
+				require.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			var actual ValidationErrors
+			require.ErrorAs(t, err, &actual)
+			require.Len(t, actual, len(expected))
+			for j := range expected {
+				require.Equal(t, expected[j].Field, actual[j].Field)
+				require.ErrorIs(t, actual[j].Err, expected[j].Err)
+			}
+		})
+	}
+}
@@ -0,0 +1,13 @@
+-- +goose Up
+ALTER TABLE events ADD COLUMN category varchar NOT NULL DEFAULT '';
+ALTER TABLE events ADD COLUMN tags text[] NOT NULL DEFAULT '{}';
+ALTER TABLE events ADD COLUMN color varchar NOT NULL DEFAULT '';
+CREATE INDEX events_category_idx ON events (category);
+CREATE INDEX events_tags_idx ON events USING GIN (tags);
+
+-- +goose Down
+DROP INDEX events_tags_idx;
+DROP INDEX events_category_idx;
+ALTER TABLE events DROP COLUMN color;
+ALTER TABLE events DROP COLUMN tags;
+ALTER TABLE events DROP COLUMN category;
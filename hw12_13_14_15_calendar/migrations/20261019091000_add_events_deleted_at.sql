@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE events ADD COLUMN deleted_at timestamp(0) NULL;
+CREATE INDEX events_deleted_at_idx ON events (deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- +goose Down
+DROP INDEX events_deleted_at_idx;
+ALTER TABLE events DROP COLUMN deleted_at;
@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE events ADD COLUMN version int8 NOT NULL DEFAULT 1;
+ALTER TABLE events ADD COLUMN updated_at timestamp NOT NULL DEFAULT (now() AT TIME ZONE 'utc');
+
+-- +goose Down
+ALTER TABLE events DROP COLUMN updated_at;
+ALTER TABLE events DROP COLUMN version;
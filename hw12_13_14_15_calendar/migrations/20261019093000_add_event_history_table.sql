@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE event_history (
+                               id bigserial NOT NULL,
+                               event_id uuid NOT NULL,
+                               operation varchar NOT NULL,
+                               actor_id varchar NOT NULL,
+                               before_state jsonb NULL,
+                               after_state jsonb NULL,
+                               changed_at timestamp NOT NULL,
+                               CONSTRAINT event_history_pk PRIMARY KEY (id)
+);
+-- +goose StatementEnd
+CREATE INDEX event_history_event_id_idx ON event_history (event_id, id);
+
+-- +goose Down
+DROP INDEX event_history_event_id_idx;
+DROP TABLE event_history;
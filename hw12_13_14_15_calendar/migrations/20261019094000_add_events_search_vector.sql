@@ -0,0 +1,9 @@
+-- +goose Up
+ALTER TABLE events ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('simple', title), 'A') || setweight(to_tsvector('simple', coalesce(description, '')), 'B')
+) STORED;
+CREATE INDEX events_search_vector_idx ON events USING GIN (search_vector);
+
+-- +goose Down
+DROP INDEX events_search_vector_idx;
+ALTER TABLE events DROP COLUMN search_vector;
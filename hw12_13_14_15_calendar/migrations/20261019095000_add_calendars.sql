@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE calendars (
+                               id uuid NOT NULL DEFAULT uuid_generate_v4(),
+                               name varchar NOT NULL,
+                               owner_id varchar NOT NULL,
+                               time_zone varchar NOT NULL DEFAULT 'UTC',
+                               CONSTRAINT calendars_pk PRIMARY KEY (id)
+);
+CREATE TABLE calendar_grants (
+                               calendar_id uuid NOT NULL REFERENCES calendars (id) ON DELETE CASCADE,
+                               user_id varchar NOT NULL,
+                               access varchar NOT NULL,
+                               CONSTRAINT calendar_grants_pk PRIMARY KEY (calendar_id, user_id)
+);
+-- +goose StatementEnd
+CREATE INDEX calendars_owner_id_idx ON calendars (owner_id);
+CREATE INDEX calendar_grants_user_id_idx ON calendar_grants (user_id);
+ALTER TABLE events ADD COLUMN calendar_id uuid NULL REFERENCES calendars (id) ON DELETE CASCADE;
+CREATE INDEX events_calendar_id_idx ON events (calendar_id);
+
+-- +goose Down
+DROP INDEX events_calendar_id_idx;
+ALTER TABLE events DROP COLUMN calendar_id;
+DROP INDEX calendar_grants_user_id_idx;
+DROP INDEX calendars_owner_id_idx;
+DROP TABLE calendar_grants;
+DROP TABLE calendars;
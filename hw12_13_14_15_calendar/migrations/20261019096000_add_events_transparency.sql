@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE events ADD COLUMN transparency varchar NOT NULL DEFAULT '';
+CREATE INDEX events_owner_start_idx ON events (owner_id, start_timestamp);
+
+-- +goose Down
+DROP INDEX events_owner_start_idx;
+ALTER TABLE events DROP COLUMN transparency;
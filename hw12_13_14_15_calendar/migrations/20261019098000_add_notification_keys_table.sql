@@ -0,0 +1,11 @@
+-- +goose Up
+CREATE TABLE notification_keys (
+                               key varchar NOT NULL,
+                               added_at timestamp NOT NULL,
+                               CONSTRAINT notification_keys_pk PRIMARY KEY (key)
+);
+CREATE INDEX notification_keys_added_at_idx ON notification_keys (added_at);
+
+-- +goose Down
+DROP INDEX notification_keys_added_at_idx;
+DROP TABLE notification_keys;
@@ -0,0 +1,13 @@
+-- +goose Up
+CREATE TABLE reminders (
+                               event_id uuid NOT NULL REFERENCES events (id) ON DELETE CASCADE,
+                               remind_at timestamp NOT NULL,
+                               acknowledged boolean NOT NULL DEFAULT false,
+                               snoozed_until timestamp NULL,
+                               CONSTRAINT reminders_pk PRIMARY KEY (event_id)
+);
+CREATE INDEX reminders_snoozed_until_idx ON reminders (snoozed_until);
+
+-- +goose Down
+DROP INDEX reminders_snoozed_until_idx;
+DROP TABLE reminders;
@@ -0,0 +1,17 @@
+-- +goose Up
+CREATE TABLE attachments (
+                               id uuid NOT NULL DEFAULT uuid_generate_v4(),
+                               event_id uuid NOT NULL REFERENCES events (id) ON DELETE CASCADE,
+                               name varchar NOT NULL,
+                               content_type varchar NOT NULL DEFAULT '',
+                               size bigint NOT NULL DEFAULT 0,
+                               url varchar NOT NULL DEFAULT '',
+                               blob_key varchar NOT NULL DEFAULT '',
+                               created_at timestamp NOT NULL,
+                               CONSTRAINT attachments_pk PRIMARY KEY (id)
+);
+CREATE INDEX attachments_event_id_idx ON attachments (event_id, created_at);
+
+-- +goose Down
+DROP INDEX attachments_event_id_idx;
+DROP TABLE attachments;
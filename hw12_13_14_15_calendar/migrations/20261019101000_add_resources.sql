@@ -0,0 +1,17 @@
+-- +goose Up
+CREATE TABLE resources (
+                               id uuid NOT NULL DEFAULT uuid_generate_v4(),
+                               name varchar NOT NULL,
+                               type varchar NOT NULL,
+                               capacity integer NOT NULL DEFAULT 0,
+                               CONSTRAINT resources_pk PRIMARY KEY (id)
+);
+CREATE INDEX resources_type_idx ON resources (type);
+ALTER TABLE events ADD COLUMN resource_ids uuid[] NOT NULL DEFAULT '{}';
+CREATE INDEX events_resource_ids_idx ON events USING GIN (resource_ids);
+
+-- +goose Down
+DROP INDEX events_resource_ids_idx;
+ALTER TABLE events DROP COLUMN resource_ids;
+DROP INDEX resources_type_idx;
+DROP TABLE resources;
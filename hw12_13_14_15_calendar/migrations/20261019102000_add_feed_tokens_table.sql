@@ -0,0 +1,12 @@
+-- +goose Up
+CREATE TABLE feed_tokens (
+                               user_id varchar NOT NULL,
+                               token_hash varchar NOT NULL,
+                               created_at timestamp NOT NULL,
+                               CONSTRAINT feed_tokens_pk PRIMARY KEY (user_id)
+);
+CREATE UNIQUE INDEX feed_tokens_token_hash_idx ON feed_tokens (token_hash);
+
+-- +goose Down
+DROP INDEX feed_tokens_token_hash_idx;
+DROP TABLE feed_tokens;
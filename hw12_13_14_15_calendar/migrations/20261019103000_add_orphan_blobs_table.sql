@@ -0,0 +1,9 @@
+-- +goose Up
+CREATE TABLE orphan_blobs (
+                               blob_key varchar NOT NULL,
+                               removed_at timestamp NOT NULL,
+                               CONSTRAINT orphan_blobs_pk PRIMARY KEY (blob_key)
+);
+
+-- +goose Down
+DROP TABLE orphan_blobs;
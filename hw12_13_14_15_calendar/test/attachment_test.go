@@ -0,0 +1,214 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/api"
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/app"
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/blob"
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/clock"
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/storage"
+	memorystorage "github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/storage/memory"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+func TestAttachments(t *testing.T) {
+	h := startHarness(t)
+	eventID := h.addEvent(t, createEvent())
+	conn, err := grpc.Dial(h.grpcAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	client := api.NewEventsClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	t.Run("gateway", func(t *testing.T) {
+		link := addAttachment(t, h, eventID, `{"attachment": {"name": "Notes", "url": "https://example.com/notes"}}`)
+		require.Equal(t, "https://example.com/notes", link.GetUrl())
+		require.Equal(t, int64(0), link.GetSize())
+		// Content is base64 of "agenda".
+		inline := addAttachment(t, h, eventID, `{"attachment": {"name": "agenda.txt"}, "content": "YWdlbmRh"}`)
+		require.Equal(t, int64(6), inline.GetSize())
+		require.Equal(t, "application/octet-stream", inline.GetContentType())
+		require.Equal(t, "agenda", download(ctx, t, client, inline.GetId()))
+
+		resp := sendRequest(t, "GET", h.gatewayURL, "events/"+eventID+"/attachments", nil)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		body, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		var list api.ListAttachmentsResponse
+		require.NoError(t, protojson.Unmarshal(body, &list))
+		require.Equal(t, 2, len(list.GetAttachments()))
+		require.Equal(t, link.GetId(), list.GetAttachments()[0].GetId())
+
+		resp = sendRequest(t, "DELETE", h.gatewayURL, "attachments/"+inline.GetId(), nil)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		resp = sendRequest(t, "DELETE", h.gatewayURL, "attachments/"+inline.GetId(), nil)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+		require.Equal(t, "ATTACHMENT_NOT_FOUND", decodeError(t, resp).GetReason())
+
+		resp = sendRequest(t, "POST", h.gatewayURL, "events/"+eventID+"/attachments",
+			[]byte(`{"attachment": {"name": "Notes", "url": "example.com"}}`))
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		errResp := decodeError(t, resp)
+		require.Equal(t, 1, len(errResp.GetFieldViolations()))
+		require.Equal(t, "attachment.url", errResp.GetFieldViolations()[0].GetField())
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		content := bytes.Repeat([]byte("0123456789"), 20000)
+		stream, err := client.UploadAttachment(ctx)
+		require.NoError(t, err)
+		require.NoError(t, stream.Send(&api.UploadAttachmentRequest{
+			Data: &api.UploadAttachmentRequest_Attachment{
+				Attachment: &api.Attachment{EventId: eventID, Name: "minutes.txt", ContentType: "text/plain"},
+			},
+		}))
+		for i := 0; i < len(content); i += 30000 {
+			end := i + 30000
+			if end > len(content) {
+				end = len(content)
+			}
+			require.NoError(t, stream.Send(&api.UploadAttachmentRequest{
+				Data: &api.UploadAttachmentRequest_Chunk{Chunk: content[i:end]},
+			}))
+		}
+		resp, err := stream.CloseAndRecv()
+		require.NoError(t, err)
+		require.Equal(t, int64(len(content)), resp.GetAttachment().GetSize())
+		require.Equal(t, "text/plain", resp.GetAttachment().GetContentType())
+		require.Equal(t, string(content), download(ctx, t, client, resp.GetAttachment().GetId()))
+	})
+
+	t.Run("too large", func(t *testing.T) {
+		stream, err := client.UploadAttachment(ctx)
+		require.NoError(t, err)
+		require.NoError(t, stream.Send(&api.UploadAttachmentRequest{
+			Data: &api.UploadAttachmentRequest_Attachment{Attachment: &api.Attachment{EventId: eventID, Name: "big"}},
+		}))
+		chunk := make([]byte, harnessMaxAttachmentSize/4)
+		for i := 0; i < 5; i++ {
+			if err := stream.Send(&api.UploadAttachmentRequest{
+				Data: &api.UploadAttachmentRequest_Chunk{Chunk: chunk},
+			}); err != nil {
+				// Server has already rejected the upload.
+				break
+			}
+		}
+		_, err = stream.CloseAndRecv()
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+		attachments, err := client.ListAttachments(ctx, &api.ListAttachmentsRequest{EventId: eventID})
+		require.NoError(t, err)
+		for _, a := range attachments.GetAttachments() {
+			require.NotEqual(t, "big", a.GetName())
+		}
+	})
+
+	t.Run("download link", func(t *testing.T) {
+		link := addAttachment(t, h, eventID, `{"attachment": {"name": "Doc", "url": "https://example.com/doc"}}`)
+		stream, err := client.DownloadAttachment(ctx, &api.DownloadAttachmentRequest{Id: link.GetId()})
+		require.NoError(t, err)
+		_, err = stream.Recv()
+		require.Equal(t, codes.FailedPrecondition, status.Code(err))
+	})
+
+	t.Run("no access", func(t *testing.T) {
+		// Content is not saved for event the user can not change.
+		blobs := &putCounter{Memory: blob.NewMemory()}
+		calendar := app.New(memorystorage.New(clock.Real), blobs, clock.Real)
+		alice := storage.ContextWithActor(ctx, "alice")
+		c, err := calendar.CreateCalendar(alice, storage.Calendar{Name: "Private", OwnerID: "alice", TimeZone: "UTC"})
+		require.NoError(t, err)
+		start := time.Now().Add(time.Hour)
+		e, err := calendar.CreateEvent(alice, storage.Event{
+			Title: "Secret", StartTime: start, EndTime: start.Add(time.Hour), OwnerID: "alice", CalendarID: c.ID,
+		})
+		require.NoError(t, err)
+		_, err = calendar.AddAttachment(
+			storage.ContextWithActor(ctx, "bob"),
+			storage.Attachment{EventID: e.ID, Name: "agenda.txt"},
+			strings.NewReader("agenda"),
+		)
+		require.ErrorIs(t, err, storage.ErrNotFoundEvent)
+		require.Equal(t, 0, blobs.puts)
+	})
+
+	t.Run("purge", func(t *testing.T) {
+		purgedID := h.addEvent(t, createEvent())
+		inline := addAttachment(t, h, purgedID, `{"attachment": {"name": "agenda.txt"}, "content": "YWdlbmRh"}`)
+		stored, err := h.calendar.Storage.GetAttachment(ctx, inline.GetId())
+		require.NoError(t, err)
+		resp := sendRequest(t, "DELETE", h.gatewayURL, "events/"+purgedID, nil)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		// Scheduler purges the event after trash retention, then sweeper deletes its content.
+		h.advance(t, 2*time.Hour+time.Minute)
+		require.Eventually(t, func() bool {
+			h.clock.Advance(time.Minute)
+			_, err := h.blobs.Get(ctx, stored.BlobKey)
+			return errors.Is(err, blob.ErrNotFound)
+		}, 5*time.Second, 10*time.Millisecond)
+	})
+}
+
+// Counts saved blobs.
+type putCounter struct {
+	*blob.Memory
+	puts int
+}
+
+func (p *putCounter) Put(ctx context.Context, key string, r io.Reader) (int64, error) {
+	p.puts++
+	return p.Memory.Put(ctx, key, r)
+}
+
+func addAttachment(t *testing.T, h *harness, eventID string, body string) *api.Attachment {
+	t.Helper()
+	resp := sendRequest(t, "POST", h.gatewayURL, "events/"+eventID+"/attachments", []byte(body))
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	data, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	var added api.AddAttachmentResponse
+	require.NoError(t, protojson.Unmarshal(data, &added))
+	require.NotEmpty(t, added.GetAttachment().GetId())
+	return added.GetAttachment()
+}
+
+// Returns content of the attachment, attachment itself must go in the first message.
+func download(ctx context.Context, t *testing.T, client api.EventsClient, id string) string {
+	t.Helper()
+	stream, err := client.DownloadAttachment(ctx, &api.DownloadAttachmentRequest{Id: id})
+	require.NoError(t, err)
+	resp, err := stream.Recv()
+	require.NoError(t, err)
+	require.Equal(t, id, resp.GetAttachment().GetId())
+	var content bytes.Buffer
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return content.String()
+		}
+		require.NoError(t, err)
+		content.Write(resp.GetChunk())
+	}
+}
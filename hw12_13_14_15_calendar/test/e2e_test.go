@@ -0,0 +1,117 @@
+package test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/broker"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEndToEnd(t *testing.T) {
+	t.Run("reminder is delivered", func(t *testing.T) {
+		h := startHarness(t)
+
+		event := createEvent()
+		event.StartTime = h.clock.Now().Add(48 * time.Hour)
+		event.EndTime = event.StartTime.Add(time.Hour)
+		event.NotifyBefore = 1
+		id := h.addEvent(t, event)
+		remindAt := event.StartTime.AddDate(0, 0, -1)
+
+		h.advance(t, 23*time.Hour)
+		require.Never(t, func() bool {
+			return len(h.notifications) > 0
+		}, 300*time.Millisecond, 10*time.Millisecond)
+
+		h.advance(t, 2*time.Hour)
+		m := h.receive(t)
+		require.Equal(t, id, m.ID)
+		require.Equal(t, event.Title, m.Name)
+		require.True(t, remindAt.Equal(m.RemindAt))
+		require.Equal(t, broker.MessageKey(id, remindAt), m.Key)
+
+		h.advance(t, 48*time.Hour)
+		require.Never(t, func() bool {
+			return len(h.notifications) > 0
+		}, 300*time.Millisecond, 10*time.Millisecond)
+	})
+
+	t.Run("snoozed reminder is delivered again", func(t *testing.T) {
+		h := startHarness(t)
+
+		event := createEvent()
+		event.StartTime = h.clock.Now().Add(48 * time.Hour)
+		event.EndTime = event.StartTime.Add(time.Hour)
+		event.NotifyBefore = 1
+		id := h.addEvent(t, event)
+		h.advance(t, 25*time.Hour)
+		require.Equal(t, id, h.receive(t).ID)
+
+		resp := sendRequest(t, "POST", h.gatewayURL, "events/"+id+"/reminder:snooze", []byte(`{"minutes": 30}`))
+		defer resp.Body.Close()
+		require.Equal(t, 200, resp.StatusCode)
+		snoozedUntil := h.clock.Now().Add(30 * time.Minute)
+
+		h.advance(t, 20*time.Minute)
+		require.Never(t, func() bool {
+			return len(h.notifications) > 0
+		}, 300*time.Millisecond, 10*time.Millisecond)
+		h.advance(t, 20*time.Minute)
+		m := h.receive(t)
+		require.Equal(t, id, m.ID)
+		require.True(t, snoozedUntil.Equal(m.RemindAt))
+	})
+
+	t.Run("acknowledged reminder is not delivered", func(t *testing.T) {
+		h := startHarness(t)
+
+		acknowledged := createEvent()
+		acknowledged.StartTime = h.clock.Now().Add(48 * time.Hour)
+		acknowledged.EndTime = acknowledged.StartTime.Add(time.Hour)
+		acknowledged.NotifyBefore = 1
+		acknowledgedID := h.addEvent(t, acknowledged)
+		other := acknowledged
+		other.StartTime = acknowledged.StartTime.Add(time.Hour)
+		other.EndTime = other.StartTime.Add(time.Hour)
+		otherID := h.addEvent(t, other)
+
+		resp := sendRequest(t, "POST", h.gatewayURL, "events/"+acknowledgedID+"/reminder:acknowledge", nil)
+		defer resp.Body.Close()
+		require.Equal(t, 200, resp.StatusCode)
+
+		h.advance(t, 26*time.Hour)
+		require.Equal(t, otherID, h.receive(t).ID)
+		require.Never(t, func() bool {
+			return len(h.notifications) > 0
+		}, 300*time.Millisecond, 10*time.Millisecond)
+	})
+}
+
+// Adds the event through HTTP gateway and returns its ID.
+func (h *harness) addEvent(t *testing.T, event testEvent) string {
+	t.Helper()
+	jsonStr, err := json.Marshal(apiStruct{Event: event})
+	require.NoError(t, err)
+	resp := sendRequest(t, "POST", h.gatewayURL, "events", jsonStr)
+	defer resp.Body.Close()
+	require.Equal(t, 200, resp.StatusCode)
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err, "failed to read body")
+	var added apiStruct
+	require.NoError(t, json.Unmarshal(body, &added), "failed to parse body")
+	return added.Event.ID
+}
+
+func (h *harness) receive(t *testing.T) broker.Message {
+	t.Helper()
+	select {
+	case m := <-h.notifications:
+		return m
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "notification is not delivered")
+		return broker.Message{}
+	}
+}
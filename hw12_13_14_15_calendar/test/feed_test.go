@@ -0,0 +1,94 @@
+package test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/api"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+func TestFeed(t *testing.T) {
+	h := startHarness(t)
+	baseURL := strings.TrimSuffix(h.gatewayURL, "/v1/")
+	alice := map[string]string{"X-User-Id": "alice"}
+
+	resp := sendRequest(t, "POST", h.gatewayURL, "feed/token:rotate", nil)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	e := createEvent()
+	e.OwnerID = "alice"
+	e.Title = "Standup, daily"
+	eventID := h.addEvent(t, e)
+	other := createEvent()
+	other.OwnerID = "bob"
+	other.Title = "Private"
+	h.addEvent(t, other)
+
+	path := rotateFeedToken(t, h, alice)
+	resp = sendRequest(t, "GET", baseURL, path, nil)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "text/calendar; charset=utf-8", resp.Header.Get("Content-Type"))
+	etag := resp.Header.Get("ETag")
+	require.NotEmpty(t, etag)
+	require.Empty(t, resp.Header.Get("Last-Modified"))
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(string(body), "BEGIN:VCALENDAR\r\n"))
+	require.Contains(t, string(body), "UID:"+eventID+"@calendar\r\n")
+	require.Contains(t, string(body), `SUMMARY:Standup\, daily`)
+	require.NotContains(t, string(body), "Private")
+
+	resp = sendRequestWithHeaders(t, "GET", baseURL, path, nil, map[string]string{"If-None-Match": etag})
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusNotModified, resp.StatusCode)
+
+	// Removed event is cancelled in the feed.
+	resp = sendRequestWithHeaders(t, "DELETE", h.gatewayURL, "events/"+eventID, nil, alice)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	resp = sendRequestWithHeaders(t, "GET", baseURL, path, nil, map[string]string{"If-None-Match": etag})
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.NotEqual(t, etag, resp.Header.Get("ETag"))
+
+	newPath := rotateFeedToken(t, h, alice)
+	require.NotEqual(t, path, newPath)
+	resp = sendRequest(t, "GET", baseURL, path, nil)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	resp = sendRequest(t, "GET", baseURL, newPath, nil)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp = sendRequestWithHeaders(t, "DELETE", h.gatewayURL, "feed/token", nil, alice)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	resp = sendRequest(t, "GET", baseURL, newPath, nil)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	resp = sendRequestWithHeaders(t, "DELETE", h.gatewayURL, "feed/token", nil, alice)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	require.Equal(t, "FEED_TOKEN_NOT_FOUND", decodeError(t, resp).GetReason())
+}
+
+// Returns path of the feed with new token of the user.
+func rotateFeedToken(t *testing.T, h *harness, user map[string]string) string {
+	t.Helper()
+	resp := sendRequestWithHeaders(t, "POST", h.gatewayURL, "feed/token:rotate", nil, user)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	data, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	var rotated api.RotateFeedTokenResponse
+	require.NoError(t, protojson.Unmarshal(data, &rotated))
+	require.Equal(t, 64, len(rotated.GetToken()))
+	require.Equal(t, "/feeds/"+rotated.GetToken(), rotated.GetPath())
+	return rotated.GetPath()
+}
@@ -9,6 +9,7 @@ import (
 	"log"
 	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"sort"
 	"strconv"
@@ -18,6 +19,8 @@ import (
 	"github.com/jmoiron/sqlx"
 	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/api"
 	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/app"
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/blob"
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/clock"
 	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/logger"
 	internalgrpc "github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/server/grpc"
 	internalhttp "github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/server/http"
@@ -26,7 +29,9 @@ import (
 	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/storagebuilder"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
@@ -76,7 +81,7 @@ func TestMain(m *testing.M) {
 		storageType = storage
 	}
 
-	grpcGatewayURL = fmt.Sprintf("http://%s/Events/", net.JoinHostPort(httpServerHost, strconv.Itoa(httpServerPort)))
+	grpcGatewayURL = fmt.Sprintf("http://%s/v1/", net.JoinHostPort(httpServerHost, strconv.Itoa(httpServerPort)))
 	httpServerURL = fmt.Sprintf("http://%s/", net.JoinHostPort(httpServerHost, strconv.Itoa(httpServerPort)))
 
 	cleanupDB()
@@ -84,10 +89,11 @@ func TestMain(m *testing.M) {
 	os.Exit(code)
 }
 
-// Wrapper to have own marshalling for duration type.
+// Wrapper to have own marshalling for duration type and int64 (it is string in JSON).
 type testEvent struct {
 	storage.Event
 	NotifyBefore int32 `json:"notifyBefore"`
+	Version      int64 `json:"version,string"`
 }
 
 // For marshaling/unmarshalling JSON.
@@ -105,7 +111,7 @@ func TestStorage(t *testing.T) {
 		jsonStr, err := json.Marshal(apiStruct{Event: event})
 		require.NoError(t, err)
 
-		resp := sendRequest(t, "POST", grpcGatewayURL, "AddEvent", jsonStr)
+		resp := sendRequest(t, "POST", grpcGatewayURL, "events", jsonStr)
 		defer resp.Body.Close()
 
 		require.Equal(t, 200, resp.StatusCode)
@@ -117,6 +123,7 @@ func TestStorage(t *testing.T) {
 		require.NotEmpty(t, actual.Event.ID)
 
 		actual.Event.ID = ""
+		event.Version = 1
 		compareEvents(t, event, actual.Event)
 	})
 
@@ -127,7 +134,7 @@ func TestStorage(t *testing.T) {
 		jsonStr, err := json.Marshal(apiStruct{Event: event})
 		require.NoError(t, err)
 
-		resp := sendRequest(t, "POST", grpcGatewayURL, "AddEvent", jsonStr)
+		resp := sendRequest(t, "POST", grpcGatewayURL, "events", jsonStr)
 		defer resp.Body.Close()
 		require.Equal(t, 200, resp.StatusCode)
 
@@ -145,20 +152,22 @@ func TestStorage(t *testing.T) {
 
 		jsonStr, err = json.Marshal(expected)
 		require.NoError(t, err)
-		updResp := sendRequest(t, "POST", grpcGatewayURL, "UpdateEvent", jsonStr)
+		updResp := sendRequest(t, "PUT", grpcGatewayURL, "events/"+expected.ID, jsonStr)
 		defer updResp.Body.Close()
 
 		require.Equal(t, 200, updResp.StatusCode)
 		body, err = ioutil.ReadAll(updResp.Body)
 		require.NoError(t, err, "failed to read body")
 		require.Equal(t, string(body), "{}")
+		require.Equal(t, `"2"`, updResp.Header.Get("ETag"))
+		expected.Event.Version++
 
 		getResp := sendRequest(
 			t,
-			"POST",
+			"GET",
 			grpcGatewayURL,
-			"GetEventsForDay",
-			[]byte(`{"startDate": "`+expected.Event.StartTime.Local().Format(time.RFC3339)+`"}`),
+			"events:day?startDate="+url.QueryEscape(expected.Event.StartTime.Local().Format(time.RFC3339)),
+			nil,
 		)
 		defer getResp.Body.Close()
 		require.Equal(t, 200, getResp.StatusCode)
@@ -177,7 +186,7 @@ func TestStorage(t *testing.T) {
 		jsonStr, err := json.Marshal(apiStruct{Event: event})
 		require.NoError(t, err)
 
-		resp := sendRequest(t, "POST", grpcGatewayURL, "AddEvent", jsonStr)
+		resp := sendRequest(t, "POST", grpcGatewayURL, "events", jsonStr)
 		defer resp.Body.Close()
 		require.Equal(t, 200, resp.StatusCode)
 
@@ -187,7 +196,7 @@ func TestStorage(t *testing.T) {
 		var got apiStruct
 		require.NoError(t, json.Unmarshal(body, &got), "failed to parse response")
 
-		updResp := sendRequest(t, "POST", grpcGatewayURL, "RemoveEvent", []byte(`{"id": "`+got.Event.ID+`"}`))
+		updResp := sendRequest(t, "DELETE", grpcGatewayURL, "events/"+got.Event.ID, nil)
 		defer updResp.Body.Close()
 
 		require.Equal(t, 200, updResp.StatusCode)
@@ -197,10 +206,10 @@ func TestStorage(t *testing.T) {
 
 		getResp := sendRequest(
 			t,
-			"POST",
+			"GET",
 			grpcGatewayURL,
-			"GetEventsForDay",
-			[]byte(`{"startDate": "`+got.Event.StartTime.Local().Format(time.RFC3339)+`"}`),
+			"events:day?startDate="+url.QueryEscape(got.Event.StartTime.Local().Format(time.RFC3339)),
+			nil,
 		)
 		defer getResp.Body.Close()
 		require.Equal(t, 200, getResp.StatusCode)
@@ -210,6 +219,456 @@ func TestStorage(t *testing.T) {
 		require.NoError(t, json.Unmarshal(body, &actual), "failed to parse response")
 		require.Equal(t, 0, len(actual.Events))
 	})
+	t.Run("restore event", func(t *testing.T) {
+		startServer(t)
+
+		event := createEvent()
+		jsonStr, err := json.Marshal(apiStruct{Event: event})
+		require.NoError(t, err)
+
+		resp := sendRequest(t, "POST", grpcGatewayURL, "events", jsonStr)
+		defer resp.Body.Close()
+		require.Equal(t, 200, resp.StatusCode)
+
+		body, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err, "failed to read body")
+
+		var got apiStruct
+		require.NoError(t, json.Unmarshal(body, &got), "failed to parse response")
+
+		rmResp := sendRequest(t, "DELETE", grpcGatewayURL, "events/"+got.Event.ID, nil)
+		defer rmResp.Body.Close()
+		require.Equal(t, 200, rmResp.StatusCode)
+
+		listResp := sendRequest(t, "GET", grpcGatewayURL, "trash?ownerId=OwnId", nil)
+		defer listResp.Body.Close()
+		require.Equal(t, 200, listResp.StatusCode)
+		body, err = ioutil.ReadAll(listResp.Body)
+		require.NoError(t, err, "failed to read body")
+		var deleted apiStruct
+		require.NoError(t, json.Unmarshal(body, &deleted), "failed to parse response")
+		require.Equal(t, 1, len(deleted.Events))
+		require.NotNil(t, deleted.Events[0].DeletedAt)
+
+		restoreResp := sendRequest(t, "POST", grpcGatewayURL, "events/"+got.Event.ID+":restore", nil)
+		defer restoreResp.Body.Close()
+		require.Equal(t, 200, restoreResp.StatusCode)
+
+		getResp := sendRequest(
+			t,
+			"GET",
+			grpcGatewayURL,
+			"events:day?startDate="+url.QueryEscape(got.Event.StartTime.Local().Format(time.RFC3339)),
+			nil,
+		)
+		defer getResp.Body.Close()
+		require.Equal(t, 200, getResp.StatusCode)
+		body, err = ioutil.ReadAll(getResp.Body)
+		require.NoError(t, err, "failed to read body")
+		var actual apiStruct
+		require.NoError(t, json.Unmarshal(body, &actual), "failed to parse response")
+		require.Equal(t, 1, len(actual.Events))
+		got.Event.Version += 2
+		compareEvents(t, got.Event, actual.Events[0])
+	})
+
+	t.Run("event history", func(t *testing.T) {
+		startServer(t)
+
+		event := createEvent()
+		jsonStr, err := json.Marshal(apiStruct{Event: event})
+		require.NoError(t, err)
+
+		resp := sendRequestWithHeaders(
+			t, "POST", grpcGatewayURL, "events", jsonStr, map[string]string{"X-User-Id": "creator"},
+		)
+		defer resp.Body.Close()
+		require.Equal(t, 200, resp.StatusCode)
+
+		body, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err, "failed to read body")
+		var got apiStruct
+		require.NoError(t, json.Unmarshal(body, &got), "failed to parse response")
+
+		rmResp := sendRequest(t, "DELETE", grpcGatewayURL, "events/"+got.Event.ID, nil)
+		defer rmResp.Body.Close()
+		require.Equal(t, 200, rmResp.StatusCode)
+
+		historyResp := sendRequest(t, "GET", grpcGatewayURL, "events/"+got.Event.ID+"/history", nil)
+		defer historyResp.Body.Close()
+		require.Equal(t, 200, historyResp.StatusCode)
+		body, err = ioutil.ReadAll(historyResp.Body)
+		require.NoError(t, err, "failed to read body")
+
+		var history struct {
+			Changes []struct {
+				Operation string     `json:"operation"`
+				ActorID   string     `json:"actorId"`
+				Before    *testEvent `json:"before"`
+				After     *testEvent `json:"after"`
+			} `json:"changes"`
+		}
+		require.NoError(t, json.Unmarshal(body, &history), "failed to parse response")
+		require.Equal(t, 2, len(history.Changes))
+		require.Equal(t, "create", history.Changes[0].Operation)
+		require.Equal(t, "creator", history.Changes[0].ActorID)
+		require.Nil(t, history.Changes[0].Before)
+		require.Equal(t, "delete", history.Changes[1].Operation)
+		require.Equal(t, "OwnId", history.Changes[1].ActorID)
+		require.NotNil(t, history.Changes[1].After.DeletedAt)
+	})
+
+	t.Run("batch", func(t *testing.T) {
+		startServer(t)
+
+		events := []testEvent{createEvent(), createEvent()}
+		events[1].EndTime = events[1].StartTime
+		jsonStr, err := json.Marshal(struct {
+			Events       []testEvent `json:"events"`
+			AllOrNothing bool        `json:"allOrNothing"`
+		}{Events: events, AllOrNothing: true})
+		require.NoError(t, err)
+
+		type batchResponse struct {
+			Results []struct {
+				Event testEvent  `json:"event"`
+				Code  codes.Code `json:"code"`
+				Error string     `json:"error"`
+			} `json:"results"`
+		}
+		parseBatch := func(resp *http.Response) batchResponse {
+			require.Equal(t, 200, resp.StatusCode)
+			body, err := ioutil.ReadAll(resp.Body)
+			require.NoError(t, err, "failed to read body")
+			var got batchResponse
+			require.NoError(t, json.Unmarshal(body, &got), "failed to parse response")
+			require.Equal(t, 2, len(got.Results))
+			return got
+		}
+
+		resp := sendRequest(t, "POST", grpcGatewayURL, "events:batchAdd", jsonStr)
+		defer resp.Body.Close()
+		got := parseBatch(resp)
+		require.Equal(t, codes.Aborted, got.Results[0].Code)
+		require.Equal(t, codes.InvalidArgument, got.Results[1].Code)
+
+		events[1] = createEvent()
+		jsonStr, err = json.Marshal(apiStruct{Events: events})
+		require.NoError(t, err)
+		addResp := sendRequest(t, "POST", grpcGatewayURL, "events:batchAdd", jsonStr)
+		defer addResp.Body.Close()
+		got = parseBatch(addResp)
+		for i, r := range got.Results {
+			require.Equal(t, codes.OK, r.Code, r.Error)
+			events[i].ID = r.Event.ID
+			events[i].Version = 1
+			compareEvents(t, events[i], r.Event)
+		}
+
+		rmResp := sendRequest(
+			t,
+			"POST",
+			grpcGatewayURL,
+			"events:batchRemove",
+			[]byte(`{"events": [{"id": "`+events[0].ID+`"}, {"id": "`+events[1].ID+`", "version": 5}]}`),
+		)
+		defer rmResp.Body.Close()
+		got = parseBatch(rmResp)
+		require.Equal(t, codes.OK, got.Results[0].Code)
+		require.NotNil(t, got.Results[0].Event.DeletedAt)
+		require.Equal(t, codes.FailedPrecondition, got.Results[1].Code)
+	})
+
+	t.Run("search", func(t *testing.T) {
+		startServer(t)
+
+		events := []testEvent{createEvent(), createEvent()}
+		events[0].Title = "Budget meeting"
+		events[1].Description = "Quarterly budget review"
+		for i := range events {
+			jsonStr, err := json.Marshal(apiStruct{Event: events[i]})
+			require.NoError(t, err)
+			resp := sendRequest(t, "POST", grpcGatewayURL, "events", jsonStr)
+			defer resp.Body.Close()
+			require.Equal(t, 200, resp.StatusCode)
+			body, err := ioutil.ReadAll(resp.Body)
+			require.NoError(t, err, "failed to read body")
+			var got apiStruct
+			require.NoError(t, json.Unmarshal(body, &got), "failed to parse response")
+			events[i].ID = got.Event.ID
+		}
+
+		resp := sendRequest(t, "GET", grpcGatewayURL, "events:search?query=budg*&ownerId=OwnId", nil)
+		defer resp.Body.Close()
+		require.Equal(t, 200, resp.StatusCode)
+		body, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err, "failed to read body")
+		var actual apiStruct
+		require.NoError(t, json.Unmarshal(body, &actual), "failed to parse response")
+		require.Equal(t, 2, len(actual.Events))
+		require.Equal(t, events[0].ID, actual.Events[0].ID)
+		require.Equal(t, events[1].ID, actual.Events[1].ID)
+
+		emptyResp := sendRequest(t, "GET", grpcGatewayURL, "events:search?query=++", nil)
+		defer emptyResp.Body.Close()
+		require.Equal(t, 400, emptyResp.StatusCode)
+	})
+
+	t.Run("all-day event", func(t *testing.T) {
+		startServer(t)
+
+		date := time.Now().UTC().AddDate(0, 0, 2)
+		date = time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+		jsonStr := []byte(`{"event": {"title": "Holiday", "ownerId": "OwnId", "allDay": true, ` +
+			`"startTime": "` + date.Add(10*time.Hour).Format(time.RFC3339) + `"}}`)
+		resp := sendRequest(t, "POST", grpcGatewayURL, "events", jsonStr)
+		defer resp.Body.Close()
+		require.Equal(t, 200, resp.StatusCode)
+
+		getResp := sendRequest(
+			t, "GET", grpcGatewayURL, "events:day?startDate="+url.QueryEscape(date.Format(time.RFC3339)), nil,
+		)
+		defer getResp.Body.Close()
+		require.Equal(t, 200, getResp.StatusCode)
+		body, err := ioutil.ReadAll(getResp.Body)
+		require.NoError(t, err, "failed to read body")
+		var actual apiStruct
+		require.NoError(t, json.Unmarshal(body, &actual), "failed to parse response")
+		require.Equal(t, 1, len(actual.Events))
+		require.True(t, actual.Events[0].AllDay)
+		require.True(t, date.Equal(actual.Events[0].StartTime))
+		require.True(t, date.AddDate(0, 0, 1).Equal(actual.Events[0].EndTime))
+	})
+
+	t.Run("free busy", func(t *testing.T) {
+		startServer(t)
+
+		events := []testEvent{createEvent(), createEvent()}
+		events[1].StartTime = events[0].EndTime.Add(time.Hour)
+		events[1].EndTime = events[1].StartTime.Add(time.Hour)
+		events[1].Transparency = storage.TransparencyFree
+		for i := range events {
+			jsonStr, err := json.Marshal(apiStruct{Event: events[i]})
+			require.NoError(t, err)
+			resp := sendRequest(t, "POST", grpcGatewayURL, "events", jsonStr)
+			defer resp.Body.Close()
+			require.Equal(t, 200, resp.StatusCode)
+		}
+
+		from := events[0].StartTime.Add(-time.Hour)
+		query := url.Values{
+			"ownerIds": []string{"OwnId", "Other"},
+			"from":     []string{from.Format(time.RFC3339Nano)},
+			"to":       []string{from.Add(24 * time.Hour).Format(time.RFC3339Nano)},
+		}
+		resp := sendRequest(t, "GET", grpcGatewayURL, "freebusy?"+query.Encode(), nil)
+		defer resp.Body.Close()
+		require.Equal(t, 200, resp.StatusCode)
+		body, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err, "failed to read body")
+		var actual struct {
+			Users []struct {
+				OwnerID string `json:"ownerId"`
+				Busy    []struct {
+					Start time.Time `json:"start"`
+					End   time.Time `json:"end"`
+				} `json:"busy"`
+			} `json:"users"`
+		}
+		require.NoError(t, json.Unmarshal(body, &actual), "failed to parse response")
+		require.Equal(t, 2, len(actual.Users))
+		require.Equal(t, "OwnId", actual.Users[0].OwnerID)
+		require.Equal(t, 1, len(actual.Users[0].Busy))
+		require.True(t, events[0].StartTime.Equal(actual.Users[0].Busy[0].Start))
+		require.True(t, events[0].EndTime.Equal(actual.Users[0].Busy[0].End))
+		require.Equal(t, "Other", actual.Users[1].OwnerID)
+		require.Equal(t, 0, len(actual.Users[1].Busy))
+
+		badResp := sendRequest(t, "GET", grpcGatewayURL, "freebusy?ownerIds=OwnId", nil)
+		defer badResp.Body.Close()
+		require.Equal(t, 400, badResp.StatusCode)
+
+		event := createEvent()
+		event.Transparency = "maybe"
+		jsonStr, err := json.Marshal(apiStruct{Event: event})
+		require.NoError(t, err)
+		addResp := sendRequest(t, "POST", grpcGatewayURL, "events", jsonStr)
+		defer addResp.Body.Close()
+		require.Equal(t, 400, addResp.StatusCode)
+	})
+
+	t.Run("reminders", func(t *testing.T) {
+		startServer(t)
+
+		jsonStr, err := json.Marshal(apiStruct{Event: createEvent()})
+		require.NoError(t, err)
+		resp := sendRequest(t, "POST", grpcGatewayURL, "events", jsonStr)
+		defer resp.Body.Close()
+		require.Equal(t, 200, resp.StatusCode)
+		body, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err, "failed to read body")
+		var added apiStruct
+		require.NoError(t, json.Unmarshal(body, &added), "failed to parse body")
+
+		cases := []struct {
+			path       string
+			request    string
+			statusCode int
+		}{
+			{"events/" + added.Event.ID + "/reminder:snooze", `{"minutes": 0}`, 400},
+			{"events/" + added.Event.ID + "/reminder:snooze", `{"minutes": 10}`, 200},
+			{"events/" + added.Event.ID + "/reminder:acknowledge", "", 200},
+			{"events/unknown/reminder:acknowledge", "", 404},
+		}
+		for _, c := range cases {
+			resp := sendRequest(t, "POST", grpcGatewayURL, c.path, []byte(c.request))
+			defer resp.Body.Close()
+			require.Equal(t, c.statusCode, resp.StatusCode, c.path)
+		}
+
+		// FailedPrecondition not caused by version mismatch must not be 412.
+		noReminder := createEvent()
+		noReminder.NotifyBefore = 0
+		jsonStr, err = json.Marshal(apiStruct{Event: noReminder})
+		require.NoError(t, err)
+		noReminderResp := sendRequest(t, "POST", grpcGatewayURL, "events", jsonStr)
+		defer noReminderResp.Body.Close()
+		require.Equal(t, 200, noReminderResp.StatusCode)
+		body, err = ioutil.ReadAll(noReminderResp.Body)
+		require.NoError(t, err, "failed to read body")
+		require.NoError(t, json.Unmarshal(body, &added), "failed to parse body")
+		ackResp := sendRequest(t, "POST", grpcGatewayURL, "events/"+added.Event.ID+"/reminder:acknowledge", nil)
+		defer ackResp.Body.Close()
+		require.Equal(t, http.StatusConflict, ackResp.StatusCode)
+		require.Equal(t, "NO_REMINDER", decodeError(t, ackResp).GetReason())
+	})
+
+	t.Run("calendars", func(t *testing.T) {
+		startServer(t)
+		alice := map[string]string{"X-User-Id": "alice"}
+		bob := map[string]string{"X-User-Id": "bob"}
+
+		noUserResp := sendRequest(t, "POST", grpcGatewayURL, "calendars", []byte(`{"calendar": {"name": "Work"}}`))
+		defer noUserResp.Body.Close()
+		require.Equal(t, http.StatusUnauthorized, noUserResp.StatusCode)
+
+		tzResp := sendRequestWithHeaders(
+			t, "POST", grpcGatewayURL, "calendars",
+			[]byte(`{"calendar": {"name": "Work", "timeZone": "Mars/Base"}}`), alice,
+		)
+		defer tzResp.Body.Close()
+		require.Equal(t, http.StatusBadRequest, tzResp.StatusCode)
+
+		resp := sendRequestWithHeaders(
+			t, "POST", grpcGatewayURL, "calendars", []byte(`{"calendar": {"name": "Work"}}`), alice,
+		)
+		defer resp.Body.Close()
+		require.Equal(t, 200, resp.StatusCode)
+		body, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err, "failed to read body")
+		var created struct {
+			Calendar storage.Calendar `json:"calendar"`
+		}
+		require.NoError(t, json.Unmarshal(body, &created), "failed to parse response")
+		require.NotEmpty(t, created.Calendar.ID)
+		require.Equal(t, "alice", created.Calendar.OwnerID)
+		require.Equal(t, "UTC", created.Calendar.TimeZone)
+
+		event := createEvent()
+		event.CalendarID = created.Calendar.ID
+		jsonStr, err := json.Marshal(apiStruct{Event: event})
+		require.NoError(t, err)
+		addResp := sendRequestWithHeaders(t, "POST", grpcGatewayURL, "events", jsonStr, alice)
+		defer addResp.Body.Close()
+		require.Equal(t, 200, addResp.StatusCode)
+		body, err = ioutil.ReadAll(addResp.Body)
+		require.NoError(t, err, "failed to read body")
+		var got apiStruct
+		require.NoError(t, json.Unmarshal(body, &got), "failed to parse response")
+		require.Equal(t, created.Calendar.ID, got.Event.CalendarID)
+
+		notFoundResp := sendRequestWithHeaders(t, "DELETE", grpcGatewayURL, "events/"+got.Event.ID, nil, bob)
+		defer notFoundResp.Body.Close()
+		require.Equal(t, http.StatusNotFound, notFoundResp.StatusCode)
+
+		shareResp := sendRequestWithHeaders(
+			t, "POST", grpcGatewayURL, "calendars/"+created.Calendar.ID+"/grants",
+			[]byte(`{"grant": {"userId": "bob", "access": "read"}}`), alice,
+		)
+		defer shareResp.Body.Close()
+		require.Equal(t, 200, shareResp.StatusCode)
+
+		listResp := sendRequestWithHeaders(t, "GET", grpcGatewayURL, "calendars", nil, bob)
+		defer listResp.Body.Close()
+		require.Equal(t, 200, listResp.StatusCode)
+		body, err = ioutil.ReadAll(listResp.Body)
+		require.NoError(t, err, "failed to read body")
+		var list struct {
+			Calendars []struct {
+				ID     string `json:"id"`
+				Access string `json:"access"`
+			} `json:"calendars"`
+		}
+		require.NoError(t, json.Unmarshal(body, &list), "failed to parse response")
+		require.Equal(t, 1, len(list.Calendars))
+		require.Equal(t, created.Calendar.ID, list.Calendars[0].ID)
+		require.Equal(t, "read", list.Calendars[0].Access)
+
+		deniedResp := sendRequestWithHeaders(t, "DELETE", grpcGatewayURL, "events/"+got.Event.ID, nil, bob)
+		defer deniedResp.Body.Close()
+		require.Equal(t, http.StatusForbidden, deniedResp.StatusCode)
+
+		removeResp := sendRequestWithHeaders(
+			t, "DELETE", grpcGatewayURL, "calendars/"+created.Calendar.ID, nil, alice,
+		)
+		defer removeResp.Body.Close()
+		require.Equal(t, 200, removeResp.StatusCode)
+	})
+
+	t.Run("update with stale version", func(t *testing.T) {
+		startServer(t)
+
+		event := createEvent()
+		jsonStr, err := json.Marshal(apiStruct{Event: event})
+		require.NoError(t, err)
+
+		resp := sendRequest(t, "POST", grpcGatewayURL, "events", jsonStr)
+		defer resp.Body.Close()
+		require.Equal(t, 200, resp.StatusCode)
+		require.Equal(t, `"1"`, resp.Header.Get("ETag"))
+
+		body, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err, "failed to read body")
+		var got apiStruct
+		require.NoError(t, json.Unmarshal(body, &got), "failed to parse response")
+
+		got.ID = got.Event.ID
+		got.Event.Title += "UPD"
+		jsonStr, err = json.Marshal(got)
+		require.NoError(t, err)
+		updResp := sendRequest(t, "PUT", grpcGatewayURL, "events/"+got.ID, jsonStr)
+		defer updResp.Body.Close()
+		require.Equal(t, 200, updResp.StatusCode)
+
+		staleResp := sendRequest(t, "PUT", grpcGatewayURL, "events/"+got.ID, jsonStr)
+		defer staleResp.Body.Close()
+		require.Equal(t, http.StatusPreconditionFailed, staleResp.StatusCode)
+
+		got.Event.Version = 0
+		jsonStr, err = json.Marshal(got)
+		require.NoError(t, err)
+		ifMatchResp := sendRequestWithHeaders(
+			t, "PUT", grpcGatewayURL, "events/"+got.ID, jsonStr, map[string]string{"If-Match": `"1"`},
+		)
+		defer ifMatchResp.Body.Close()
+		require.Equal(t, http.StatusPreconditionFailed, ifMatchResp.StatusCode)
+
+		rmResp := sendRequestWithHeaders(
+			t, "DELETE", grpcGatewayURL, "events/"+got.ID, nil, map[string]string{"If-Match": `"2"`},
+		)
+		defer rmResp.Body.Close()
+		require.Equal(t, 200, rmResp.StatusCode)
+	})
 }
 
 func TestGatewayGetEvents(t *testing.T) {
@@ -224,7 +683,7 @@ func TestGatewayGetEvents(t *testing.T) {
 	for i := 0; i < 60; i++ {
 		jsonStr, err := json.Marshal(apiStruct{Event: event})
 		require.NoError(t, err)
-		resp := sendRequest(t, "POST", grpcGatewayURL, "AddEvent", jsonStr)
+		resp := sendRequest(t, "POST", grpcGatewayURL, "events", jsonStr)
 		require.Equal(t, 200, resp.StatusCode)
 
 		body, err := ioutil.ReadAll(resp.Body)
@@ -243,10 +702,10 @@ func TestGatewayGetEvents(t *testing.T) {
 	t.Run("get day", func(t *testing.T) {
 		resp := sendRequest(
 			t,
-			"POST",
+			"GET",
 			grpcGatewayURL,
-			"GetEventsForDay",
-			[]byte(`{"startDate": "`+initDate.Local().Format(time.RFC3339)+`"}`),
+			"events:day?startDate="+url.QueryEscape(initDate.Local().Format(time.RFC3339)),
+			nil,
 		)
 		defer resp.Body.Close()
 		require.Equal(t, 200, resp.StatusCode)
@@ -262,10 +721,10 @@ func TestGatewayGetEvents(t *testing.T) {
 	t.Run("get week", func(t *testing.T) {
 		resp := sendRequest(
 			t,
-			"POST",
+			"GET",
 			grpcGatewayURL,
-			"GetEventsForWeek",
-			[]byte(`{"startDate": "`+initDate.Local().Format(time.RFC3339)+`"}`),
+			"events:week?startDate="+url.QueryEscape(initDate.Local().Format(time.RFC3339)),
+			nil,
 		)
 		defer resp.Body.Close()
 		require.Equal(t, 200, resp.StatusCode)
@@ -287,10 +746,10 @@ func TestGatewayGetEvents(t *testing.T) {
 	t.Run("get month", func(t *testing.T) {
 		resp := sendRequest(
 			t,
-			"POST",
+			"GET",
 			grpcGatewayURL,
-			"GetEventsForMonth",
-			[]byte(`{"startDate": "`+initDate.Local().Format(time.RFC3339)+`"}`),
+			"events:month?startDate="+url.QueryEscape(initDate.Local().Format(time.RFC3339)),
+			nil,
 		)
 		defer resp.Body.Close()
 		require.Equal(t, 200, resp.StatusCode)
@@ -312,10 +771,10 @@ func TestGatewayGetEvents(t *testing.T) {
 	t.Run("get month 28 days", func(t *testing.T) {
 		resp := sendRequest(
 			t,
-			"POST",
+			"GET",
 			grpcGatewayURL,
-			"GetEventsForMonth",
-			[]byte(`{"startDate": "`+initDate.AddDate(0, 1, 0).Local().Format(time.RFC3339)+`"}`),
+			"events:month?startDate="+url.QueryEscape(initDate.AddDate(0, 1, 0).Local().Format(time.RFC3339)),
+			nil,
 		)
 		defer resp.Body.Close()
 		require.Equal(t, 200, resp.StatusCode)
@@ -335,23 +794,111 @@ func TestGatewayGetEvents(t *testing.T) {
 	})
 }
 
+func TestAPIDocs(t *testing.T) {
+	startServer(t)
+
+	resp := sendRequest(t, "GET", httpServerURL, "openapi.json", nil)
+	defer resp.Body.Close()
+	require.Equal(t, 200, resp.StatusCode)
+	var doc struct {
+		Swagger string                     `json:"swagger"`
+		Paths   map[string]json.RawMessage `json:"paths"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&doc))
+	require.Equal(t, "2.0", doc.Swagger)
+	require.Contains(t, doc.Paths, "/v1/events")
+	require.Contains(t, doc.Paths, "/v1/events/{id}")
+
+	uiResp := sendRequest(t, "GET", httpServerURL, "swagger", nil)
+	defer uiResp.Body.Close()
+	require.Equal(t, 200, uiResp.StatusCode)
+	body, err := ioutil.ReadAll(uiResp.Body)
+	require.NoError(t, err, "failed to read body")
+	require.Contains(t, string(body), "/openapi.json")
+}
+
 func TestGatewayErrors(t *testing.T) {
 	startServer(t)
 
 	t.Run("add no empty event", func(t *testing.T) {
-		resp := sendRequest(t, "POST", grpcGatewayURL, "AddEvent", []byte(`{"event": {}}`))
+		resp := sendRequest(t, "POST", grpcGatewayURL, "events", []byte(`{"event": {}}`))
 		defer resp.Body.Close()
 		require.Equal(t, 400, resp.StatusCode)
 	})
 
 	t.Run("add no event", func(t *testing.T) {
-		resp := sendRequest(t, "POST", grpcGatewayURL, "AddEvent", []byte(`{}`))
+		resp := sendRequest(t, "POST", grpcGatewayURL, "events", []byte(`{}`))
 		defer resp.Body.Close()
 		require.Equal(t, 400, resp.StatusCode)
 	})
 
+	t.Run("add invalid event", func(t *testing.T) {
+		event := createEvent()
+		event.Title = ""
+		event.Color = "blue"
+		event.EndTime = event.StartTime.Add(-time.Minute)
+		jsonStr, err := json.Marshal(apiStruct{Event: event})
+		require.NoError(t, err)
+
+		resp := sendRequest(t, "POST", grpcGatewayURL, "events", jsonStr)
+		defer resp.Body.Close()
+		require.Equal(t, 400, resp.StatusCode)
+		body := decodeError(t, resp)
+		require.Equal(t, "INVALID_ARGUMENT", body.GetCode())
+		require.Equal(t, "invalid event", body.GetMessage())
+		fields := make([]string, 0, len(body.GetFieldViolations()))
+		for _, v := range body.GetFieldViolations() {
+			fields = append(fields, v.GetField())
+		}
+		require.Equal(t, []string{"event.title", "event.color", "event.endTime"}, fields)
+	})
+
+	t.Run("add past event", func(t *testing.T) {
+		event := createEvent()
+		event.StartTime = time.Now().Truncate(time.Second).Add(-time.Hour)
+		jsonStr, err := json.Marshal(apiStruct{Event: event})
+		require.NoError(t, err)
+
+		resp := sendRequest(t, "POST", grpcGatewayURL, "events", jsonStr)
+		defer resp.Body.Close()
+		require.Equal(t, 400, resp.StatusCode)
+		body := decodeError(t, resp)
+		require.Equal(t, 1, len(body.GetFieldViolations()))
+		require.Equal(t, "event.startTime", body.GetFieldViolations()[0].GetField())
+	})
+
+	t.Run("add event with existing ID", func(t *testing.T) {
+		jsonStr, err := json.Marshal(apiStruct{Event: createEvent()})
+		require.NoError(t, err)
+		resp := sendRequest(t, "POST", grpcGatewayURL, "events", jsonStr)
+		defer resp.Body.Close()
+		require.Equal(t, 200, resp.StatusCode)
+		created := apiStruct{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&created))
+
+		jsonStr, err = json.Marshal(apiStruct{Event: created.Event})
+		require.NoError(t, err)
+		dupResp := sendRequest(t, "POST", grpcGatewayURL, "events", jsonStr)
+		defer dupResp.Body.Close()
+		require.Equal(t, 409, dupResp.StatusCode)
+		body := decodeError(t, dupResp)
+		require.Equal(t, "ALREADY_EXISTS", body.GetCode())
+		require.Equal(t, "event with same ID exists", body.GetMessage())
+		require.Equal(t, "DUPLICATE_EVENT_ID", body.GetReason())
+	})
+
 	t.Run("remove non exists event", func(t *testing.T) {
-		resp := sendRequest(t, "POST", grpcGatewayURL, "RemoveEvent", []byte(`{"id": "_non_exists_"}`))
+		resp := sendRequest(t, "DELETE", grpcGatewayURL, "events/_non_exists_", nil)
+		defer resp.Body.Close()
+		require.Equal(t, 404, resp.StatusCode)
+		body := decodeError(t, resp)
+		require.Equal(t, "NOT_FOUND", body.GetCode())
+		require.Equal(t, "event not found", body.GetMessage())
+		require.Equal(t, "EVENT_NOT_FOUND", body.GetReason())
+	})
+
+	t.Run("restore non deleted event", func(t *testing.T) {
+		resp := sendRequest(t, "POST", grpcGatewayURL, "events/_non_exists_:restore", nil)
 		defer resp.Body.Close()
 		require.Equal(t, 404, resp.StatusCode)
 	})
@@ -361,13 +908,35 @@ func TestGatewayErrors(t *testing.T) {
 		jsonStr, err := json.Marshal(apiStruct{ID: "__non_exist__", Event: event})
 		require.NoError(t, err)
 
-		resp := sendRequest(t, "POST", grpcGatewayURL, "UpdateEvent", jsonStr)
+		resp := sendRequest(t, "PUT", grpcGatewayURL, "events/__non_exist__", jsonStr)
 		defer resp.Body.Close()
 		require.Equal(t, 404, resp.StatusCode)
 	})
 }
 
+func decodeError(t *testing.T, resp *http.Response) *api.ErrorResponse {
+	t.Helper()
+	require.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	data, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err, "failed to read body")
+	body := &api.ErrorResponse{}
+	require.NoError(t, protojson.Unmarshal(data, body))
+	return body
+}
+
 func sendRequest(t *testing.T, method string, url string, path string, requestBody []byte) *http.Response {
+	t.Helper()
+	return sendRequestWithHeaders(t, method, url, path, requestBody, nil)
+}
+
+func sendRequestWithHeaders(
+	t *testing.T,
+	method string,
+	url string,
+	path string,
+	requestBody []byte,
+	headers map[string]string,
+) *http.Response {
 	t.Helper()
 	req, err := http.NewRequestWithContext(
 		context.Background(),
@@ -377,6 +946,9 @@ func sendRequest(t *testing.T, method string, url string, path string, requestBo
 	)
 	require.NoError(t, err, "failed to send request")
 	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
 
 	client := &http.Client{}
 	resp, err := client.Do(req)
@@ -398,13 +970,13 @@ func startServer(t *testing.T) {
 			Username: pgUsername,
 			Password: pgPassword,
 		},
-	})
+	}, clock.Real)
 	require.NoError(t, err, "failed to create storage")
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 	require.NoError(t, storage.Connect(ctx))
 
-	calendar := app.New(storage)
+	calendar := app.New(storage, blob.NewMemory(), clock.Real)
 	httpServer := internalhttp.NewServer(internalhttp.Config{
 		Host: httpServerHost,
 		Port: httpServerPort,
@@ -466,6 +1038,10 @@ func createEvent() testEvent {
 			EndTime:     time.Now().Truncate(time.Second).Add(20 * time.Minute),
 			Description: "TestDescription",
 			OwnerID:     "OwnId",
+			Category:    "meeting",
+			Tags:        []string{"work"},
+			Color:       "#0000ff",
+			ResourceIDs: []string{},
 		},
 		NotifyBefore: 1,
 	}
@@ -481,8 +1057,10 @@ func compareEvents(t *testing.T, expected testEvent, actual testEvent) {
 		t,
 		expected.StartTime.Equal(actual.StartTime),
 		"start time is not equals %q != %q", expected.StartTime, actual.StartTime)
+	require.False(t, actual.UpdatedAt.IsZero(), "update time is not set")
 	expected.StartTime = actual.StartTime
 	expected.EndTime = actual.EndTime
+	expected.UpdatedAt = actual.UpdatedAt
 	require.Equal(t, expected, actual)
 }
 
@@ -506,7 +1084,7 @@ func cleanupDB() error {
 	}
 	defer db.Close()
 
-	_, err = db.Exec("TRUNCATE TABLE Events")
+	_, err = db.Exec("TRUNCATE TABLE Events, event_history, calendar_grants, calendars, reminders")
 	if err != nil {
 		return err
 	}
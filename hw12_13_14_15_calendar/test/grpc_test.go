@@ -0,0 +1,51 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/api"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+)
+
+func TestGRPCServices(t *testing.T) {
+	h := startHarness(t)
+	conn, err := grpc.Dial(h.grpcAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	t.Run("health", func(t *testing.T) {
+		client := healthpb.NewHealthClient(conn)
+		for _, service := range []string{"", api.Events_ServiceDesc.ServiceName} {
+			resp, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: service})
+			require.NoError(t, err)
+			require.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.GetStatus())
+		}
+	})
+
+	t.Run("reflection", func(t *testing.T) {
+		stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+		require.NoError(t, err)
+		err = stream.Send(&reflectionpb.ServerReflectionRequest{
+			MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+		})
+		require.NoError(t, err)
+		resp, err := stream.Recv()
+		require.NoError(t, err)
+		require.NoError(t, stream.CloseSend())
+
+		services := make([]string, 0)
+		for _, s := range resp.GetListServicesResponse().GetService() {
+			services = append(services, s.GetName())
+		}
+		require.Contains(t, services, api.Events_ServiceDesc.ServiceName)
+		require.Contains(t, services, healthpb.Health_ServiceDesc.ServiceName)
+	})
+}
@@ -0,0 +1,160 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/app"
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/blob"
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/broker"
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/clock"
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/dedup"
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/leader"
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/scheduler"
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/sender"
+	internalgrpc "github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/server/grpc"
+	internalhttp "github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/server/http"
+	memorystorage "github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/storage/memory"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	harnessCheckInterval     = time.Minute
+	harnessMaxAttachmentSize = 1 << 20
+)
+
+// Harness runs calendar API with blob sweeper, scheduler and sender in the test process
+// with memory storage, in-memory broker and fake clock.
+type harness struct {
+	clock         *clock.Fake
+	calendar      *app.App
+	grpcServer    *internalgrpc.Server
+	blobs         *blob.Memory
+	gatewayURL    string
+	grpcAddr      string
+	notifications chan broker.Message
+}
+
+// Records delivered notifications.
+type notifier chan broker.Message
+
+func (n notifier) Notify(_ context.Context, m broker.Message) error {
+	n <- m
+	return nil
+}
+
+func startHarness(t *testing.T) *harness {
+	t.Helper()
+
+	h := &harness{
+		clock:         clock.NewFake(time.Now().Truncate(time.Second)),
+		blobs:         blob.NewMemory(),
+		notifications: make(chan broker.Message, 100),
+	}
+	stor := memorystorage.New(h.clock)
+	calendar := app.New(stor, h.blobs, h.clock)
+	h.calendar = calendar
+	grpcConfig := internalgrpc.Config{
+		Host:              "127.0.0.1",
+		Port:              freePort(t),
+		Reflection:        true,
+		MaxAttachmentSize: harnessMaxAttachmentSize,
+	}
+	httpConfig := internalhttp.Config{Host: "127.0.0.1", Port: freePort(t)}
+	grpcServer := internalgrpc.NewServer(grpcConfig, calendar)
+	httpServer := internalhttp.NewServer(httpConfig, calendar)
+	h.grpcServer = grpcServer
+	h.grpcAddr = net.JoinHostPort(grpcConfig.Host, strconv.Itoa(grpcConfig.Port))
+	h.gatewayURL = fmt.Sprintf("http://%s/v1/", net.JoinHostPort(httpConfig.Host, strconv.Itoa(httpConfig.Port)))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	grpcDone := make(chan struct{})
+	go func() {
+		defer close(grpcDone)
+		grpcServer.Start(ctx)
+	}()
+	// Gateway connection is established when grpc server listens, otherwise it waits for reconnect.
+	waitListening(t, grpcConfig.Host, grpcConfig.Port)
+	gatewayMux, err := grpcServer.GatewayMux(ctx)
+	require.NoError(t, err, "failed to get gateway mux")
+	httpDone := make(chan struct{})
+	go func() {
+		defer close(httpDone)
+		httpServer.Start(ctx, gatewayMux)
+	}()
+
+	sweeperDone := make(chan struct{})
+	go func() {
+		defer close(sweeperDone)
+		calendar.RunBlobSweeper(ctx, time.Minute, nil)
+	}()
+
+	queue := broker.NewMemory(100)
+	schedulerDone := make(chan struct{})
+	go func() {
+		defer close(schedulerDone)
+		config := scheduler.Config{
+			CheckInterval:  harnessCheckInterval,
+			PurgeInterval:  time.Hour,
+			TrashRetention: time.Hour,
+		}
+		scheduler.New(stor, queue, leader.Single{}, config, h.clock).Run(ctx, nil)
+	}()
+	senderDone := make(chan struct{})
+	go func() {
+		defer close(senderDone)
+		store := dedup.NewMemory(100, time.Hour, h.clock)
+		sender.New(store, notifier(h.notifications), time.Hour, h.clock).Run(ctx, queue, nil)
+	}()
+
+	t.Cleanup(func() {
+		cancel()
+		stopCtx, stopCancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer stopCancel()
+		httpServer.Stop(stopCtx)
+		grpcServer.Stop(stopCtx)
+		for _, done := range []chan struct{}{grpcDone, httpDone, sweeperDone, schedulerDone, senderDone} {
+			<-done
+		}
+	})
+
+	// Scheduler and sweeper tickers are created before the clock is moved.
+	require.Eventually(t, func() bool {
+		return h.clock.Tickers() == 3
+	}, 5*time.Second, 10*time.Millisecond)
+	waitListening(t, httpConfig.Host, httpConfig.Port)
+	return h
+}
+
+// Moves the clock and waits until the scheduler receives the tick, so the next move does not drop it.
+func (h *harness) advance(t *testing.T, d time.Duration) {
+	t.Helper()
+	h.clock.Advance(d)
+	require.Eventually(t, func() bool {
+		return h.clock.Pending() == 0
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func waitListening(t *testing.T, host string, port int) {
+	t.Helper()
+	require.Eventually(t, func() bool {
+		conn, err := net.Dial("tcp", net.JoinHostPort(host, strconv.Itoa(port)))
+		if err != nil {
+			return false
+		}
+		conn.Close()
+		return true
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func freePort(t *testing.T) int {
+	t.Helper()
+	lsn, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer lsn.Close()
+	return lsn.Addr().(*net.TCPAddr).Port
+}
@@ -0,0 +1,39 @@
+package test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/internal/ratelimit"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimit(t *testing.T) {
+	h := startHarness(t)
+	alice := map[string]string{"X-User-Id": "alice"}
+	bob := map[string]string{"X-User-Id": "bob"}
+	status := func(headers map[string]string) int {
+		resp := sendRequestWithHeaders(t, "GET", h.gatewayURL, "resources", nil, headers)
+		defer resp.Body.Close()
+		if resp.StatusCode == http.StatusTooManyRequests {
+			require.Equal(t, "RATE_LIMITED", decodeError(t, resp).GetReason())
+		}
+		return resp.StatusCode
+	}
+
+	h.grpcServer.SetRateLimit(ratelimit.Config{Rate: 1, Burst: 2})
+	require.Equal(t, http.StatusOK, status(alice))
+	require.Equal(t, http.StatusOK, status(alice))
+	require.Equal(t, http.StatusTooManyRequests, status(alice))
+	require.Equal(t, http.StatusOK, status(bob), "users are limited separately")
+
+	h.clock.Advance(time.Second)
+	require.Equal(t, http.StatusOK, status(alice))
+	require.Equal(t, http.StatusTooManyRequests, status(alice))
+
+	h.grpcServer.SetRateLimit(ratelimit.Config{})
+	for i := 0; i < 5; i++ {
+		require.Equal(t, http.StatusOK, status(alice), "limit is disabled")
+	}
+}
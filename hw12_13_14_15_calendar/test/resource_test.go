@@ -0,0 +1,88 @@
+package test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/lomoval/otus-golang/hw12_13_14_15_calendar/api"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestResources(t *testing.T) {
+	h := startHarness(t)
+	room := createResource(t, h, `{"resource": {"name": "Blue room", "type": "room", "capacity": 8}}`)
+	createResource(t, h, `{"resource": {"name": "Projector", "type": "equipment"}}`)
+
+	var list api.ListResourcesResponse
+	getProto(t, h, "resources?type=room&minCapacity=4", &list)
+	require.Equal(t, 1, len(list.GetResources()))
+	require.Equal(t, room.GetId(), list.GetResources()[0].GetId())
+
+	e := createEvent()
+	e.ResourceIDs = []string{room.GetId()}
+	eventID := h.addEvent(t, e)
+
+	e.StartTime = e.StartTime.Add(time.Minute)
+	jsonStr, err := protojson.Marshal(&api.AddEventRequest{Event: &api.Event{
+		Title:       "Overlapping",
+		OwnerId:     "OwnId",
+		StartTime:   timestamppb.New(e.StartTime),
+		EndTime:     timestamppb.New(e.EndTime),
+		ResourceIds: []string{room.GetId()},
+	}})
+	require.NoError(t, err)
+	resp := sendRequest(t, "POST", h.gatewayURL, "events", jsonStr)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusConflict, resp.StatusCode)
+	require.Equal(t, "RESOURCE_BUSY", decodeError(t, resp).GetReason())
+
+	var schedule api.GetResourceScheduleResponse
+	from, to := e.StartTime.Add(-time.Hour).UTC(), e.EndTime.Add(time.Hour).UTC()
+	getProto(t, h, "resources/"+room.GetId()+"/schedule?from="+from.Format(time.RFC3339)+"&to="+to.Format(time.RFC3339),
+		&schedule)
+	require.Equal(t, 1, len(schedule.GetBookings()))
+	require.Equal(t, eventID, schedule.GetBookings()[0].GetEventId())
+
+	resp = sendRequest(t, "DELETE", h.gatewayURL, "resources/"+room.GetId(), nil)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	resp = sendRequest(t, "GET", h.gatewayURL, "resources/"+room.GetId()+"/schedule?from="+from.Format(time.RFC3339)+
+		"&to="+to.Format(time.RFC3339), nil)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	require.Equal(t, "RESOURCE_NOT_FOUND", decodeError(t, resp).GetReason())
+
+	resp = sendRequest(t, "POST", h.gatewayURL, "resources", []byte(`{"resource": {"name": "", "type": "desk"}}`))
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	require.Equal(t, 2, len(decodeError(t, resp).GetFieldViolations()))
+}
+
+func createResource(t *testing.T, h *harness, body string) *api.Resource {
+	t.Helper()
+	resp := sendRequest(t, "POST", h.gatewayURL, "resources", []byte(body))
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	data, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	var created api.CreateResourceResponse
+	require.NoError(t, protojson.Unmarshal(data, &created))
+	require.NotEmpty(t, created.GetResource().GetId())
+	return created.GetResource()
+}
+
+// Sends GET request to the gateway and decodes successful response into m.
+func getProto(t *testing.T, h *harness, path string, m proto.Message) {
+	t.Helper()
+	resp := sendRequest(t, "GET", h.gatewayURL, path, nil)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	data, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.NoError(t, protojson.Unmarshal(data, m))
+}